@@ -9,17 +9,32 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Embedded zone database for report time zones; the runtime image has none
+	_ "time/tzdata"
 
 	"github.com/drywaters/learnd/internal/config"
 	"github.com/drywaters/learnd/internal/enricher"
+	"github.com/drywaters/learnd/internal/mailin"
+	"github.com/drywaters/learnd/internal/metrics"
 	"github.com/drywaters/learnd/internal/repository"
+	"github.com/drywaters/learnd/internal/review"
 	"github.com/drywaters/learnd/internal/server"
 	"github.com/drywaters/learnd/internal/summarizer"
+	"github.com/drywaters/learnd/internal/tagger"
+	"github.com/drywaters/learnd/internal/tracing"
 	"github.com/drywaters/learnd/internal/worker"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "users" {
+		if err := runUsers(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		slog.Error("application error", "error", err)
 		os.Exit(1)
@@ -48,9 +63,30 @@ func run() error {
 
 	slog.Info("starting learnd", "port", cfg.Port)
 
-	// Connect to database
+	// Set up tracing; without an exporter spans are dropped
 	ctx := context.Background()
-	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracesExporter)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			slog.Warn("failed to flush traces", "error", err)
+		}
+	}()
+	if cfg.TracesExporter != tracing.ExporterNone {
+		slog.Info("tracing enabled", "exporter", cfg.TracesExporter)
+	}
+
+	// Connect to database, tracing every query
+	poolConfig, err := pgxpool.ParseConfig(cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse database url: %w", err)
+	}
+	poolConfig.ConnConfig.Tracer = tracing.QueryTracer{}
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -61,10 +97,19 @@ func run() error {
 		return fmt.Errorf("failed to ping database: %w", err)
 	}
 	slog.Info("connected to database")
+	metrics.RegisterPool(pool)
 
 	// Initialize repositories
 	entryRepo := repository.NewEntryRepository(pool)
 	summaryCacheRepo := repository.NewSummaryCacheRepository(pool)
+	promptTemplateRepo := repository.NewPromptTemplateRepository(pool)
+	reviewRepo := repository.NewReviewRepository(pool)
+	importRepo := repository.NewImportJobRepository(pool)
+	userRepo := repository.NewUserRepository(pool)
+	sessionRepo := repository.NewSessionRepository(pool)
+	apiTokenRepo := repository.NewAPITokenRepository(pool)
+	authEventRepo := repository.NewAuthEventRepository(pool, cfg.AuthEventRetention)
+	metrics.RegisterQueueDepth(entryRepo)
 
 	// Initialize enrichers
 	webEnricher := enricher.NewWebEnricher()
@@ -83,29 +128,90 @@ func run() error {
 	podcastEnricher := enricher.NewPodcastEnricher()
 	enrichRegistry.Register(podcastEnricher)
 
-	// Initialize summarizer
-	var sum summarizer.Summarizer
-	if cfg.GeminiAPIKey != "" {
-		var err error
-		sum, err = summarizer.NewGeminiSummarizer(ctx, cfg.GeminiAPIKey)
+	// Wayback Machine fallback for pages that can't be fetched
+	waybackEnricher := enricher.NewWaybackEnricher()
+	if cfg.WaybackFallback {
+		enrichRegistry.SetArchive(waybackEnricher)
+		slog.Info("Wayback Machine fallback enabled")
+	}
+	var archiver worker.Archiver
+	if cfg.WaybackSaveNew {
+		archiver = waybackEnricher
+		slog.Info("Wayback Machine archiving of new captures enabled")
+	}
+
+	// Initialize summarizers: the configured provider first, then fallbacks in order
+	var providers []summarizer.Summarizer
+	for _, provider := range append([]string{cfg.SummarizerProvider}, cfg.SummarizerFallbacks...) {
+		if provider == "" {
+			continue
+		}
+		apiKey := cfg.SummarizerAPIKey
+		if provider == "gemini" && provider != cfg.SummarizerProvider {
+			apiKey = cfg.GeminiAPIKey
+		}
+		sumCfg := summarizer.Config{Provider: provider, APIKey: apiKey}
+		if provider == cfg.SummarizerProvider {
+			sumCfg.Model = cfg.SummarizerModel
+			sumCfg.BaseURL = cfg.SummarizerBaseURL
+			sumCfg.Timeout = cfg.SummarizerTimeout
+			sumCfg.MaxTokens = cfg.SummarizerMaxTokens
+		}
+
+		s, err := summarizer.New(ctx, sumCfg)
 		if err != nil {
-			slog.Warn("failed to initialize Gemini summarizer", "error", err)
-		} else {
-			slog.Info("Gemini summarizer enabled", "model", sum.Model())
+			slog.Warn("failed to initialize summarizer", "provider", provider, "error", err)
+			continue
 		}
-	} else {
-		slog.Warn("Gemini API key not configured, summarization disabled")
+		providers = append(providers, s)
+		slog.Info("summarizer enabled", "provider", s.Provider(), "model", s.Model())
+	}
+
+	var sum summarizer.Summarizer
+	switch len(providers) {
+	case 0:
+		// Offline extractive summaries until an LLM provider is available
+		sum = summarizer.NewExtractiveSummarizer()
+		slog.Warn("no LLM summarizer available, using local extractive summaries")
+	default:
+		sum = summarizer.NewChain(summarizer.BreakerConfig{
+			Threshold: cfg.SummarizerBreakerFailures,
+			Cooldown:  cfg.SummarizerBreakerCooldown,
+		}, providers...)
+	}
+
+	// Auto-tagging: rules always, the LLM only when one is configured and opted in
+	var autoTagger *tagger.Tagger
+	if cfg.AutoTag {
+		var llmClassifier tagger.Classifier
+		if cfg.AutoTagLLM && len(providers) > 0 {
+			llmClassifier = tagger.NewLLMClassifier(sum)
+		}
+		autoTagger = tagger.New(llmClassifier)
+		slog.Info("auto-tagging enabled", "llm", llmClassifier != nil)
+	}
+
+	// Review cards: one per entry, plus LLM-written question cards when opted in
+	var qaSummarizer summarizer.Summarizer
+	if cfg.ReviewQACards && len(providers) > 0 {
+		qaSummarizer = sum
 	}
+	cardGenerator := review.NewGenerator(qaSummarizer)
 
 	// Initialize and start background worker
-	bgWorker := worker.New(entryRepo, summaryCacheRepo, enrichRegistry, sum, worker.Config{
-		Interval:  10 * time.Second,
-		BatchSize: 5,
+	bgWorker := worker.New(entryRepo, summaryCacheRepo, promptTemplateRepo, reviewRepo, importRepo, enrichRegistry, sum, worker.Config{
+		Interval:   10 * time.Second,
+		BatchSize:  5,
+		CacheTTL:   cfg.SummaryCacheTTL,
+		Structured: cfg.SummarizerStructured,
+		Archiver:   archiver,
+		Tagger:     autoTagger,
+		Cards:      cardGenerator,
 	})
 	bgWorker.Start(ctx)
 
 	// Create server
-	srv := server.New(cfg, entryRepo, summaryCacheRepo)
+	srv := server.New(cfg, entryRepo, summaryCacheRepo, promptTemplateRepo, reviewRepo, importRepo, userRepo, sessionRepo, apiTokenRepo, authEventRepo)
 
 	// Start HTTP server
 	httpServer := &http.Server{
@@ -116,6 +222,38 @@ func run() error {
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Start the email-in receiver if configured
+	var mailServer *mailin.Server
+	if cfg.SMTPAddr != "" {
+		mailServer = mailin.New(mailin.Config{
+			Addr:       cfg.SMTPAddr,
+			Hostname:   cfg.SMTPHostname,
+			Recipients: cfg.SMTPRecipients,
+		}, entryRepo, userRepo)
+		go func() {
+			slog.Info("smtp receiver listening", "addr", cfg.SMTPAddr, "recipients", cfg.SMTPRecipients)
+			if err := mailServer.ListenAndServe(); err != nil {
+				slog.Error("smtp receiver error", "error", err)
+			}
+		}()
+	}
+
+	// Serve metrics on their own address, e.g. one only reachable from the scraper
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		metricsServer = &http.Server{
+			Addr:              cfg.MetricsAddr,
+			Handler:           metrics.Handler(cfg.MetricsToken),
+			ReadHeaderTimeout: 15 * time.Second,
+		}
+		go func() {
+			slog.Info("metrics listening", "addr", cfg.MetricsAddr)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("metrics server error", "error", err)
+			}
+		}()
+	}
+
 	// Graceful shutdown
 	shutdownChan := make(chan os.Signal, 1)
 	signal.Notify(shutdownChan, os.Interrupt, syscall.SIGTERM)
@@ -133,6 +271,13 @@ func run() error {
 	// Stop background worker
 	bgWorker.Stop()
 
+	if mailServer != nil {
+		mailServer.Close()
+	}
+	if metricsServer != nil {
+		metricsServer.Close()
+	}
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/config"
+	"github.com/drywaters/learnd/internal/repository"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usersUsage = `usage:
+  learnd users list
+  learnd users create [-admin] <email>
+  learnd users set-password <email>
+  learnd users require-2fa [-off] <email>
+  learnd users reset-2fa <email>
+
+create and set-password read the password from the first line of stdin.
+reset-2fa turns off two-factor sign-in for a user who lost their authenticator
+and recovery codes.`
+
+// minPasswordLength is the shortest password accepted for an account
+const minPasswordLength = 8
+
+// runUsers manages accounts from the command line
+func runUsers(args []string) error {
+	if len(args) == 0 {
+		return errors.New(usersUsage)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer pool.Close()
+	users := repository.NewUserRepository(pool)
+
+	switch args[0] {
+	case "list":
+		list, err := users.List(ctx)
+		if err != nil {
+			return err
+		}
+		for _, u := range list {
+			role := "user"
+			if u.IsAdmin {
+				role = "admin"
+			}
+			password := "no password"
+			if u.PasswordHash != nil {
+				password = "password set"
+			}
+			twoFactor := "2fa off"
+			if u.TOTPEnabled() {
+				twoFactor = "2fa on"
+			} else if u.TOTPRequired {
+				twoFactor = "2fa required"
+			}
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", u.ID, u.Email, role, password, twoFactor)
+		}
+		return nil
+
+	case "create":
+		fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+		isAdmin := fs.Bool("admin", false, "grant admin rights")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 || !strings.Contains(fs.Arg(0), "@") {
+			return errors.New(usersUsage)
+		}
+
+		hash, err := readPasswordHash(os.Stdin)
+		if err != nil {
+			return err
+		}
+		user, err := users.Create(ctx, fs.Arg(0), &hash, *isAdmin)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("created user %s (%s)\n", user.Email, user.ID)
+		return nil
+
+	case "set-password":
+		if len(args) != 2 {
+			return errors.New(usersUsage)
+		}
+		user, err := users.GetByEmail(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("no user with email %s", args[1])
+		}
+
+		hash, err := readPasswordHash(os.Stdin)
+		if err != nil {
+			return err
+		}
+		if _, err := users.SetPassword(ctx, user.ID, hash); err != nil {
+			return err
+		}
+		fmt.Printf("updated password for %s and signed out its sessions\n", user.Email)
+		return nil
+
+	case "require-2fa":
+		fs := flag.NewFlagSet("users require-2fa", flag.ContinueOnError)
+		off := fs.Bool("off", false, "stop requiring two-factor sign-in")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New(usersUsage)
+		}
+		user, err := users.GetByEmail(ctx, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("no user with email %s", fs.Arg(0))
+		}
+
+		if _, err := users.SetTOTPRequired(ctx, user.ID, !*off); err != nil {
+			return err
+		}
+		if *off {
+			fmt.Printf("two-factor sign-in is now optional for %s\n", user.Email)
+		} else {
+			fmt.Printf("two-factor sign-in is now required for %s\n", user.Email)
+		}
+		return nil
+
+	case "reset-2fa":
+		if len(args) != 2 {
+			return errors.New(usersUsage)
+		}
+		user, err := users.GetByEmail(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("no user with email %s", args[1])
+		}
+
+		if err := users.DisableTOTP(ctx, user.ID); err != nil {
+			return err
+		}
+		fmt.Printf("turned off two-factor sign-in for %s\n", user.Email)
+		return nil
+
+	default:
+		return errors.New(usersUsage)
+	}
+}
+
+// readPasswordHash reads a password from the first line of r and hashes it
+func readPasswordHash(r io.Reader) (string, error) {
+	if f, ok := r.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			fmt.Fprint(os.Stderr, "Password (input is echoed): ")
+		}
+	}
+
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+
+	return auth.HashPassword(password)
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	hash, err := HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$") {
+		t.Errorf("hash = %q, want argon2id PHC format", hash)
+	}
+
+	ok, err := VerifyPassword("correct horse battery staple", hash)
+	if err != nil || !ok {
+		t.Errorf("VerifyPassword(correct) = %v, %v; want true", ok, err)
+	}
+	ok, err = VerifyPassword("wrong", hash)
+	if err != nil || ok {
+		t.Errorf("VerifyPassword(wrong) = %v, %v; want false", ok, err)
+	}
+}
+
+func TestHashPasswordSalted(t *testing.T) {
+	a, _ := HashPassword("same")
+	b, _ := HashPassword("same")
+	if a == b {
+		t.Error("expected different hashes for the same password")
+	}
+}
+
+func TestVerifyPasswordInvalidHash(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"plaintext",
+		"$argon2i$v=19$m=19456,t=2,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=18$m=19456,t=2,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=0,t=2,p=1$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=19456,t=2,p=1$!!$aGFzaA",
+	} {
+		if _, err := VerifyPassword("x", hash); err != ErrInvalidHash {
+			t.Errorf("VerifyPassword(%q) error = %v, want ErrInvalidHash", hash, err)
+		}
+	}
+}
+
+func TestSessionToken(t *testing.T) {
+	token, hash, err := NewSessionToken()
+	if err != nil {
+		t.Fatalf("NewSessionToken: %v", err)
+	}
+	if len(token) < 43 {
+		t.Errorf("token %q is too short", token)
+	}
+	if hash == token || hash != HashToken(token) {
+		t.Errorf("hash = %q, want HashToken(token)", hash)
+	}
+
+	other, _, _ := NewSessionToken()
+	if other == token {
+		t.Error("expected a different token each time")
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Activity slides the expiry forward by the TTL
+	now := created.Add(10 * 24 * time.Hour)
+	if got, want := SessionExpiry(created, now, 24*time.Hour, 30*24*time.Hour), now.Add(24*time.Hour); !got.Equal(want) {
+		t.Errorf("SessionExpiry = %v, want %v", got, want)
+	}
+
+	// but never past the maximum age
+	now = created.Add(29*24*time.Hour + 12*time.Hour)
+	if got, want := SessionExpiry(created, now, 24*time.Hour, 30*24*time.Hour), created.Add(30*24*time.Hour); !got.Equal(want) {
+		t.Errorf("SessionExpiry near max age = %v, want %v", got, want)
+	}
+}
+
+func TestUserID(t *testing.T) {
+	if got := UserID(context.Background()); got != uuid.Nil {
+		t.Errorf("UserID without user = %v, want uuid.Nil", got)
+	}
+
+	user := &model.User{ID: uuid.New()}
+	ctx := WithUser(context.Background(), user)
+	if got := UserID(ctx); got != user.ID {
+		t.Errorf("UserID = %v, want %v", got, user.ID)
+	}
+	if UserFromContext(ctx) != user {
+		t.Error("UserFromContext did not return the stored user")
+	}
+}
@@ -0,0 +1,72 @@
+// Package auth provides password hashing, session tokens and the request context
+// helpers that carry the signed-in user to handlers.
+package auth
+
+import (
+	"context"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type contextKey struct{}
+
+type sessionContextKey struct{}
+
+type scopesContextKey struct{}
+
+type csrfContextKey struct{}
+
+// WithUser returns a copy of ctx carrying the signed-in user
+func WithUser(ctx context.Context, user *model.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext returns the signed-in user, or nil outside an authenticated request
+func UserFromContext(ctx context.Context) *model.User {
+	user, _ := ctx.Value(contextKey{}).(*model.User)
+	return user
+}
+
+// UserID returns the signed-in user's ID, or uuid.Nil outside an authenticated request.
+// No account has the nil ID, so a handler reached without a user reads and writes nothing.
+func UserID(ctx context.Context) uuid.UUID {
+	if user := UserFromContext(ctx); user != nil {
+		return user.ID
+	}
+	return uuid.Nil
+}
+
+// WithSession returns a copy of ctx carrying the browser session the request was made with
+func WithSession(ctx context.Context, session *model.Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, session)
+}
+
+// SessionFromContext returns the request's browser session, or nil for bearer token requests
+func SessionFromContext(ctx context.Context) *model.Session {
+	session, _ := ctx.Value(sessionContextKey{}).(*model.Session)
+	return session
+}
+
+// WithScopes returns a copy of ctx carrying the scopes the request's credentials grant
+func WithScopes(ctx context.Context, scopes []model.Scope) context.Context {
+	return context.WithValue(ctx, scopesContextKey{}, scopes)
+}
+
+// HasScope reports whether the request's credentials grant scope
+func HasScope(ctx context.Context, scope model.Scope) bool {
+	scopes, _ := ctx.Value(scopesContextKey{}).([]model.Scope)
+	return model.HasScope(scopes, scope)
+}
+
+// WithCSRFToken returns a copy of ctx carrying the CSRF token pages embed for the
+// browser's session
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfContextKey{}, token)
+}
+
+// CSRFTokenFromContext returns the request's CSRF token, or "" without a session cookie
+func CSRFTokenFromContext(ctx context.Context) string {
+	token, _ := ctx.Value(csrfContextKey{}).(string)
+	return token
+}
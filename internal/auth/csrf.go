@@ -0,0 +1,20 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// CSRFHeader is the header htmx requests send the CSRF token in
+const CSRFHeader = "X-CSRF-Token"
+
+// CSRFField is the form field plain form posts send the CSRF token in
+const CSRFField = "csrf_token"
+
+// CSRFToken returns the anti-forgery token for a browser session. It's derived from
+// the session token, so it changes with every sign-in, and another site can't work
+// it out because it can't read the HttpOnly session cookie.
+func CSRFToken(sessionToken string) string {
+	sum := sha256.Sum256([]byte("learnd-csrf:" + sessionToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
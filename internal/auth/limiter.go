@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLimiterMaxFailures       = 5
+	defaultLimiterGlobalMaxFailures = 100
+	defaultLimiterWindow            = 15 * time.Minute
+	defaultLimiterLockout           = time.Minute
+	defaultLimiterMaxLockout        = time.Hour
+	defaultLimiterGlobalDelay       = 2 * time.Second
+)
+
+// LimiterConfig configures a Limiter. Zero values use the defaults.
+type LimiterConfig struct {
+	// MaxFailures is how many failures from one address within Window lock it out
+	MaxFailures int
+	// GlobalMaxFailures is how many failures from all addresses within Window slow
+	// down every failed attempt by GlobalDelay, for guessing spread over many addresses.
+	// Nobody is locked out, so an attacker can't keep everyone from signing in.
+	GlobalMaxFailures int
+	GlobalDelay       time.Duration
+	// Window is how long a failure counts towards a lockout
+	Window time.Duration
+	// Lockout is how long the first lockout lasts. Each further lockout doubles it, up
+	// to MaxLockout, until the address has been quiet for MaxLockout.
+	Lockout    time.Duration
+	MaxLockout time.Duration
+}
+
+// Lockout says what a failure locked out or slowed down
+type Lockout int
+
+const (
+	NoLockout Lockout = iota
+	// AddressLockout locks out the address the failure came from
+	AddressLockout
+	// GlobalSlowdown delays failed attempts from every address
+	GlobalSlowdown
+)
+
+// String names what was locked out, as recorded in the audit log
+func (l Lockout) String() string {
+	switch l {
+	case AddressLockout:
+		return "address"
+	case GlobalSlowdown:
+		return "global"
+	default:
+		return "none"
+	}
+}
+
+// Limiter slows down credential guessing. It counts failed sign-ins and rejected
+// bearer tokens per client address and in total. An address is locked out for
+// exponentially longer each time its failures pass the limit, and failures from
+// everyone are slowed down the same way when the total passes the global limit.
+// A locked out address is refused before its credentials are checked, so it can't
+// keep guessing until the lockout expires.
+type Limiter struct {
+	mu        sync.Mutex
+	cfg       LimiterConfig
+	now       func() time.Time
+	addresses map[string]*failureCount
+	global    failureCount
+	lastSweep time.Time
+}
+
+// failureCount tracks recent failures and lockouts for an address or everyone
+type failureCount struct {
+	failures    int
+	lockouts    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// NewLimiter creates a Limiter
+func NewLimiter(cfg LimiterConfig) *Limiter {
+	return newLimiter(cfg, time.Now)
+}
+
+func newLimiter(cfg LimiterConfig, now func() time.Time) *Limiter {
+	if cfg.MaxFailures <= 0 {
+		cfg.MaxFailures = defaultLimiterMaxFailures
+	}
+	if cfg.GlobalMaxFailures <= 0 {
+		cfg.GlobalMaxFailures = defaultLimiterGlobalMaxFailures
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = defaultLimiterWindow
+	}
+	if cfg.Lockout <= 0 {
+		cfg.Lockout = defaultLimiterLockout
+	}
+	if cfg.MaxLockout <= 0 {
+		cfg.MaxLockout = defaultLimiterMaxLockout
+	}
+	if cfg.GlobalDelay <= 0 {
+		cfg.GlobalDelay = defaultLimiterGlobalDelay
+	}
+	cfg.MaxLockout = max(cfg.MaxLockout, cfg.Lockout)
+
+	return &Limiter{
+		cfg:       cfg,
+		now:       now,
+		addresses: make(map[string]*failureCount),
+		lastSweep: now(),
+	}
+}
+
+// RetryAfter returns how much longer ip is locked out, or zero if it may try
+func (l *Limiter) RetryAfter(ip string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	count, ok := l.addresses[ip]
+	if !ok || !count.lockedUntil.After(now) {
+		return 0
+	}
+	return count.lockedUntil.Sub(now)
+}
+
+// Delay returns how long to hold back the answer to a failed attempt, which is
+// GlobalDelay while failures from everyone are over the global limit
+func (l *Limiter) Delay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.global.lockedUntil.After(l.now()) {
+		return 0
+	}
+	return l.cfg.GlobalDelay
+}
+
+// Wait sleeps for Delay, or until ctx is done
+func (l *Limiter) Wait(ctx context.Context) {
+	delay := l.Delay()
+	if delay <= 0 {
+		return
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// Fail records a failed attempt from ip and reports whether it started a lockout or
+// a global slowdown.
+// Successes don't reset the count, so an attacker with one valid account can't use
+// it to keep guessing at others.
+func (l *Limiter) Fail(ip string) Lockout {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	count, ok := l.addresses[ip]
+	if !ok {
+		count = &failureCount{}
+		l.addresses[ip] = count
+	}
+	addressLocked := l.fail(count, now, l.cfg.MaxFailures)
+	globalSlowed := l.fail(&l.global, now, l.cfg.GlobalMaxFailures)
+	switch {
+	case globalSlowed:
+		return GlobalSlowdown
+	case addressLocked:
+		return AddressLockout
+	default:
+		return NoLockout
+	}
+}
+
+// fail counts a failure and locks count out once it reaches limit
+func (l *Limiter) fail(count *failureCount, now time.Time, limit int) bool {
+	if now.Sub(count.lastFailure) > l.cfg.MaxLockout {
+		count.lockouts = 0
+	}
+	if now.Sub(count.lastFailure) > l.cfg.Window {
+		count.failures = 0
+	}
+	count.failures++
+	count.lastFailure = now
+
+	if count.failures < limit {
+		return false
+	}
+	lockout := l.cfg.Lockout
+	for i := 0; i < count.lockouts && lockout < l.cfg.MaxLockout; i++ {
+		lockout *= 2
+	}
+	count.lockedUntil = now.Add(min(lockout, l.cfg.MaxLockout))
+	count.lockouts++
+	count.failures = 0
+	return true
+}
+
+// sweep forgets addresses that no longer affect a lockout, at most once per window
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.cfg.Window {
+		return
+	}
+	l.lastSweep = now
+
+	forget := max(l.cfg.Window, l.cfg.MaxLockout)
+	for ip, count := range l.addresses {
+		if now.Sub(count.lastFailure) > forget && !count.lockedUntil.After(now) {
+			delete(l.addresses, ip)
+		}
+	}
+}
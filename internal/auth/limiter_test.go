@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterExponentialLockout(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newLimiter(LimiterConfig{MaxFailures: 3, Lockout: time.Minute, MaxLockout: 3 * time.Minute}, func() time.Time { return now })
+
+	failUntilLocked := func(wantLockout time.Duration) {
+		t.Helper()
+		for i := range 2 {
+			if got := l.Fail("10.0.0.1"); got != NoLockout {
+				t.Fatalf("failure %d locked out: %v", i+1, got)
+			}
+			if got := l.RetryAfter("10.0.0.1"); got != 0 {
+				t.Fatalf("RetryAfter before the limit = %v", got)
+			}
+		}
+		if got := l.Fail("10.0.0.1"); got != AddressLockout {
+			t.Fatalf("Fail at the limit = %v, want AddressLockout", got)
+		}
+		if got := l.RetryAfter("10.0.0.1"); got != wantLockout {
+			t.Fatalf("RetryAfter = %v, want %v", got, wantLockout)
+		}
+		if got := l.RetryAfter("10.0.0.2"); got != 0 {
+			t.Fatalf("other address RetryAfter = %v, want 0", got)
+		}
+		now = now.Add(wantLockout)
+	}
+
+	// Each lockout doubles, up to the maximum
+	failUntilLocked(time.Minute)
+	failUntilLocked(2 * time.Minute)
+	failUntilLocked(3 * time.Minute)
+	failUntilLocked(3 * time.Minute)
+
+	// After a quiet spell the lockout starts over from the shortest
+	now = now.Add(time.Hour)
+	failUntilLocked(time.Minute)
+}
+
+func TestLimiterWindow(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newLimiter(LimiterConfig{MaxFailures: 2, Window: time.Minute}, func() time.Time { return now })
+
+	l.Fail("10.0.0.1")
+	now = now.Add(2 * time.Minute)
+	if got := l.Fail("10.0.0.1"); got != NoLockout {
+		t.Errorf("failures outside the window locked out: %v", got)
+	}
+}
+
+func TestLimiterGlobalSlowdown(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newLimiter(LimiterConfig{MaxFailures: 5, GlobalMaxFailures: 3, GlobalDelay: time.Second}, func() time.Time { return now })
+
+	l.Fail("10.0.0.1")
+	l.Fail("10.0.0.2")
+	if got := l.Delay(); got != 0 {
+		t.Fatalf("Delay before the global limit = %v, want 0", got)
+	}
+	if got := l.Fail("10.0.0.3"); got != GlobalSlowdown {
+		t.Fatalf("Fail = %v, want GlobalSlowdown", got)
+	}
+
+	// Everyone's failures are slowed down, but nobody is locked out
+	if got := l.Delay(); got != time.Second {
+		t.Errorf("Delay = %v, want 1s", got)
+	}
+	if got := l.RetryAfter("10.0.0.4"); got != 0 {
+		t.Errorf("unrelated address RetryAfter = %v, want 0", got)
+	}
+
+	now = now.Add(defaultLimiterLockout)
+	if got := l.Delay(); got != 0 {
+		t.Errorf("Delay after the slowdown = %v, want 0", got)
+	}
+}
+
+func TestLimiterSweep(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	l := newLimiter(LimiterConfig{}, func() time.Time { return now })
+
+	l.Fail("10.0.0.1")
+	now = now.Add(2 * defaultLimiterMaxLockout)
+	l.Fail("10.0.0.2")
+
+	if _, ok := l.addresses["10.0.0.1"]; ok {
+		t.Error("expected a long-quiet address to be forgotten")
+	}
+	if _, ok := l.addresses["10.0.0.2"]; !ok {
+		t.Error("expected a recent address to be kept")
+	}
+}
@@ -0,0 +1,16 @@
+package auth
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP returns the address a request came from, without the port. Behind a trusted
+// proxy this relies on the RealIP middleware having rewritten RemoteAddr.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
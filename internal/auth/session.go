@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SessionCookieName is the cookie holding a browser's session token
+const SessionCookieName = "learnd_session"
+
+// TwoFactorCookieName is the cookie holding a pending session's token between the
+// password and the authenticator code
+const TwoFactorCookieName = "learnd_2fa"
+
+// sessionTokenBytes is the entropy of a session token
+const sessionTokenBytes = 32
+
+// NewSessionToken returns a random opaque session token for the cookie and the hash to store
+func NewSessionToken() (token, hash string, err error) {
+	buf := make([]byte, sessionTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the stored form of a session or API token. Tokens are random, so
+// an unsalted SHA-256 is enough to keep a leaked table from being replayed.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SessionExpiry returns when a session used at now expires: ttl after now, but never
+// later than maxAge after the session was created, so activity can't keep it alive forever
+func SessionExpiry(createdAt, now time.Time, ttl, maxAge time.Duration) time.Time {
+	expiresAt := now.Add(ttl)
+	if limit := createdAt.Add(maxAge); maxAge > 0 && expiresAt.After(limit) {
+		return limit
+	}
+	return expiresAt
+}
+
+// SetSessionCookie stores a session token in the browser until the session would expire
+func SetSessionCookie(w http.ResponseWriter, token string, ttl time.Duration, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearSessionCookie removes the session cookie from the browser
+func ClearSessionCookie(w http.ResponseWriter, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// SetTwoFactorCookie stores a pending session's token until the second factor is due
+func SetTwoFactorCookie(w http.ResponseWriter, token string, ttl time.Duration, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     TwoFactorCookieName,
+		Value:    token,
+		Path:     "/login",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearTwoFactorCookie removes the pending session cookie from the browser
+func ClearTwoFactorCookie(w http.ResponseWriter, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     TwoFactorCookieName,
+		Value:    "",
+		Path:     "/login",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
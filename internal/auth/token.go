@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// apiTokenPrefix marks learnd API tokens so they're recognizable in configs and secret scanners
+const apiTokenPrefix = "lnd_"
+
+// apiTokenDisplayLen is how much of a token is kept to tell tokens apart in the UI
+const apiTokenDisplayLen = len(apiTokenPrefix) + 6
+
+// NewAPIToken returns a random API token, the start of it that is safe to display,
+// and the hash to store
+func NewAPIToken() (token, display, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate api token: %w", err)
+	}
+	token = apiTokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, token[:apiTokenDisplayLen], HashToken(token), nil
+}
+
+// mailKeyBytes is the entropy of the secret in a user's emailing address
+const mailKeyBytes = 10
+
+// NewMailKey returns a random key for a user's emailing address. It's lowercase base32
+// so it survives mail servers that change the case of addresses.
+func NewMailKey() (string, error) {
+	buf := make([]byte, mailKeyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate mail key: %w", err)
+	}
+	return strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf)), nil
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 key from the RFC 6238 test vectors, "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFCVectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	step := TOTPStep(now)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: "081804", wantStep: step, wantOK: true},
+		{name: "spaces ignored", code: " 081 804 ", wantStep: step, wantOK: true},
+		{name: "previous step", code: mustCode(t, step-1), wantStep: step - 1, wantOK: true},
+		{name: "next step", code: mustCode(t, step+1), wantStep: step + 1, wantOK: true},
+		{name: "too old", code: mustCode(t, step-2)},
+		{name: "wrong code", code: "000000"},
+		{name: "wrong length", code: "08180"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := VerifyTOTP(rfcSecret, tt.code, now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("VerifyTOTP(%q) = %d, %v; want %d, %v", tt.code, gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func mustCode(t *testing.T, step int64) string {
+	t.Helper()
+	code, err := TOTPCode(rfcSecret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func TestNewTOTPSecret(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32", len(secret))
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("generated secret doesn't decode: %v", err)
+	}
+}
+
+func TestTOTPURL(t *testing.T) {
+	u, err := url.Parse(TOTPURL("alice@example.com", rfcSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/learnd:alice@example.com" {
+		t.Errorf("url = %s", u)
+	}
+	if u.Query().Get("secret") != rfcSecret || u.Query().Get("issuer") != "learnd" {
+		t.Errorf("query = %v", u.Query())
+	}
+
+	dataURI, err := TOTPQRCode(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(dataURI, "data:image/png;base64,") {
+		t.Errorf("qr code = %.40s...", dataURI)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("got %d codes and %d hashes, want 10", len(codes), len(hashes))
+	}
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if len(code) != 19 || strings.Count(code, "-") != 3 {
+			t.Errorf("code %q isn't xxxx-xxxx-xxxx-xxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+
+		loose := strings.ToUpper(strings.ReplaceAll(code, "-", " "))
+		if HashRecoveryCode(loose) != hashes[i] {
+			t.Errorf("HashRecoveryCode(%q) doesn't match the hash of %q", loose, code)
+		}
+	}
+}
@@ -3,8 +3,11 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/netip"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // Config holds all application configuration
@@ -16,6 +19,77 @@ type Config struct {
 	YouTubeAPIKey string
 	LogLevel      string
 	SecureCookies bool
+
+	// Browser sessions expire after this long without activity, and this long after
+	// sign-in however active they are
+	SessionTTL    time.Duration
+	SessionMaxAge time.Duration
+
+	// Accept the shared API_TOKEN as a bearer token and sign-in for the default user
+	LegacyAPIToken bool
+
+	// Failed sign-ins and bearer tokens from one address that lock it out, or from all
+	// addresses that slow down failures; each starts at LoginLockout and doubles while it repeats
+	LoginMaxFailures       int
+	LoginGlobalMaxFailures int
+	LoginLockout           time.Duration
+
+	// Proxies whose X-Forwarded-For and X-Real-IP headers give the client address
+	TrustedProxies []netip.Prefix
+
+	// Authentication audit log entries are deleted after this long
+	AuthEventRetention time.Duration
+
+	// OpenID Connect single sign-on; an empty issuer disables it
+	OIDCIssuer         string
+	OIDCClientID       string
+	OIDCClientSecret   string
+	OIDCRedirectURL    string
+	OIDCAllowedDomains []string
+
+	// Summarizer provider selection; empty provider disables summarization
+	SummarizerProvider  string
+	SummarizerModel     string
+	SummarizerBaseURL   string
+	SummarizerAPIKey    string
+	SummarizerTimeout   time.Duration
+	SummarizerMaxTokens int
+
+	// Fallback providers tried in order when the primary fails, with a circuit breaker per provider
+	SummarizerFallbacks       []string
+	SummarizerBreakerFailures int
+	SummarizerBreakerCooldown time.Duration
+
+	// How long cached summaries are reused; zero keeps them until purged
+	SummaryCacheTTL time.Duration
+
+	// Request structured output (takeaways, difficulty, suggested tags) from LLM providers
+	SummarizerStructured bool
+
+	// Wayback Machine integration
+	WaybackFallback bool
+	WaybackSaveNew  bool
+
+	// Auto-tagging of untagged entries
+	AutoTag    bool
+	AutoTagLLM bool
+
+	// Generate question/answer review cards with the summarizer's LLM
+	ReviewQACards bool
+
+	// Email-in capture; an empty address disables the SMTP receiver
+	SMTPAddr       string
+	SMTPHostname   string
+	SMTPRecipients []string
+
+	// Prometheus metrics, served on their own address or at /metrics behind the token;
+	// with neither set they aren't served
+	MetricsAddr  string
+	MetricsToken string
+
+	// Trace exporter, "none" or "otlp"; the OTLP endpoint, headers and sampling come
+	// from the standard OTEL_* variables
+	TracesExporter string
 }
 
 // Load reads configuration from environment variables.
@@ -51,16 +125,327 @@ func Load() (*Config, error) {
 	}
 	cfg.SecureCookies = secureCookiesStr != "false"
 
+	// Fall back to Wayback Machine snapshots when a page can't be fetched, opt-in with
+	// WAYBACK_FALLBACK=true since it sends the failed URL to web.archive.org
+	waybackFallbackStr, err := getEnv("WAYBACK_FALLBACK", "false")
+	if err != nil {
+		return nil, err
+	}
+	cfg.WaybackFallback = waybackFallbackStr == "true"
+
+	// Submit newly captured URLs to the Wayback Machine, opt-in with WAYBACK_SAVE_NEW=true
+	waybackSaveNewStr, err := getEnv("WAYBACK_SAVE_NEW", "false")
+	if err != nil {
+		return nil, err
+	}
+	cfg.WaybackSaveNew = waybackSaveNewStr == "true"
+
+	// Suggest tags for untagged entries, set AUTOTAG=false to disable
+	autoTagStr, err := getEnv("AUTOTAG", "true")
+	if err != nil {
+		return nil, err
+	}
+	cfg.AutoTag = autoTagStr != "false"
+
+	// Ask the summarizer's LLM when the tagging rules aren't confident, opt-in with AUTOTAG_LLM=true
+	autoTagLLMStr, err := getEnv("AUTOTAG_LLM", "false")
+	if err != nil {
+		return nil, err
+	}
+	cfg.AutoTagLLM = autoTagLLMStr == "true"
+
+	// Write question/answer review cards with the LLM, opt-in with REVIEW_QA_CARDS=true
+	reviewQACardsStr, err := getEnv("REVIEW_QA_CARDS", "false")
+	if err != nil {
+		return nil, err
+	}
+	cfg.ReviewQACards = reviewQACardsStr == "true"
+
+	if err := loadAuthConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadOIDCConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadSummarizerConfig(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadSMTPConfig(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.MetricsAddr, err = getEnv("METRICS_ADDR", ""); err != nil {
+		return nil, err
+	}
+	if cfg.MetricsToken, err = getEnvOrFile("METRICS_TOKEN", "/run/secrets/learnd_metrics_token"); err != nil {
+		return nil, err
+	}
+	if cfg.TracesExporter, err = getEnv("OTEL_TRACES_EXPORTER", "none"); err != nil {
+		return nil, err
+	}
+
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
-	if cfg.APIToken == "" {
-		return nil, fmt.Errorf("API_TOKEN is required")
+	if cfg.LegacyAPIToken && cfg.APIToken == "" {
+		return nil, fmt.Errorf("API_TOKEN is required unless LEGACY_API_TOKEN=false")
 	}
 
 	return cfg, nil
 }
 
+// loadSummarizerConfig reads the SUMMARIZER_* settings.
+// Without SUMMARIZER_PROVIDER, Gemini is used when GEMINI_API_KEY is set.
+func loadSummarizerConfig(cfg *Config) error {
+	var err error
+	if cfg.SummarizerProvider, err = getEnv("SUMMARIZER_PROVIDER", ""); err != nil {
+		return err
+	}
+	if cfg.SummarizerModel, err = getEnv("SUMMARIZER_MODEL", ""); err != nil {
+		return err
+	}
+	if cfg.SummarizerBaseURL, err = getEnv("SUMMARIZER_BASE_URL", ""); err != nil {
+		return err
+	}
+	if cfg.SummarizerAPIKey, err = getEnvOrFile("SUMMARIZER_API_KEY", "/run/secrets/learnd_summarizer_api_key"); err != nil {
+		return err
+	}
+
+	cfg.SummarizerProvider = strings.ToLower(strings.TrimSpace(cfg.SummarizerProvider))
+	if cfg.SummarizerProvider == "" && cfg.GeminiAPIKey != "" {
+		cfg.SummarizerProvider = "gemini"
+	}
+	if cfg.SummarizerProvider == "gemini" && cfg.SummarizerAPIKey == "" {
+		cfg.SummarizerAPIKey = cfg.GeminiAPIKey
+	}
+
+	timeoutStr, err := getEnv("SUMMARIZER_TIMEOUT", "")
+	if err != nil {
+		return err
+	}
+	if timeoutStr != "" {
+		if cfg.SummarizerTimeout, err = time.ParseDuration(timeoutStr); err != nil {
+			return fmt.Errorf("invalid SUMMARIZER_TIMEOUT %q: %w", timeoutStr, err)
+		}
+	}
+
+	maxTokensStr, err := getEnv("SUMMARIZER_MAX_TOKENS", "")
+	if err != nil {
+		return err
+	}
+	if maxTokensStr != "" {
+		if cfg.SummarizerMaxTokens, err = strconv.Atoi(maxTokensStr); err != nil {
+			return fmt.Errorf("invalid SUMMARIZER_MAX_TOKENS %q: %w", maxTokensStr, err)
+		}
+	}
+
+	// Comma-separated provider names, e.g. SUMMARIZER_FALLBACK=ollama,local
+	fallbackStr, err := getEnv("SUMMARIZER_FALLBACK", "")
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(fallbackStr, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" && name != cfg.SummarizerProvider {
+			cfg.SummarizerFallbacks = append(cfg.SummarizerFallbacks, name)
+		}
+	}
+
+	breakerFailuresStr, err := getEnv("SUMMARIZER_BREAKER_FAILURES", "3")
+	if err != nil {
+		return err
+	}
+	if cfg.SummarizerBreakerFailures, err = strconv.Atoi(breakerFailuresStr); err != nil {
+		return fmt.Errorf("invalid SUMMARIZER_BREAKER_FAILURES %q: %w", breakerFailuresStr, err)
+	}
+
+	breakerCooldownStr, err := getEnv("SUMMARIZER_BREAKER_COOLDOWN", "5m")
+	if err != nil {
+		return err
+	}
+	if cfg.SummarizerBreakerCooldown, err = time.ParseDuration(breakerCooldownStr); err != nil {
+		return fmt.Errorf("invalid SUMMARIZER_BREAKER_COOLDOWN %q: %w", breakerCooldownStr, err)
+	}
+
+	structuredStr, err := getEnv("SUMMARIZER_STRUCTURED", "false")
+	if err != nil {
+		return err
+	}
+	cfg.SummarizerStructured = structuredStr == "true"
+
+	cacheTTLStr, err := getEnv("SUMMARY_CACHE_TTL", "720h")
+	if err != nil {
+		return err
+	}
+	if cfg.SummaryCacheTTL, err = time.ParseDuration(cacheTTLStr); err != nil {
+		return fmt.Errorf("invalid SUMMARY_CACHE_TTL %q: %w", cacheTTLStr, err)
+	}
+
+	return nil
+}
+
+// loadAuthConfig reads the sign-in and session settings
+func loadAuthConfig(cfg *Config) error {
+	sessionTTLStr, err := getEnv("SESSION_TTL", "720h")
+	if err != nil {
+		return err
+	}
+	if cfg.SessionTTL, err = time.ParseDuration(sessionTTLStr); err != nil {
+		return fmt.Errorf("invalid SESSION_TTL %q: %w", sessionTTLStr, err)
+	}
+	if cfg.SessionTTL <= 0 {
+		return fmt.Errorf("SESSION_TTL must be positive")
+	}
+
+	// Sessions end this long after sign-in even if they're used every day
+	sessionMaxAgeStr, err := getEnv("SESSION_MAX_AGE", "2160h")
+	if err != nil {
+		return err
+	}
+	if cfg.SessionMaxAge, err = time.ParseDuration(sessionMaxAgeStr); err != nil {
+		return fmt.Errorf("invalid SESSION_MAX_AGE %q: %w", sessionMaxAgeStr, err)
+	}
+	if cfg.SessionMaxAge < cfg.SessionTTL {
+		return fmt.Errorf("SESSION_MAX_AGE must be at least SESSION_TTL")
+	}
+
+	// Keep accepting the shared API_TOKEN until clients move to personal access tokens,
+	// set LEGACY_API_TOKEN=false to disable
+	legacyTokenStr, err := getEnv("LEGACY_API_TOKEN", "true")
+	if err != nil {
+		return err
+	}
+	cfg.LegacyAPIToken = legacyTokenStr != "false"
+
+	maxFailuresStr, err := getEnv("LOGIN_MAX_FAILURES", "5")
+	if err != nil {
+		return err
+	}
+	if cfg.LoginMaxFailures, err = strconv.Atoi(maxFailuresStr); err != nil {
+		return fmt.Errorf("invalid LOGIN_MAX_FAILURES %q: %w", maxFailuresStr, err)
+	}
+
+	globalMaxFailuresStr, err := getEnv("LOGIN_GLOBAL_MAX_FAILURES", "100")
+	if err != nil {
+		return err
+	}
+	if cfg.LoginGlobalMaxFailures, err = strconv.Atoi(globalMaxFailuresStr); err != nil {
+		return fmt.Errorf("invalid LOGIN_GLOBAL_MAX_FAILURES %q: %w", globalMaxFailuresStr, err)
+	}
+
+	lockoutStr, err := getEnv("LOGIN_LOCKOUT", "1m")
+	if err != nil {
+		return err
+	}
+	if cfg.LoginLockout, err = time.ParseDuration(lockoutStr); err != nil {
+		return fmt.Errorf("invalid LOGIN_LOCKOUT %q: %w", lockoutStr, err)
+	}
+
+	// Forwarding headers are only believed from these proxies, e.g.
+	// TRUSTED_PROXIES=10.0.0.0/8,192.168.1.2
+	proxiesStr, err := getEnv("TRUSTED_PROXIES", "")
+	if err != nil {
+		return err
+	}
+	for _, proxy := range strings.Split(proxiesStr, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy == "" {
+			continue
+		}
+		prefix, err := parseProxy(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid TRUSTED_PROXIES entry %q: %w", proxy, err)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, prefix)
+	}
+
+	retentionStr, err := getEnv("AUTH_EVENT_RETENTION", "2160h")
+	if err != nil {
+		return err
+	}
+	if cfg.AuthEventRetention, err = time.ParseDuration(retentionStr); err != nil {
+		return fmt.Errorf("invalid AUTH_EVENT_RETENTION %q: %w", retentionStr, err)
+	}
+	if cfg.AuthEventRetention <= 0 {
+		return fmt.Errorf("AUTH_EVENT_RETENTION must be positive")
+	}
+
+	return nil
+}
+
+// parseProxy parses a trusted proxy given as a CIDR range or a single address
+func parseProxy(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// loadOIDCConfig reads the OIDC_* settings for single sign-on.
+// The client ID and redirect URL are required when OIDC_ISSUER is set; the client
+// secret is optional since PKCE protects public clients.
+func loadOIDCConfig(cfg *Config) error {
+	var err error
+	if cfg.OIDCIssuer, err = getEnv("OIDC_ISSUER", ""); err != nil {
+		return err
+	}
+	if cfg.OIDCClientID, err = getEnv("OIDC_CLIENT_ID", ""); err != nil {
+		return err
+	}
+	if cfg.OIDCClientSecret, err = getEnvOrFile("OIDC_CLIENT_SECRET", "/run/secrets/learnd_oidc_client_secret"); err != nil {
+		return err
+	}
+	if cfg.OIDCRedirectURL, err = getEnv("OIDC_REDIRECT_URL", ""); err != nil {
+		return err
+	}
+
+	// Comma-separated domains, e.g. OIDC_ALLOWED_DOMAINS=example.com,example.org
+	domainsStr, err := getEnv("OIDC_ALLOWED_DOMAINS", "")
+	if err != nil {
+		return err
+	}
+	for _, domain := range strings.Split(domainsStr, ",") {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			cfg.OIDCAllowedDomains = append(cfg.OIDCAllowedDomains, domain)
+		}
+	}
+
+	if cfg.OIDCIssuer != "" && (cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "") {
+		return fmt.Errorf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER is set")
+	}
+	return nil
+}
+
+// loadSMTPConfig reads the SMTP_* settings for email-in capture.
+// SMTP_RECIPIENTS is required when SMTP_ADDR is set, so the receiver never accepts mail for any address.
+func loadSMTPConfig(cfg *Config) error {
+	var err error
+	if cfg.SMTPAddr, err = getEnv("SMTP_ADDR", ""); err != nil {
+		return err
+	}
+	if cfg.SMTPHostname, err = getEnv("SMTP_HOSTNAME", ""); err != nil {
+		return err
+	}
+
+	// Comma-separated addresses, e.g. SMTP_RECIPIENTS=save@learnd.example.com
+	recipientsStr, err := getEnv("SMTP_RECIPIENTS", "")
+	if err != nil {
+		return err
+	}
+	for _, addr := range strings.Split(recipientsStr, ",") {
+		if addr = strings.ToLower(strings.TrimSpace(addr)); addr != "" {
+			cfg.SMTPRecipients = append(cfg.SMTPRecipients, addr)
+		}
+	}
+
+	if cfg.SMTPAddr != "" && len(cfg.SMTPRecipients) == 0 {
+		return fmt.Errorf("SMTP_RECIPIENTS is required when SMTP_ADDR is set")
+	}
+	return nil
+}
+
 // getEnv checks for FOO_FILE env var first, reads from file if exists,
 // otherwise falls back to FOO env var, then to the default value.
 // Returns an error if _FILE is set but the file cannot be read.
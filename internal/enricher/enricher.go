@@ -2,10 +2,18 @@ package enricher
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
 	"sort"
 	"time"
 
+	"github.com/drywaters/learnd/internal/metrics"
 	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Result contains extracted metadata from a URL
@@ -35,10 +43,20 @@ type Enricher interface {
 	Priority() int
 }
 
+// StatusError reports an HTTP error response from the source being enriched
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP error: %d", e.StatusCode)
+}
+
 // Registry manages enrichers and routes URLs to appropriate handlers
 type Registry struct {
 	enrichers []Enricher
 	fallback  Enricher
+	archive   Enricher
 }
 
 // NewRegistry creates a new enricher registry with a fallback enricher.
@@ -63,14 +81,71 @@ func (r *Registry) Register(e Enricher) {
 	})
 }
 
+// SetArchive configures an archive enricher (e.g. the Wayback Machine) that is
+// consulted when live enrichment fails with an HTTP error or timeout.
+func (r *Registry) SetArchive(e Enricher) {
+	r.archive = e
+}
+
 // Enrich processes a URL using the appropriate enricher.
 // The first enricher that can handle the URL is authoritative - if it fails,
 // the error is returned rather than falling back to a generic enricher.
+// The archive enricher, when configured, is the only exception: it is tried
+// when the source itself is unreachable.
 func (r *Registry) Enrich(ctx context.Context, url string) (*Result, error) {
+	result, err := r.enrichLive(ctx, url)
+	if err == nil || r.archive == nil || !isUnreachable(err) {
+		return result, err
+	}
+
+	archived, archiveErr := enrich(ctx, r.archive, url)
+	if archiveErr != nil {
+		return nil, fmt.Errorf("%w (%s fallback: %v)", err, r.archive.Name(), archiveErr)
+	}
+	return archived, nil
+}
+
+func (r *Registry) enrichLive(ctx context.Context, url string) (*Result, error) {
 	for _, e := range r.enrichers {
 		if e.CanHandle(url) {
-			return e.Enrich(ctx, url)
+			return enrich(ctx, e, url)
 		}
 	}
-	return r.fallback.Enrich(ctx, url)
+	return enrich(ctx, r.fallback, url)
+}
+
+// enrich runs a single enricher in its own span, recording its outcome and latency.
+// The span only records the link's host, since full URLs can carry private paths and
+// tokens that don't belong in a tracing backend.
+func enrich(ctx context.Context, e Enricher, url string) (*Result, error) {
+	attrs := []attribute.KeyValue{attribute.String("enricher", e.Name())}
+	if parsed, err := neturl.Parse(url); err == nil && parsed.Hostname() != "" {
+		attrs = append(attrs, attribute.String("server.address", parsed.Hostname()))
+	}
+	ctx, span := tracing.Start(ctx, "enrich "+e.Name(), trace.WithAttributes(attrs...))
+	start := time.Now()
+	result, err := e.Enrich(ctx, url)
+	tracing.End(span, err)
+
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	metrics.Enrichments.WithLabelValues(e.Name(), outcome).Inc()
+	metrics.EnrichmentDuration.WithLabelValues(e.Name()).Observe(time.Since(start).Seconds())
+	return result, err
+}
+
+// isUnreachable reports whether err means the source returned an HTTP error
+// status or timed out, as opposed to a validation or parsing failure.
+func isUnreachable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
@@ -0,0 +1,52 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestEnrichSpanOmitsSourcePath(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	const sourceURL = "https://intranet.example.com/private/plan?token=s3cret"
+	fetchErr := &url.Error{Op: "Get", URL: sourceURL, Err: errors.New("connection refused")}
+	registry := NewRegistry(&stubEnricher{err: fetchErr})
+
+	if _, err := registry.Enrich(context.Background(), sourceURL); err == nil {
+		t.Fatal("expected the fetch error")
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+
+	recorded := []string{span.Status().Description}
+	for _, event := range span.Events() {
+		for _, attr := range event.Attributes {
+			recorded = append(recorded, attr.Value.Emit())
+		}
+	}
+	for _, attr := range span.Attributes() {
+		recorded = append(recorded, attr.Value.Emit())
+	}
+	for _, value := range recorded {
+		if strings.Contains(value, "/private") || strings.Contains(value, "s3cret") {
+			t.Errorf("span records %q", value)
+		}
+	}
+	if !strings.Contains(span.Status().Description, "https://intranet.example.com") {
+		t.Errorf("status = %q, want the failed host kept", span.Status().Description)
+	}
+}
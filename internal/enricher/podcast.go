@@ -83,7 +83,7 @@ func (e *PodcastEnricher) Enrich(ctx context.Context, rawURL string) (*Result, e
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
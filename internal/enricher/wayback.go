@@ -0,0 +1,173 @@
+package enricher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	waybackAvailabilityURL = "https://archive.org/wayback/available"
+	waybackBaseURL         = "https://web.archive.org"
+
+	// waybackTimestampLayout is the 14-digit timestamp format used in snapshot URLs
+	waybackTimestampLayout = "20060102150405"
+)
+
+// WaybackEnricher extracts metadata from the closest Wayback Machine snapshot
+// of a URL. It is used as a fallback when the live page cannot be fetched.
+type WaybackEnricher struct {
+	client          *http.Client
+	availabilityURL string
+	baseURL         string
+}
+
+// NewWaybackEnricher creates a new Wayback Machine enricher
+func NewWaybackEnricher() *WaybackEnricher {
+	return &WaybackEnricher{
+		client:          newSafeHTTPClient(20*time.Second, "archive.org", "web.archive.org"),
+		availabilityURL: waybackAvailabilityURL,
+		baseURL:         waybackBaseURL,
+	}
+}
+
+func (e *WaybackEnricher) Name() string            { return "wayback" }
+func (e *WaybackEnricher) Priority() int           { return 200 } // Only used as an archive fallback
+func (e *WaybackEnricher) CanHandle(_ string) bool { return true }
+
+// Enrich looks up the closest snapshot for the URL and extracts metadata from it.
+// The result describes the original URL; the snapshot is recorded in Metadata
+// as archived_from and archive_timestamp.
+func (e *WaybackEnricher) Enrich(ctx context.Context, rawURL string) (*Result, error) {
+	parsedURL, err := validateFetchURL(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot, err := e.closestSnapshot(ctx, parsedURL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// The id_ modifier returns the original page without the Wayback toolbar or rewritten links
+	rawSnapshotURL := fmt.Sprintf("%s/web/%sid_/%s", e.baseURL, snapshot.Timestamp, parsedURL.String())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", rawSnapshotURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Learnd/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("snapshot HTTP error: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	doc, err := html.Parse(strings.NewReader(string(body)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot HTML: %w", err)
+	}
+
+	result := resultFromDocument(doc, parsedURL.String(), parsedURL.Hostname())
+	result.Metadata["archived_from"] = snapshot.URL
+	if capturedAt, err := time.Parse(waybackTimestampLayout, snapshot.Timestamp); err == nil {
+		result.Metadata["archive_timestamp"] = capturedAt.UTC().Format(time.RFC3339)
+	}
+
+	return result, nil
+}
+
+// Save submits a "Save Page Now" request so the URL is archived while it is still live
+func (e *WaybackEnricher) Save(ctx context.Context, rawURL string) error {
+	parsedURL, err := validateFetchURL(ctx, rawURL)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", e.baseURL+"/save/"+parsedURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; Learnd/1.0)")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to submit save request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("save request HTTP error: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (e *WaybackEnricher) closestSnapshot(ctx context.Context, rawURL string) (*waybackSnapshot, error) {
+	apiURL := e.availabilityURL + "?url=" + url.QueryEscape(rawURL)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Wayback Machine: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Wayback Machine API error: %d", resp.StatusCode)
+	}
+
+	var apiResp waybackAvailabilityResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	closest := apiResp.ArchivedSnapshots.Closest
+	if closest == nil || !closest.Available || closest.Timestamp == "" {
+		return nil, fmt.Errorf("no archived snapshot available")
+	}
+	if closest.Status != "" && !strings.HasPrefix(closest.Status, "2") {
+		return nil, fmt.Errorf("closest snapshot has status %s", closest.Status)
+	}
+	if _, err := time.Parse(waybackTimestampLayout, closest.Timestamp); err != nil {
+		return nil, fmt.Errorf("invalid snapshot timestamp: %s", closest.Timestamp)
+	}
+
+	return closest, nil
+}
+
+// Wayback Machine availability API response structures
+type waybackAvailabilityResponse struct {
+	ArchivedSnapshots struct {
+		Closest *waybackSnapshot `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+type waybackSnapshot struct {
+	Available bool   `json:"available"`
+	URL       string `json:"url"`
+	Timestamp string `json:"timestamp"`
+	Status    string `json:"status"`
+}
@@ -0,0 +1,195 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// A public IP literal avoids DNS lookups in validateFetchURL
+const waybackTestURL = "https://93.184.215.14/post"
+
+// newWaybackStandIn serves the availability API, raw snapshots and save requests.
+// Paths contain embedded URLs, so a plain handler is used instead of a ServeMux
+// which would clean the double slashes.
+func newWaybackStandIn(t *testing.T, snapshotHTML string, available bool) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var requests []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+
+		switch {
+		case r.URL.Path == "/wayback/available":
+			if r.URL.Query().Get("url") != waybackTestURL {
+				http.Error(w, "unexpected url", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if !available {
+				fmt.Fprint(w, `{"url": "`+waybackTestURL+`", "archived_snapshots": {}}`)
+				return
+			}
+			fmt.Fprintf(w, `{"archived_snapshots": {"closest": {"status": "200", "available": true, "url": "%s/web/20240102030405/%s", "timestamp": "20240102030405"}}}`,
+				srv.URL, waybackTestURL)
+		case r.URL.Path == "/web/20240102030405id_/"+waybackTestURL:
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, snapshotHTML)
+		case strings.HasPrefix(r.URL.Path, "/save/"):
+			w.WriteHeader(http.StatusOK)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func newTestWaybackEnricher(srv *httptest.Server) *WaybackEnricher {
+	e := NewWaybackEnricher()
+	e.client = srv.Client()
+	e.availabilityURL = srv.URL + "/wayback/available"
+	e.baseURL = srv.URL
+	return e
+}
+
+type stubEnricher struct {
+	err error
+}
+
+func (s *stubEnricher) Name() string            { return "stub" }
+func (s *stubEnricher) Priority() int           { return 100 }
+func (s *stubEnricher) CanHandle(_ string) bool { return true }
+func (s *stubEnricher) Enrich(_ context.Context, _ string) (*Result, error) {
+	return nil, s.err
+}
+
+func TestWaybackEnricherUsesClosestSnapshot(t *testing.T) {
+	srv, _ := newWaybackStandIn(t, `<html><head>
+		<title>Archived Post</title>
+		<meta name="description" content="From the archive">
+	</head><body><article>some archived words</article></body></html>`, true)
+
+	result, err := newTestWaybackEnricher(srv).Enrich(context.Background(), waybackTestURL)
+	if err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+
+	if result.Title != "Archived Post" {
+		t.Errorf("Title = %q, want %q", result.Title, "Archived Post")
+	}
+	if result.Description != "From the archive" {
+		t.Errorf("Description = %q, want %q", result.Description, "From the archive")
+	}
+	if result.CanonicalURL != waybackTestURL {
+		t.Errorf("CanonicalURL = %q, want original URL %q", result.CanonicalURL, waybackTestURL)
+	}
+	if result.Domain != "93.184.215.14" {
+		t.Errorf("Domain = %q, want original host", result.Domain)
+	}
+
+	wantArchived := srv.URL + "/web/20240102030405/" + waybackTestURL
+	if got := result.Metadata["archived_from"]; got != wantArchived {
+		t.Errorf("archived_from = %v, want %q", got, wantArchived)
+	}
+	if got := result.Metadata["archive_timestamp"]; got != "2024-01-02T03:04:05Z" {
+		t.Errorf("archive_timestamp = %v, want %q", got, "2024-01-02T03:04:05Z")
+	}
+}
+
+func TestWaybackEnricherNoSnapshot(t *testing.T) {
+	srv, _ := newWaybackStandIn(t, "", false)
+
+	_, err := newTestWaybackEnricher(srv).Enrich(context.Background(), waybackTestURL)
+	if err == nil || !strings.Contains(err.Error(), "no archived snapshot") {
+		t.Fatalf("Enrich() error = %v, want no archived snapshot error", err)
+	}
+}
+
+func TestWaybackEnricherSave(t *testing.T) {
+	srv, requests := newWaybackStandIn(t, "", true)
+
+	if err := newTestWaybackEnricher(srv).Save(context.Background(), waybackTestURL); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(*requests) != 1 || (*requests)[0] != "/save/"+waybackTestURL {
+		t.Errorf("requests = %v, want a single save request", *requests)
+	}
+}
+
+func TestRegistryArchiveFallback(t *testing.T) {
+	srv, _ := newWaybackStandIn(t, `<html><head><title>Gone Now</title></head></html>`, true)
+
+	tests := []struct {
+		name        string
+		liveErr     error
+		wantArchive bool
+	}{
+		{
+			name:        "not found falls back",
+			liveErr:     &StatusError{StatusCode: http.StatusNotFound},
+			wantArchive: true,
+		},
+		{
+			name:        "server error falls back",
+			liveErr:     fmt.Errorf("wrapped: %w", &StatusError{StatusCode: http.StatusBadGateway}),
+			wantArchive: true,
+		},
+		{
+			name:        "timeout falls back",
+			liveErr:     fmt.Errorf("failed to fetch URL: %w", context.DeadlineExceeded),
+			wantArchive: true,
+		},
+		{
+			name:        "validation error does not fall back",
+			liveErr:     errors.New("invalid URL: host is not allowed"),
+			wantArchive: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(&stubEnricher{err: tt.liveErr})
+			registry.SetArchive(newTestWaybackEnricher(srv))
+
+			result, err := registry.Enrich(context.Background(), waybackTestURL)
+			if !tt.wantArchive {
+				if !errors.Is(err, tt.liveErr) {
+					t.Fatalf("Enrich() error = %v, want %v", err, tt.liveErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+			if result.Title != "Gone Now" {
+				t.Errorf("Title = %q, want %q", result.Title, "Gone Now")
+			}
+			if _, ok := result.Metadata["archived_from"]; !ok {
+				t.Error("archived_from missing from metadata")
+			}
+		})
+	}
+}
+
+func TestRegistryArchiveFallbackFailure(t *testing.T) {
+	srv, _ := newWaybackStandIn(t, "", false)
+
+	liveErr := &StatusError{StatusCode: http.StatusGone}
+	registry := NewRegistry(&stubEnricher{err: liveErr})
+	registry.SetArchive(newTestWaybackEnricher(srv))
+
+	_, err := registry.Enrich(context.Background(), waybackTestURL)
+	if !errors.Is(err, liveErr) {
+		t.Fatalf("Enrich() error = %v, want wrapped %v", err, liveErr)
+	}
+	if !strings.Contains(err.Error(), "wayback fallback") {
+		t.Errorf("error %q does not mention the fallback", err)
+	}
+}
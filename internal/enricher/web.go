@@ -51,7 +51,7 @@ func (e *WebEnricher) Enrich(ctx context.Context, rawURL string) (*Result, error
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	// Limit reading to 1MB
@@ -66,10 +66,17 @@ func (e *WebEnricher) Enrich(ctx context.Context, rawURL string) (*Result, error
 		return nil, fmt.Errorf("failed to parse HTML: %w", err)
 	}
 
+	// Follow redirects for the canonical URL
+	return resultFromDocument(doc, resp.Request.URL.String(), parsedURL.Hostname()), nil
+}
+
+// resultFromDocument builds a web Result from a parsed HTML page.
+// Link rel=canonical in the page overrides canonicalURL.
+func resultFromDocument(doc *html.Node, canonicalURL, domain string) *Result {
 	result := &Result{
-		CanonicalURL: resp.Request.URL.String(), // Follow redirects
-		Domain:       parsedURL.Hostname(),
-		SourceType:   classifySourceType(parsedURL.Hostname(), ""),
+		CanonicalURL: canonicalURL,
+		Domain:       domain,
+		SourceType:   classifySourceType(domain, ""),
 		Metadata:     make(map[string]interface{}),
 	}
 
@@ -85,7 +92,7 @@ func (e *WebEnricher) Enrich(ctx context.Context, rawURL string) (*Result, error
 		}
 	}
 
-	return result, nil
+	return result
 }
 
 // extractMetadata walks the HTML tree and extracts title, description, etc.
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxTokenNameLength limits the name given to a personal access token
+const maxTokenNameLength = 100
+
+// APITokenRepo defines the repository operations for personal access tokens
+type APITokenRepo interface {
+	Create(ctx context.Context, token *model.APIToken, tokenHash string) (*model.APIToken, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) (bool, error)
+}
+
+// APITokenHandler manages the signed-in user's personal access tokens
+type APITokenHandler struct {
+	tokens APITokenRepo
+}
+
+// NewAPITokenHandler creates a new APITokenHandler
+func NewAPITokenHandler(tokens APITokenRepo) *APITokenHandler {
+	return &APITokenHandler{
+		tokens: tokens,
+	}
+}
+
+// TokensPage renders the user's tokens and the form to create one
+func (h *APITokenHandler) TokensPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	tokens, err := h.tokens.ListByUser(ctx, auth.UserID(ctx))
+	if err != nil {
+		slog.Error("failed to list api tokens", "handler", "TokensPage", "error", err)
+		http.Error(w, "Failed to load tokens", http.StatusInternalServerError)
+		return
+	}
+
+	pages.APITokensPage(tokens, grantableScopes(ctx)).Render(ctx, w)
+}
+
+// Create issues a new token, showing its secret once
+func (h *APITokenHandler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		htmxError(w, "Invalid form")
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		htmxError(w, "Give the token a name")
+		return
+	}
+	if len(name) > maxTokenNameLength {
+		htmxError(w, "Token name is too long")
+		return
+	}
+
+	allowed := grantableScopes(ctx)
+	var scopes []model.Scope
+	for _, value := range r.Form["scopes"] {
+		scope := model.Scope(value)
+		if !slices.Contains(allowed, scope) {
+			htmxError(w, "Invalid scope: "+value)
+			return
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		htmxError(w, "Select at least one scope")
+		return
+	}
+
+	var expiresAt *time.Time
+	if days := r.FormValue("expires_in_days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			htmxError(w, "Invalid expiry")
+			return
+		}
+		t := time.Now().AddDate(0, 0, n)
+		expiresAt = &t
+	}
+
+	secret, prefix, tokenHash, err := auth.NewAPIToken()
+	if err != nil {
+		slog.Error("failed to generate api token", "handler", "Create", "error", err)
+		htmxError(w, "Failed to create token")
+		return
+	}
+	if _, err := h.tokens.Create(ctx, &model.APIToken{
+		UserID:    auth.UserID(ctx),
+		Name:      name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+	}, tokenHash); err != nil {
+		slog.Error("failed to create api token", "handler", "Create", "error", err)
+		htmxError(w, "Failed to create token")
+		return
+	}
+
+	h.renderTokens(w, r, "Create", secret)
+}
+
+// Revoke deletes one of the user's tokens
+func (h *APITokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	found, err := h.tokens.Delete(ctx, auth.UserID(ctx), id)
+	if err != nil {
+		slog.Error("failed to revoke api token", "handler", "Revoke", "id", id, "error", err)
+		htmxError(w, "Failed to revoke token")
+		return
+	}
+	if !found {
+		http.Error(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	htmxToast(w, "Token revoked", nil, "")
+	h.renderTokens(w, r, "Revoke", "")
+}
+
+func (h *APITokenHandler) renderTokens(w http.ResponseWriter, r *http.Request, handlerName, newToken string) {
+	ctx := r.Context()
+
+	tokens, err := h.tokens.ListByUser(ctx, auth.UserID(ctx))
+	if err != nil {
+		slog.Error("failed to list api tokens", "handler", handlerName, "error", err)
+		htmxError(w, "Failed to reload tokens")
+		return
+	}
+
+	partials.APITokens(tokens, newToken).Render(ctx, w)
+}
+
+// grantableScopes returns the scopes the signed-in user may put on a token
+func grantableScopes(ctx context.Context) []model.Scope {
+	if user := auth.UserFromContext(ctx); user != nil {
+		return model.UserScopes(user)
+	}
+	return nil
+}
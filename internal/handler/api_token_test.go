@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type mockAPITokenRepo struct {
+	tokens []model.APIToken
+	hashes []string
+}
+
+func (m *mockAPITokenRepo) Create(ctx context.Context, token *model.APIToken, tokenHash string) (*model.APIToken, error) {
+	created := *token
+	created.ID = uuid.New()
+	m.tokens = append(m.tokens, created)
+	m.hashes = append(m.hashes, tokenHash)
+	return &created, nil
+}
+
+func (m *mockAPITokenRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error) {
+	var tokens []model.APIToken
+	for _, t := range m.tokens {
+		if t.UserID == userID {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, nil
+}
+
+func (m *mockAPITokenRepo) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	for i, t := range m.tokens {
+		if t.ID == id && t.UserID == userID {
+			m.tokens = append(m.tokens[:i], m.tokens[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newTokenRequest(user *model.User, form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/tokens", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req.WithContext(auth.WithUser(req.Context(), user))
+}
+
+func TestCreateAPIToken(t *testing.T) {
+	repo := &mockAPITokenRepo{}
+	h := NewAPITokenHandler(repo)
+	user := &model.User{ID: uuid.New()}
+
+	rec := httptest.NewRecorder()
+	h.Create(rec, newTokenRequest(user, url.Values{
+		"name":            {"iOS Shortcut"},
+		"scopes":          {"entries:write", "entries:write"},
+		"expires_in_days": {"30"},
+	}))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.tokens) != 1 {
+		t.Fatalf("created %d tokens, want 1", len(repo.tokens))
+	}
+	token := repo.tokens[0]
+	if token.UserID != user.ID || token.Name != "iOS Shortcut" || token.ExpiresAt == nil {
+		t.Errorf("token = %+v", token)
+	}
+	if len(token.Scopes) != 1 || token.Scopes[0] != model.ScopeEntriesWrite {
+		t.Errorf("scopes = %v, want [entries:write]", token.Scopes)
+	}
+	if !strings.HasPrefix(token.Prefix, "lnd_") {
+		t.Errorf("prefix = %q", token.Prefix)
+	}
+
+	// The secret is shown once and only its hash is stored
+	body := rec.Body.String()
+	start := strings.Index(body, token.Prefix)
+	if start < 0 {
+		t.Fatal("response doesn't show the new token")
+	}
+	secret := body[start : start+len(token.Prefix)+37]
+	if auth.HashToken(secret) != repo.hashes[0] {
+		t.Errorf("stored hash doesn't match the shown token %q", secret)
+	}
+}
+
+func TestCreateAPITokenValidation(t *testing.T) {
+	user := &model.User{ID: uuid.New()}
+	tests := []struct {
+		name string
+		form url.Values
+	}{
+		{"missing name", url.Values{"scopes": {"entries:read"}}},
+		{"no scopes", url.Values{"name": {"cli"}}},
+		{"unknown scope", url.Values{"name": {"cli"}, "scopes": {"everything"}}},
+		{"admin scope for non-admin", url.Values{"name": {"cli"}, "scopes": {"admin"}}},
+		{"invalid expiry", url.Values{"name": {"cli"}, "scopes": {"entries:read"}, "expires_in_days": {"-1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockAPITokenRepo{}
+			rec := httptest.NewRecorder()
+			NewAPITokenHandler(repo).Create(rec, newTokenRequest(user, tt.form))
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want 422", rec.Code)
+			}
+			if len(repo.tokens) != 0 {
+				t.Error("token was created")
+			}
+		})
+	}
+}
+
+func TestCreateAdminAPIToken(t *testing.T) {
+	repo := &mockAPITokenRepo{}
+	admin := &model.User{ID: uuid.New(), IsAdmin: true}
+
+	rec := httptest.NewRecorder()
+	NewAPITokenHandler(repo).Create(rec, newTokenRequest(admin, url.Values{"name": {"ops"}, "scopes": {"admin"}}))
+
+	if rec.Code != http.StatusOK || len(repo.tokens) != 1 || repo.tokens[0].ExpiresAt != nil {
+		t.Errorf("status = %d, tokens = %+v; want a non-expiring admin token", rec.Code, repo.tokens)
+	}
+}
@@ -1,82 +1,347 @@
 package handler
 
 import (
+	"context"
 	"crypto/subtle"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
 	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/google/uuid"
 )
 
-const cookieName = "learnd_session"
+// twoFactorTimeout is how long a user has to enter their code after the password
+const twoFactorTimeout = 5 * time.Minute
+
+// maxTwoFactorAttempts is how many wrong codes end a sign-in attempt
+const maxTwoFactorAttempts = 5
+
+// UserRepo looks up accounts for sign-in
+type UserRepo interface {
+	SecondFactorRepo
+	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	GetByIdentity(ctx context.Context, issuer, subject string) (*model.User, error)
+	LinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject, email string) error
+	Create(ctx context.Context, email string, passwordHash *string, isAdmin bool) (*model.User, error)
+}
 
 // AuthHandler handles authentication
 type AuthHandler struct {
 	apiToken      string
 	secureCookies bool
+	sessionTTL    time.Duration
+	users         UserRepo
+	sessions      SessionRepo
+	events        AuthEventRecorder
+	limiter       *auth.Limiter
+	sso           OIDCProvider
+	// dummyHash is verified against when no account matches, so unknown emails
+	// take as long to reject as wrong passwords
+	dummyHash string
 }
 
-// NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(apiToken string, secureCookies bool) *AuthHandler {
+// NewAuthHandler creates a new AuthHandler. An empty apiToken disables signing in
+// as the default user with the legacy token; a nil sso disables single sign-on.
+// Failed attempts are recorded to events and counted by limiter.
+func NewAuthHandler(apiToken string, secureCookies bool, sessionTTL time.Duration, users UserRepo, sessions SessionRepo, events AuthEventRecorder, limiter *auth.Limiter, sso OIDCProvider) *AuthHandler {
+	dummyHash, err := auth.HashPassword(uuid.NewString())
+	if err != nil {
+		slog.Error("failed to hash dummy password", "error", err)
+	}
 	return &AuthHandler{
 		apiToken:      apiToken,
 		secureCookies: secureCookies,
+		sessionTTL:    sessionTTL,
+		users:         users,
+		sessions:      sessions,
+		events:        events,
+		limiter:       limiter,
+		sso:           sso,
+		dummyHash:     dummyHash,
 	}
 }
 
 // LoginPage renders the login page
 func (h *AuthHandler) LoginPage(w http.ResponseWriter, r *http.Request) {
-	// If already authenticated via valid cookie, redirect to home
-	if cookie, err := r.Cookie(cookieName); err == nil {
-		if constantTimeEqual(cookie.Value, h.apiToken) {
+	// If already authenticated via a live session, redirect to home
+	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil {
+		session, err := h.sessions.GetByTokenHash(r.Context(), auth.HashToken(cookie.Value))
+		if err == nil && session != nil {
 			http.Redirect(w, r, "/", http.StatusSeeOther)
 			return
 		}
+		if err == nil {
+			// The session expired or was revoked; drop its cookie and token so the
+			// form is posted without them
+			auth.ClearSessionCookie(w, h.secureCookies)
+			r = r.WithContext(auth.WithCSRFToken(r.Context(), ""))
+		}
 	}
 
 	errorType := r.URL.Query().Get("error")
 	redirectURL := r.URL.Query().Get("redirect")
-	pages.LoginPage(errorType, redirectURL).Render(r.Context(), w)
+	pages.LoginPage(errorType, redirectURL, h.apiToken != "", h.sso != nil).Render(r.Context(), w)
 }
 
-// Login handles the login form submission
+// Login handles the login form submission. An email and password sign in to that
+// account; a blank email with the API token signs in as the default user. A locked
+// out client is refused without its credentials being checked.
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if h.lockedOut(r) {
+		http.Redirect(w, r, "/login?error=locked_out", http.StatusSeeOther)
+		return
+	}
+
 	if err := r.ParseForm(); err != nil {
 		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
 		return
 	}
 
-	apiKey := r.FormValue("api_key")
-	if apiKey == "" {
-		http.Redirect(w, r, "/login?error=missing_key", http.StatusSeeOther)
+	email := strings.TrimSpace(r.FormValue("email"))
+	password := r.FormValue("password")
+	if password == "" {
+		http.Redirect(w, r, "/login?error=missing_password", http.StatusSeeOther)
+		return
+	}
+
+	method := model.AuthMethodPassword
+	if email == "" {
+		method = model.AuthMethodLegacyToken
+	}
+
+	user, ok, err := h.authenticate(r.Context(), email, password)
+	if err != nil {
+		slog.Error("failed to authenticate", "error", err, "handler", "Login")
+		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
+		return
+	}
+	if !ok {
+		// Failures for a known email show up in that user's activity
+		event := model.AuthEvent{Kind: model.AuthEventLoginFailed, Method: method, Email: email}
+		if user != nil {
+			event.UserID = &user.ID
+		}
+		if h.failAttempt(r, event) {
+			http.Redirect(w, r, "/login?error=locked_out", http.StatusSeeOther)
+			return
+		}
+		http.Redirect(w, r, "/login?error=invalid_credentials", http.StatusSeeOther)
+		return
+	}
+
+	h.startSession(w, r, "Login", user, method, r.FormValue("redirect"))
+}
+
+// startSession signs user in and sends them on to redirectURL. With two-factor on,
+// the session stays pending until the code is verified, and only then is the sign-in
+// recorded with method.
+func (h *AuthHandler) startSession(w http.ResponseWriter, r *http.Request, handlerName string, user *model.User, method, redirectURL string) {
+	// The cookie holds a random token and only its hash is stored
+	token, tokenHash, err := auth.NewSessionToken()
+	if err != nil {
+		slog.Error("failed to generate session token", "error", err, "handler", handlerName)
+		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
+		return
+	}
+
+	if user.TOTPEnabled() {
+		expiresAt := time.Now().Add(twoFactorTimeout)
+		if _, err := h.sessions.CreatePending(r.Context(), user.ID, tokenHash, r.UserAgent(), auth.ClientIP(r), expiresAt); err != nil {
+			slog.Error("failed to create pending session", "error", err, "handler", handlerName)
+			http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
+			return
+		}
+		auth.SetTwoFactorCookie(w, token, twoFactorTimeout, h.secureCookies)
+		http.Redirect(w, r, twoFactorURL("", redirectURL), http.StatusSeeOther)
 		return
 	}
 
-	// Validate the API token with constant-time comparison
-	if !constantTimeEqual(apiKey, h.apiToken) {
-		http.Redirect(w, r, "/login?error=invalid_key", http.StatusSeeOther)
+	expiresAt := time.Now().Add(h.sessionTTL)
+	if _, err := h.sessions.Create(r.Context(), user.ID, tokenHash, r.UserAgent(), auth.ClientIP(r), expiresAt); err != nil {
+		slog.Error("failed to create session", "error", err, "handler", handlerName)
+		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
 		return
 	}
+	auth.SetSessionCookie(w, token, h.sessionTTL, h.secureCookies)
+	recordAuthEvent(r, h.events, model.AuthEvent{UserID: &user.ID, Kind: model.AuthEventLoginSucceeded, Method: method})
+	redirectAfterLogin(w, r, redirectURL)
+}
 
-	// Set the session cookie with the token value
-	http.SetCookie(w, &http.Cookie{
-		Name:     cookieName,
-		Value:    h.apiToken,
-		Path:     "/",
-		MaxAge:   90 * 24 * 60 * 60, // 90 days
-		HttpOnly: true,
-		Secure:   h.secureCookies,
-		SameSite: http.SameSiteLaxMode,
-	})
+// TwoFactorPage asks for the second factor of a sign-in that passed the password
+func (h *AuthHandler) TwoFactorPage(w http.ResponseWriter, r *http.Request) {
+	session, _, ok := h.pendingSession(w, r, "TwoFactorPage")
+	if !ok {
+		return
+	}
+	if session == nil {
+		http.Redirect(w, r, "/login?error=two_factor_expired", http.StatusSeeOther)
+		return
+	}
 
-	// Redirect to the original URL if provided, otherwise home
+	errorType := r.URL.Query().Get("error")
+	redirectURL := r.URL.Query().Get("redirect")
+	pages.TwoFactorLoginPage(errorType, redirectURL).Render(r.Context(), w)
+}
+
+// VerifyTwoFactor checks an authenticator or recovery code and, if it's right,
+// turns the pending session into a signed-in one
+func (h *AuthHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Redirect(w, r, twoFactorURL("invalid_request", ""), http.StatusSeeOther)
+		return
+	}
 	redirectURL := r.FormValue("redirect")
+
+	session, token, ok := h.pendingSession(w, r, "VerifyTwoFactor")
+	if !ok {
+		return
+	}
+	if session == nil {
+		http.Redirect(w, r, "/login?error=two_factor_expired", http.StatusSeeOther)
+		return
+	}
+	if h.lockedOut(r) {
+		h.abandonTwoFactor(w, r, token, "locked_out")
+		return
+	}
+
+	user, err := h.users.GetByID(ctx, session.UserID)
+	if err != nil {
+		slog.Error("failed to load user", "error", err, "handler", "VerifyTwoFactor")
+		http.Redirect(w, r, twoFactorURL("invalid_request", redirectURL), http.StatusSeeOther)
+		return
+	}
+	if user == nil || !user.TOTPEnabled() {
+		// Deleted, or two-factor was turned off meanwhile; start over
+		h.abandonTwoFactor(w, r, token, "two_factor_expired")
+		return
+	}
+
+	verified, err := verifySecondFactor(ctx, h.users, user, r.FormValue("code"))
+	if err != nil {
+		slog.Error("failed to verify second factor", "error", err, "handler", "VerifyTwoFactor")
+		http.Redirect(w, r, twoFactorURL("invalid_request", redirectURL), http.StatusSeeOther)
+		return
+	}
+	if !verified {
+		event := model.AuthEvent{UserID: &user.ID, Kind: model.AuthEventTwoFactorFailed, Method: model.AuthMethodTwoFactor, Email: user.Email}
+		if h.failAttempt(r, event) {
+			h.abandonTwoFactor(w, r, token, "locked_out")
+			return
+		}
+
+		attempts, err := h.sessions.RecordFailedTOTP(ctx, session.ID)
+		if err != nil {
+			slog.Error("failed to record two-factor attempt", "error", err, "handler", "VerifyTwoFactor")
+		}
+		if err != nil || attempts >= maxTwoFactorAttempts {
+			h.abandonTwoFactor(w, r, token, "too_many_attempts")
+			return
+		}
+		http.Redirect(w, r, twoFactorURL("invalid_code", redirectURL), http.StatusSeeOther)
+		return
+	}
+
+	if err := h.sessions.CompletePending(ctx, session.ID, time.Now().Add(h.sessionTTL)); err != nil {
+		slog.Error("failed to complete session", "error", err, "handler", "VerifyTwoFactor")
+		http.Redirect(w, r, twoFactorURL("invalid_request", redirectURL), http.StatusSeeOther)
+		return
+	}
+	auth.ClearTwoFactorCookie(w, h.secureCookies)
+	auth.SetSessionCookie(w, token, h.sessionTTL, h.secureCookies)
+	recordAuthEvent(r, h.events, model.AuthEvent{UserID: &user.ID, Kind: model.AuthEventLoginSucceeded, Method: model.AuthMethodTwoFactor})
+	redirectAfterLogin(w, r, redirectURL)
+}
+
+// pendingSession looks up the sign-in waiting for a second factor, returning nil if
+// there is none. ok is false when a response has already been written.
+func (h *AuthHandler) pendingSession(w http.ResponseWriter, r *http.Request, handlerName string) (*model.Session, string, bool) {
+	cookie, err := r.Cookie(auth.TwoFactorCookieName)
+	if err != nil {
+		return nil, "", true
+	}
+
+	session, err := h.sessions.GetPendingByTokenHash(r.Context(), auth.HashToken(cookie.Value))
+	if err != nil {
+		slog.Error("failed to load pending session", "error", err, "handler", handlerName)
+		http.Redirect(w, r, "/login?error=invalid_request", http.StatusSeeOther)
+		return nil, "", false
+	}
+	if session == nil {
+		auth.ClearTwoFactorCookie(w, h.secureCookies)
+	}
+	return session, cookie.Value, true
+}
+
+// abandonTwoFactor deletes a pending sign-in and sends the user back to the password form
+func (h *AuthHandler) abandonTwoFactor(w http.ResponseWriter, r *http.Request, token, errorType string) {
+	if err := h.sessions.DeleteByTokenHash(r.Context(), auth.HashToken(token)); err != nil {
+		slog.Error("failed to delete pending session", "error", err, "handler", "VerifyTwoFactor")
+	}
+	auth.ClearTwoFactorCookie(w, h.secureCookies)
+	http.Redirect(w, r, "/login?error="+errorType, http.StatusSeeOther)
+}
+
+// twoFactorURL returns the code entry page, keeping the page to return to afterwards
+func twoFactorURL(errorType, redirectURL string) string {
+	params := url.Values{}
+	if errorType != "" {
+		params.Set("error", errorType)
+	}
+	if redirectURL != "" && isValidRedirect(redirectURL) {
+		params.Set("redirect", redirectURL)
+	}
+	if len(params) == 0 {
+		return "/login/2fa"
+	}
+	return "/login/2fa?" + params.Encode()
+}
+
+// redirectAfterLogin sends a newly signed-in user to the page they asked for, or home
+func redirectAfterLogin(w http.ResponseWriter, r *http.Request, redirectURL string) {
 	if redirectURL == "" || !isValidRedirect(redirectURL) {
 		redirectURL = "/"
 	}
 	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 }
 
+// authenticate returns the account the credentials are for and whether they're
+// valid. The account is nil if none matches.
+func (h *AuthHandler) authenticate(ctx context.Context, email, password string) (*model.User, bool, error) {
+	if email == "" {
+		// Validate the API token with constant-time comparison; an empty token means
+		// the legacy token is disabled
+		if h.apiToken == "" || !constantTimeEqual(password, h.apiToken) {
+			return nil, false, nil
+		}
+		user, err := h.users.GetByID(ctx, model.DefaultUserID)
+		return user, user != nil, err
+	}
+
+	user, err := h.users.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hash := h.dummyHash
+	if user != nil && user.PasswordHash != nil {
+		hash = *user.PasswordHash
+	}
+	ok, err := auth.VerifyPassword(password, hash)
+	if err != nil {
+		return nil, false, err
+	}
+	return user, ok && user != nil && user.PasswordHash != nil, nil
+}
+
 // isValidRedirect checks that the redirect URL is safe (relative path only)
 func isValidRedirect(rawURL string) bool {
 	parsed, err := url.Parse(rawURL)
@@ -87,18 +352,15 @@ func isValidRedirect(rawURL string) bool {
 	return parsed.Scheme == "" && parsed.Host == "" && len(parsed.Path) > 0 && parsed.Path[0] == '/'
 }
 
-// Logout clears the session cookie
+// Logout revokes the browser's session and clears the cookie
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	// Clear the cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     cookieName,
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-		Secure:   h.secureCookies,
-		SameSite: http.SameSiteLaxMode,
-	})
+	if cookie, err := r.Cookie(auth.SessionCookieName); err == nil {
+		if err := h.sessions.DeleteByTokenHash(r.Context(), auth.HashToken(cookie.Value)); err != nil {
+			slog.Error("failed to revoke session", "error", err, "handler", "Logout")
+		}
+	}
+
+	auth.ClearSessionCookie(w, h.secureCookies)
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
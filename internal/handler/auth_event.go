@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/google/uuid"
+)
+
+// activityLimit is how many recent authentication events the activity page shows
+const activityLimit = 100
+
+// AuthEventRecorder adds to the authentication audit log
+type AuthEventRecorder interface {
+	Record(ctx context.Context, event model.AuthEvent) error
+}
+
+// AuthEventRepo defines the repository operations for the authentication audit log
+type AuthEventRepo interface {
+	AuthEventRecorder
+	ListRecent(ctx context.Context, userID *uuid.UUID, limit int) ([]model.AuthEvent, error)
+}
+
+// ActivityHandler shows recent sign-ins and failed attempts
+type ActivityHandler struct {
+	events AuthEventRepo
+}
+
+// NewActivityHandler creates a new ActivityHandler
+func NewActivityHandler(events AuthEventRepo) *ActivityHandler {
+	return &ActivityHandler{
+		events: events,
+	}
+}
+
+// ActivityPage renders recent authentication events. Users see their own; admins
+// see everyone's, including attempts that matched no account and lockouts.
+func (h *ActivityHandler) ActivityPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var userID *uuid.UUID
+	user := auth.UserFromContext(ctx)
+	if !user.IsAdmin {
+		userID = &user.ID
+	}
+
+	events, err := h.events.ListRecent(ctx, userID, activityLimit)
+	if err != nil {
+		slog.Error("failed to list auth events", "handler", "ActivityPage", "error", err)
+		http.Error(w, "Failed to load activity", http.StatusInternalServerError)
+		return
+	}
+
+	pages.ActivityPage(events, user.IsAdmin).Render(ctx, w)
+}
+
+// recordAuthEvent adds event to the audit log with the request's client address and
+// user agent. The log is best effort, so failures to write it are only logged.
+func recordAuthEvent(r *http.Request, events AuthEventRecorder, event model.AuthEvent) {
+	event.IP, event.UserAgent = auth.ClientIP(r), r.UserAgent()
+	if err := events.Record(r.Context(), event); err != nil {
+		slog.Warn("failed to record auth event", "error", err, "kind", event.Kind)
+	}
+}
+
+// lockedOut reports whether the client is locked out, so its attempts are refused
+// before any credentials are checked
+func (h *AuthHandler) lockedOut(r *http.Request) bool {
+	return h.limiter.RetryAfter(auth.ClientIP(r)) > 0
+}
+
+// failAttempt records a failed attempt and counts it towards locking out the client,
+// reporting whether the attempt locked it out
+func (h *AuthHandler) failAttempt(r *http.Request, event model.AuthEvent) bool {
+	recordAuthEvent(r, h.events, event)
+
+	ip := auth.ClientIP(r)
+	lockout := h.limiter.Fail(ip)
+	if lockout != auth.NoLockout {
+		slog.Warn("too many failed sign-ins", "lockout", lockout, "ip", ip)
+		recordAuthEvent(r, h.events, model.AuthEvent{Kind: model.AuthEventLockedOut, Method: lockout.String()})
+	}
+	h.limiter.Wait(r.Context())
+	return lockout == auth.AddressLockout
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type mockAuthEvents struct {
+	events []model.AuthEvent
+}
+
+func (m *mockAuthEvents) Record(ctx context.Context, event model.AuthEvent) error {
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *mockAuthEvents) ListRecent(ctx context.Context, userID *uuid.UUID, limit int) ([]model.AuthEvent, error) {
+	var events []model.AuthEvent
+	for i := len(m.events) - 1; i >= 0 && len(events) < limit; i-- {
+		event := m.events[i]
+		if userID == nil || (event.UserID != nil && *event.UserID == *userID) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// kinds lists the recorded events' kinds in order
+func (m *mockAuthEvents) kinds() []model.AuthEventKind {
+	var kinds []model.AuthEventKind
+	for _, event := range m.events {
+		kinds = append(kinds, event.Kind)
+	}
+	return kinds
+}
+
+func TestLoginRecordsEvents(t *testing.T) {
+	hash, err := auth.HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: &hash}
+	events := &mockAuthEvents{}
+	h := NewAuthHandler("", false, time.Hour, &mockUserRepo{users: []*model.User{alice}}, newMockSessionRepo(), events, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	for _, form := range []url.Values{
+		{"email": {"alice@example.com"}, "password": {"wrong"}},
+		{"email": {"bob@example.com"}, "password": {"hunter2"}},
+		{"email": {"alice@example.com"}, "password": {"hunter2"}},
+	} {
+		req := newLoginRequest(form)
+		req.Header.Set("User-Agent", "curl/8.0")
+		h.Login(httptest.NewRecorder(), req)
+	}
+
+	if len(events.events) != 3 {
+		t.Fatalf("recorded %d events, want 3", len(events.events))
+	}
+	wrongPassword, unknown, success := events.events[0], events.events[1], events.events[2]
+
+	if wrongPassword.Kind != model.AuthEventLoginFailed || wrongPassword.UserID == nil || *wrongPassword.UserID != alice.ID {
+		t.Errorf("wrong password event = %+v, want a failure for alice", wrongPassword)
+	}
+	if unknown.Kind != model.AuthEventLoginFailed || unknown.UserID != nil || unknown.Email != "bob@example.com" {
+		t.Errorf("unknown email event = %+v, want a failure for no account", unknown)
+	}
+	if success.Kind != model.AuthEventLoginSucceeded || success.Method != model.AuthMethodPassword || *success.UserID != alice.ID {
+		t.Errorf("success event = %+v, want alice signing in with a password", success)
+	}
+	if success.IP != "192.0.2.1" || success.UserAgent != "curl/8.0" {
+		t.Errorf("success event client = %q, %q", success.IP, success.UserAgent)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	hash, err := auth.HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: &hash}
+	events := &mockAuthEvents{}
+	sessions := newMockSessionRepo()
+	limiter := auth.NewLimiter(auth.LimiterConfig{MaxFailures: 3})
+	h := NewAuthHandler("", false, time.Hour, &mockUserRepo{users: []*model.User{alice}}, sessions, events, limiter, nil)
+
+	login := func(password string) string {
+		rec := httptest.NewRecorder()
+		h.Login(rec, newLoginRequest(url.Values{"email": {"alice@example.com"}, "password": {password}}))
+		return rec.Header().Get("Location")
+	}
+
+	for range 2 {
+		if got := login("wrong"); got != "/login?error=invalid_credentials" {
+			t.Fatalf("Location = %q, want invalid credentials", got)
+		}
+	}
+	if got := login("wrong"); got != "/login?error=locked_out" {
+		t.Fatalf("Location at the limit = %q, want locked out", got)
+	}
+
+	// Every attempt is refused until the lockout expires, even the right password
+	for _, password := range []string{"wrong", "hunter2"} {
+		if got := login(password); got != "/login?error=locked_out" {
+			t.Errorf("Location for %q while locked out = %q, want locked out", password, got)
+		}
+	}
+	if len(sessions.sessions) != 0 {
+		t.Error("expected no session while locked out")
+	}
+
+	want := []model.AuthEventKind{
+		model.AuthEventLoginFailed, model.AuthEventLoginFailed, model.AuthEventLoginFailed, model.AuthEventLockedOut,
+	}
+	if got := events.kinds(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if lockout := events.events[3]; lockout.Method != "address" || lockout.IP != "192.0.2.1" {
+		t.Errorf("lockout event = %+v", lockout)
+	}
+}
+
+func TestActivityPage(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	admin := &model.User{ID: uuid.New(), Email: "admin@example.com", IsAdmin: true}
+	events := &mockAuthEvents{}
+	events.Record(context.Background(), model.AuthEvent{UserID: &alice.ID, Kind: model.AuthEventLoginSucceeded, Method: model.AuthMethodSSO, IP: "10.0.0.1"})
+	events.Record(context.Background(), model.AuthEvent{UserID: &alice.ID, Kind: model.AuthEventLoginFailed, Method: model.AuthMethodPassword, IP: "10.0.0.2"})
+	events.Record(context.Background(), model.AuthEvent{Kind: model.AuthEventLoginFailed, Method: model.AuthMethodPassword, Email: "mallory@example.com", IP: "10.0.0.3"})
+	events.Record(context.Background(), model.AuthEvent{Kind: model.AuthEventLockedOut, Method: "address", IP: "10.0.0.3"})
+
+	h := NewActivityHandler(events)
+
+	tests := []struct {
+		name     string
+		user     *model.User
+		want     []string
+		dontWant []string
+	}{
+		{
+			name:     "own activity",
+			user:     alice,
+			want:     []string{"Signed in with single sign-on", "Failed sign-in with password", "10.0.0.2"},
+			dontWant: []string{"10.0.0.3", "mallory@example.com"},
+		},
+		{
+			name: "admin sees everyone",
+			user: admin,
+			want: []string{"10.0.0.1", "mallory@example.com", "Sign-in locked for this address"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/settings/activity", nil)
+			h.ActivityPage(rec, req.WithContext(auth.WithUser(req.Context(), tt.user)))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+			body := rec.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q", want)
+				}
+			}
+			for _, dontWant := range tt.dontWant {
+				if strings.Contains(body, dontWant) {
+					t.Errorf("body shows %q", dontWant)
+				}
+			}
+		})
+	}
+}
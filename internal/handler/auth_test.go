@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type mockUserRepo struct {
+	users []*model.User
+	// recoveryCodes maps code hashes to whether they're still unused
+	recoveryCodes map[string]bool
+	// identities maps linked identity provider accounts to user IDs
+	identities map[identityKey]uuid.UUID
+}
+
+func (m *mockUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockUserRepo) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	for _, u := range m.users {
+		if strings.EqualFold(u.Email, email) {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockUserRepo) AdvanceTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	user, _ := m.GetByID(ctx, id)
+	if user == nil || user.TOTPLastStep >= step {
+		return false, nil
+	}
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+func (m *mockUserRepo) UseRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error) {
+	if !m.recoveryCodes[codeHash] {
+		return false, nil
+	}
+	m.recoveryCodes[codeHash] = false
+	return true, nil
+}
+
+func newLoginRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := auth.HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: &hash}
+	admin := &model.User{ID: model.DefaultUserID, Email: "admin@localhost", IsAdmin: true}
+	sessions := newMockSessionRepo()
+	h := NewAuthHandler("api-token", false, time.Hour, &mockUserRepo{users: []*model.User{alice, admin}}, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	tests := []struct {
+		name         string
+		form         url.Values
+		wantLocation string
+		wantUser     uuid.UUID
+	}{
+		{
+			name:         "email and password",
+			form:         url.Values{"email": {"Alice@Example.com"}, "password": {"hunter2"}, "redirect": {"/report"}},
+			wantLocation: "/report",
+			wantUser:     alice.ID,
+		},
+		{
+			name:         "api token as default user",
+			form:         url.Values{"password": {"api-token"}},
+			wantLocation: "/",
+			wantUser:     model.DefaultUserID,
+		},
+		{
+			name:         "wrong password",
+			form:         url.Values{"email": {"alice@example.com"}, "password": {"wrong"}},
+			wantLocation: "/login?error=invalid_credentials",
+		},
+		{
+			name:         "unknown email",
+			form:         url.Values{"email": {"bob@example.com"}, "password": {"hunter2"}},
+			wantLocation: "/login?error=invalid_credentials",
+		},
+		{
+			name:         "account without password",
+			form:         url.Values{"email": {"admin@localhost"}, "password": {"api-token"}},
+			wantLocation: "/login?error=invalid_credentials",
+		},
+		{
+			name:         "wrong api token",
+			form:         url.Values{"password": {"nope"}},
+			wantLocation: "/login?error=invalid_credentials",
+		},
+		{
+			name:         "missing password",
+			form:         url.Values{"email": {"alice@example.com"}},
+			wantLocation: "/login?error=missing_password",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.Login(rec, newLoginRequest(tt.form))
+
+			if rec.Code != http.StatusSeeOther {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusSeeOther)
+			}
+			if got := rec.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+
+			var token string
+			for _, c := range rec.Result().Cookies() {
+				if c.Name == auth.SessionCookieName {
+					token = c.Value
+				}
+			}
+			if tt.wantUser == uuid.Nil {
+				if token != "" {
+					t.Errorf("unexpected session cookie %q", token)
+				}
+				return
+			}
+			if token == "api-token" {
+				t.Fatal("session cookie holds the API token")
+			}
+			session := sessions.sessions[auth.HashToken(token)]
+			if session == nil || session.UserID != tt.wantUser {
+				t.Errorf("session = %+v, want one for user %v", session, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	sessions := newMockSessionRepo()
+	token, hash, err := auth.NewSessionToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions.Create(context.Background(), model.DefaultUserID, hash, "", "", time.Now().Add(time.Hour))
+	h := NewAuthHandler("api-token", false, time.Hour, &mockUserRepo{}, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: token})
+	rec := httptest.NewRecorder()
+	h.Logout(rec, req)
+
+	if len(sessions.sessions) != 0 {
+		t.Error("expected the session to be revoked")
+	}
+	if got := rec.Header().Get("Location"); got != "/login" {
+		t.Errorf("Location = %q, want /login", got)
+	}
+}
+
+func TestLoginPageClearsDeadSession(t *testing.T) {
+	sessions := newMockSessionRepo()
+	token, hash, err := auth.NewSessionToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessions.Create(context.Background(), model.DefaultUserID, hash, "", "", time.Now().Add(time.Hour))
+	h := NewAuthHandler("", false, time.Hour, &mockUserRepo{}, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	page := func(cookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/login", nil)
+		req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: cookie})
+		req = req.WithContext(auth.WithCSRFToken(req.Context(), auth.CSRFToken(cookie)))
+		rec := httptest.NewRecorder()
+		h.LoginPage(rec, req)
+		return rec
+	}
+
+	if rec := page(token); rec.Code != http.StatusSeeOther {
+		t.Errorf("live session status = %d, want a redirect home", rec.Code)
+	}
+
+	rec := page("revoked-token")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dead session status = %d, want the login form", rec.Code)
+	}
+	cleared := false
+	for _, cookie := range rec.Result().Cookies() {
+		cleared = cleared || (cookie.Name == auth.SessionCookieName && cookie.MaxAge < 0)
+	}
+	if !cleared {
+		t.Error("expected the dead session cookie to be cleared")
+	}
+	if strings.Contains(rec.Body.String(), auth.CSRFToken("revoked-token")) {
+		t.Error("expected the form not to carry the dead session's CSRF token")
+	}
+}
+
+func TestLoginLegacyTokenDisabled(t *testing.T) {
+	admin := &model.User{ID: model.DefaultUserID, Email: "admin@localhost", IsAdmin: true}
+	sessions := newMockSessionRepo()
+	h := NewAuthHandler("", false, time.Hour, &mockUserRepo{users: []*model.User{admin}}, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	rec := httptest.NewRecorder()
+	h.Login(rec, newLoginRequest(url.Values{"password": {"anything"}}))
+
+	if got := rec.Header().Get("Location"); got != "/login?error=invalid_credentials" {
+		t.Errorf("Location = %q, want invalid credentials", got)
+	}
+	if len(sessions.sessions) != 0 {
+		t.Error("expected no session to be created")
+	}
+}
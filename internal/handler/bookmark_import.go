@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/importer"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/google/uuid"
+)
+
+// maxBookmarkImportBytes limits the size of an uploaded bookmark export
+const maxBookmarkImportBytes = 32 << 20
+
+// importJobLimit is the number of recent import jobs shown on the import page
+const importJobLimit = 20
+
+// ImportJobRepo defines the repository operations used by bookmark imports
+type ImportJobRepo interface {
+	CreateJob(ctx context.Context, job *model.ImportJob, items []model.ImportItem) (*model.ImportJob, error)
+	ListJobs(ctx context.Context, userID uuid.UUID, limit int) ([]model.ImportJob, error)
+}
+
+// BookmarkImportHandler handles uploads of browser and read-later service exports
+type BookmarkImportHandler struct {
+	importRepo ImportJobRepo
+}
+
+// NewBookmarkImportHandler creates a new BookmarkImportHandler
+func NewBookmarkImportHandler(importRepo ImportJobRepo) *BookmarkImportHandler {
+	return &BookmarkImportHandler{
+		importRepo: importRepo,
+	}
+}
+
+// ImportPage renders the upload form and recent import jobs
+func (h *BookmarkImportHandler) ImportPage(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.importRepo.ListJobs(r.Context(), auth.UserID(r.Context()), importJobLimit)
+	if err != nil {
+		slog.Error("failed to list import jobs", "handler", "ImportPage", "error", err)
+		http.Error(w, "Failed to load imports", http.StatusInternalServerError)
+		return
+	}
+
+	pages.ImportPage(jobs).Render(r.Context(), w)
+}
+
+// Jobs renders the import job list, polled while jobs are running
+func (h *BookmarkImportHandler) Jobs(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.importRepo.ListJobs(r.Context(), auth.UserID(r.Context()), importJobLimit)
+	if err != nil {
+		slog.Error("failed to list import jobs", "handler", "Jobs", "error", err)
+		http.Error(w, "Failed to load imports", http.StatusInternalServerError)
+		return
+	}
+
+	partials.ImportJobs(jobs).Render(r.Context(), w)
+}
+
+// Upload parses an uploaded export and queues its links as an import job.
+// Entries are created by the background worker as the enrichment queue drains.
+func (h *BookmarkImportHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBookmarkImportBytes)
+	if err := r.ParseMultipartForm(maxBookmarkImportBytes); err != nil {
+		htmxError(w, "Upload is too large or invalid")
+		return
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = importer.FormatAuto
+	}
+	if !slices.Contains(importer.Formats, format) {
+		htmxError(w, "Unsupported import format")
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		htmxError(w, "Choose a file to import")
+		return
+	}
+	defer file.Close()
+
+	bookmarks, err := importer.Parse(format, file)
+	if err != nil {
+		htmxError(w, fmt.Sprintf("Could not read %s: %v", header.Filename, err))
+		return
+	}
+	if len(bookmarks) == 0 {
+		htmxError(w, "No links found in "+header.Filename)
+		return
+	}
+
+	job, err := h.importRepo.CreateJob(ctx, &model.ImportJob{
+		UserID:   auth.UserID(ctx),
+		Format:   format,
+		Filename: header.Filename,
+	}, importItems(bookmarks))
+	if err != nil {
+		slog.Error("failed to create import job", "handler", "Upload", "count", len(bookmarks), "error", err)
+		htmxError(w, "Failed to start import")
+		return
+	}
+
+	slog.Info("started import job", "id", job.ID, "format", format, "count", job.Total)
+
+	jobs, err := h.importRepo.ListJobs(ctx, auth.UserID(ctx), importJobLimit)
+	if err != nil {
+		slog.Error("failed to list import jobs", "handler", "Upload", "error", err)
+		htmxError(w, "Failed to load imports")
+		return
+	}
+
+	htmxToast(w, fmt.Sprintf("Importing %d links", job.Total), nil, "")
+	partials.ImportJobs(jobs).Render(ctx, w)
+}
+
+// importItems converts parsed bookmarks to pending import items, dropping empty fields
+func importItems(bookmarks []importer.Bookmark) []model.ImportItem {
+	items := make([]model.ImportItem, len(bookmarks))
+	for i, b := range bookmarks {
+		item := model.ImportItem{
+			Position: i,
+			URL:      b.URL,
+			Title:    optionalString(b.Title),
+			Tag:      optionalString(b.Tag),
+			Notes:    optionalString(b.Notes),
+		}
+		if !b.SavedAt.IsZero() {
+			savedAt := b.SavedAt
+			item.SavedAt = &savedAt
+		}
+		items[i] = item
+	}
+	return items
+}
+
+// optionalString returns nil for blank strings
+func optionalString(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type mockImportJobRepo struct {
+	job   *model.ImportJob
+	items []model.ImportItem
+}
+
+func (m *mockImportJobRepo) CreateJob(ctx context.Context, job *model.ImportJob, items []model.ImportItem) (*model.ImportJob, error) {
+	created := *job
+	created.ID = uuid.New()
+	created.Status = model.ImportJobRunning
+	created.Total = len(items)
+	created.Pending = len(items)
+	m.job = &created
+	m.items = items
+	return &created, nil
+}
+
+func (m *mockImportJobRepo) ListJobs(ctx context.Context, userID uuid.UUID, limit int) ([]model.ImportJob, error) {
+	if m.job == nil {
+		return nil, nil
+	}
+	return []model.ImportJob{*m.job}, nil
+}
+
+func newImportUpload(t *testing.T, format, filename, content string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	if format != "" {
+		mw.WriteField("format", format)
+	}
+	if filename != "" {
+		fw, err := mw.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import/bookmarks", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestBookmarkImportUpload(t *testing.T) {
+	const instapaper = "URL,Title,Selection,Folder,Timestamp\n" +
+		"https://example.com/1,First,,Databases,1680000000\n" +
+		"https://example.com/2,,,Unread,\n"
+
+	repo := &mockImportJobRepo{}
+	h := NewBookmarkImportHandler(repo)
+
+	rec := httptest.NewRecorder()
+	h.Upload(rec, newImportUpload(t, "", "instapaper.csv", instapaper))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if repo.job == nil || repo.job.Format != "auto" || repo.job.Filename != "instapaper.csv" {
+		t.Fatalf("job = %+v", repo.job)
+	}
+	if len(repo.items) != 2 {
+		t.Fatalf("got %d items, want 2", len(repo.items))
+	}
+
+	first := repo.items[0]
+	if first.Tag == nil || *first.Tag != "databases" || first.Title == nil || *first.Title != "First" {
+		t.Errorf("item 0 = %+v", first)
+	}
+	if first.SavedAt == nil || !first.SavedAt.Equal(time.Unix(1680000000, 0)) {
+		t.Errorf("item 0 SavedAt = %v", first.SavedAt)
+	}
+
+	second := repo.items[1]
+	if second.Position != 1 || second.Tag != nil || second.Title != nil || second.SavedAt != nil {
+		t.Errorf("item 1 = %+v", second)
+	}
+
+	if rec.Header().Get("HX-Trigger") == "" {
+		t.Error("expected a toast trigger")
+	}
+	if !bytes.Contains(rec.Body.Bytes(), []byte(`hx-trigger="every 2s"`)) {
+		t.Error("expected the job list to poll while the import runs")
+	}
+}
+
+func TestBookmarkImportUploadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		filename string
+		content  string
+	}{
+		{name: "missing file", format: "auto"},
+		{name: "unsupported format", format: "delicious", filename: "links.html", content: "<a href=\"https://example.com\">x</a>"},
+		{name: "unrecognized content", filename: "notes.txt", content: "not an export"},
+		{name: "no links", format: "bookmarks", filename: "empty.html", content: "<DL><p></DL>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockImportJobRepo{}
+			h := NewBookmarkImportHandler(repo)
+
+			rec := httptest.NewRecorder()
+			h.Upload(rec, newImportUpload(t, tt.format, tt.filename, tt.content))
+
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+			}
+			if repo.job != nil {
+				t.Error("expected no import job to be created")
+			}
+		})
+	}
+}
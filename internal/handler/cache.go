@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/drywaters/learnd/internal/repository"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// cachePageLimit is the number of cached summaries shown on the admin page
+const cachePageLimit = 100
+
+// CacheHandler handles the summary cache admin page
+type CacheHandler struct {
+	cacheRepo *repository.SummaryCacheRepository
+}
+
+// NewCacheHandler creates a new CacheHandler
+func NewCacheHandler(cacheRepo *repository.SummaryCacheRepository) *CacheHandler {
+	return &CacheHandler{
+		cacheRepo: cacheRepo,
+	}
+}
+
+// CachePage renders the summary cache page
+func (h *CacheHandler) CachePage(w http.ResponseWriter, r *http.Request) {
+	data, err := h.loadData(r)
+	if err != nil {
+		slog.Error("failed to load summary cache", "handler", "CachePage", "error", err)
+		http.Error(w, "Failed to load summary cache", http.StatusInternalServerError)
+		return
+	}
+
+	pages.CachePage(*data).Render(r.Context(), w)
+}
+
+// Purge removes expired cached summaries, or all of them with scope=all
+func (h *CacheHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var count int64
+	var err error
+	switch scope := r.FormValue("scope"); scope {
+	case "expired":
+		count, err = h.cacheRepo.PurgeExpired(ctx)
+	case "all":
+		count, err = h.cacheRepo.PurgeAll(ctx)
+	default:
+		http.Error(w, "Invalid scope", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to purge summary cache", "handler", "Purge", "error", err)
+		htmxError(w, "Failed to purge cache")
+		return
+	}
+
+	htmxToast(w, fmt.Sprintf("Removed %d cached summaries", count), nil, "")
+	h.renderPanel(w, r)
+}
+
+// Delete removes a single cached summary
+func (h *CacheHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.cacheRepo.Delete(r.Context(), id); err != nil {
+		slog.Error("failed to delete cached summary", "handler", "Delete", "id", id, "error", err)
+		htmxError(w, "Failed to remove cached summary")
+		return
+	}
+
+	htmxToast(w, "Cached summary removed", nil, "")
+	h.renderPanel(w, r)
+}
+
+// ResetStats zeroes the cache hit statistics
+func (h *CacheHandler) ResetStats(w http.ResponseWriter, r *http.Request) {
+	if err := h.cacheRepo.ResetStats(r.Context()); err != nil {
+		slog.Error("failed to reset cache stats", "handler", "ResetStats", "error", err)
+		htmxError(w, "Failed to reset statistics")
+		return
+	}
+
+	htmxToast(w, "Cache statistics reset", nil, "")
+	h.renderPanel(w, r)
+}
+
+func (h *CacheHandler) renderPanel(w http.ResponseWriter, r *http.Request) {
+	data, err := h.loadData(r)
+	if err != nil {
+		slog.Error("failed to load summary cache", "error", err)
+		http.Error(w, "Failed to load summary cache", http.StatusInternalServerError)
+		return
+	}
+
+	partials.SummaryCachePanel(*data).Render(r.Context(), w)
+}
+
+func (h *CacheHandler) loadData(r *http.Request) (*partials.SummaryCacheData, error) {
+	ctx := r.Context()
+
+	stats, err := h.cacheRepo.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, err := h.cacheRepo.List(ctx, cachePageLimit, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &partials.SummaryCacheData{
+		Entries:  stats.Entries,
+		Expired:  stats.Expired,
+		Hits:     stats.Hits,
+		Misses:   stats.Misses,
+		Bypasses: stats.Bypasses,
+		HitRate:  stats.HitRate(),
+		ResetAt:  stats.ResetAt,
+		Items:    items,
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"net/http"
 
+	"github.com/drywaters/learnd/internal/auth"
 	"github.com/drywaters/learnd/internal/repository"
 	"github.com/drywaters/learnd/internal/ui/pages"
 )
@@ -25,7 +26,7 @@ func (h *CaptureHandler) CapturePage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Get recent entries
-	entries, err := h.entryRepo.List(ctx, repository.ListOptions{Limit: 20})
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), repository.ListOptions{Limit: 20})
 	if err != nil {
 		slog.Error("failed to list entries", "handler", "CapturePage", "error", err)
 		http.Error(w, "Failed to load entries", http.StatusInternalServerError)
@@ -3,7 +3,6 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
-	"html"
 	"log/slog"
 	"net/http"
 	"regexp"
@@ -11,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/drywaters/learnd/internal/auth"
 	"github.com/drywaters/learnd/internal/model"
 	"github.com/drywaters/learnd/internal/repository"
 	"github.com/drywaters/learnd/internal/ui/pages"
@@ -37,13 +37,13 @@ func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	if err := r.ParseForm(); err != nil {
-		h.htmxError(w, "Invalid form data")
+		htmxError(w, "Invalid form data")
 		return
 	}
 
 	url := strings.TrimSpace(r.FormValue("url"))
 	if url == "" {
-		h.htmxError(w, "URL is required")
+		htmxError(w, "URL is required")
 		return
 	}
 
@@ -55,9 +55,9 @@ func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if !allowDuplicate {
-		existing, err := h.entryRepo.GetLatestByNormalizedURL(ctx, normalizedURL)
+		existing, err := h.entryRepo.GetLatestByNormalizedURL(ctx, auth.UserID(ctx), normalizedURL)
 		if err != nil {
-			h.htmxError(w, "Failed to check duplicates")
+			htmxError(w, "Failed to check duplicates")
 			return
 		}
 		if existing != nil {
@@ -74,7 +74,7 @@ func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Parse optional fields
 	tag, err := parseTag(r.FormValue("tag"))
 	if err != nil {
-		h.htmxError(w, err.Error())
+		htmxError(w, err.Error())
 		return
 	}
 	timeSpent := parseTimeSpentMinutes(r.FormValue("time_spent"))
@@ -82,6 +82,7 @@ func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	notes := parseOptionalString(r.FormValue("notes"))
 
 	input := &model.CreateEntryInput{
+		UserID:           auth.UserID(ctx),
 		SourceURL:        url,
 		NormalizedURL:    normalizedURL,
 		Tag:              tag,
@@ -92,12 +93,12 @@ func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	entry, err := h.entryRepo.Create(ctx, input)
 	if err != nil {
-		h.htmxError(w, "Failed to create entry")
+		htmxError(w, "Failed to create entry")
 		return
 	}
 
 	// Get updated entry count
-	count, err := h.entryRepo.Count(ctx)
+	count, err := h.entryRepo.Count(ctx, auth.UserID(ctx))
 	if err != nil {
 		count = 1 // Fallback to at least 1 since we just created one
 	}
@@ -105,7 +106,7 @@ func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Entry-Created", "true")
 
 	// Trigger toast and return the new entry row
-	h.htmxToast(w, "Entry saved", &entry.ID, "")
+	htmxToast(w, "Entry saved", &entry.ID, "")
 
 	// Render entry row
 	duplicateCount := getDuplicateCount(ctx, h.entryRepo, entry)
@@ -113,7 +114,7 @@ func (h *EntryHandler) Create(w http.ResponseWriter, r *http.Request) {
 	partials.EntryRow(entryView).Render(ctx, w)
 
 	if duplicateCount > 1 {
-		duplicates, err := h.entryRepo.ListByNormalizedURL(ctx, entry.NormalizedURL)
+		duplicates, err := h.entryRepo.ListByNormalizedURL(ctx, auth.UserID(ctx), entry.NormalizedURL)
 		if err == nil {
 			for _, duplicate := range duplicates {
 				if duplicate.ID == entry.ID {
@@ -157,7 +158,7 @@ func (h *EntryHandler) List(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	entries, err := h.entryRepo.List(ctx, repository.ListOptions{
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), repository.ListOptions{
 		Limit:  limit,
 		Offset: offset,
 	})
@@ -186,7 +187,7 @@ func (h *EntryHandler) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entry, err := h.entryRepo.GetByID(ctx, id)
+	entry, err := h.entryRepo.GetByID(ctx, auth.UserID(ctx), id)
 	if err != nil {
 		slog.Error("failed to get entry", "handler", "Get", "id", id, "error", err)
 		http.Error(w, "Failed to get entry", http.StatusInternalServerError)
@@ -216,7 +217,7 @@ func (h *EntryHandler) EditPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entry, err := h.entryRepo.GetByID(ctx, id)
+	entry, err := h.entryRepo.GetByID(ctx, auth.UserID(ctx), id)
 	if err != nil {
 		slog.Error("failed to get entry", "handler", "EditPage", "id", id, "error", err)
 		http.Error(w, "Failed to get entry", http.StatusInternalServerError)
@@ -251,7 +252,7 @@ func (h *EntryHandler) Update(w http.ResponseWriter, r *http.Request) {
 	// Parse user fields
 	tag, err := parseTag(r.FormValue("tag"))
 	if err != nil {
-		h.htmxError(w, err.Error())
+		htmxError(w, err.Error())
 		return
 	}
 	timeSpent := parseTimeSpentMinutes(r.FormValue("time_spent"))
@@ -275,7 +276,7 @@ func (h *EntryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		SourceType:       sourceType,
 	}
 
-	entry, err := h.entryRepo.Update(ctx, id, input)
+	entry, err := h.entryRepo.Update(ctx, auth.UserID(ctx), id, input)
 	if err != nil {
 		slog.Error("failed to update entry", "handler", "Update", "id", id, "error", err)
 		http.Error(w, "Failed to update entry", http.StatusInternalServerError)
@@ -286,13 +287,49 @@ func (h *EntryHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.htmxToast(w, "Entry updated", &entry.ID, "")
+	htmxToast(w, "Entry updated", &entry.ID, "")
 
 	duplicateCount := getDuplicateCount(ctx, h.entryRepo, entry)
 	entryView := buildEntryView(entry, duplicateCount)
 	partials.EntryRow(entryView).Render(ctx, w)
 }
 
+// AcceptTag sets an entry's tag in one click, e.g. from an AI-suggested tag
+func (h *EntryHandler) AcceptTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	tag, err := parseTag(r.FormValue("tag"))
+	if err != nil {
+		htmxError(w, err.Error())
+		return
+	}
+	if tag == nil {
+		htmxError(w, "Tag is required")
+		return
+	}
+
+	found, err := h.entryRepo.UpdateTag(ctx, auth.UserID(ctx), id, tag)
+	if err != nil {
+		slog.Error("failed to update tag", "handler", "AcceptTag", "id", id, "error", err)
+		http.Error(w, "Failed to update tag", http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.Error(w, "Entry not found", http.StatusNotFound)
+		return
+	}
+
+	htmxToast(w, fmt.Sprintf("Tagged as %s", *tag), &id, "")
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // Delete removes an entry
 func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -304,7 +341,7 @@ func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entry, err := h.entryRepo.GetByID(ctx, id)
+	entry, err := h.entryRepo.GetByID(ctx, auth.UserID(ctx), id)
 	if err != nil {
 		slog.Error("failed to get entry", "handler", "Delete", "id", id, "error", err)
 		http.Error(w, "Failed to get entry", http.StatusInternalServerError)
@@ -322,19 +359,19 @@ func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := h.entryRepo.Delete(ctx, id); err != nil {
+	if err := h.entryRepo.Delete(ctx, auth.UserID(ctx), id); err != nil {
 		slog.Error("failed to delete entry", "handler", "Delete", "id", id, "error", err)
 		http.Error(w, "Failed to delete entry", http.StatusInternalServerError)
 		return
 	}
 
 	// Get updated entry count
-	count, err := h.entryRepo.Count(ctx)
+	count, err := h.entryRepo.Count(ctx, auth.UserID(ctx))
 	if err != nil {
 		count = 0
 	}
 
-	h.htmxToast(w, "Entry deleted", &id, "")
+	htmxToast(w, "Entry deleted", &id, "")
 
 	// Render OOB swap for entry count
 	partials.EntryCount(count).Render(ctx, w)
@@ -343,7 +380,7 @@ func (h *EntryHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	partials.EmptyState(count == 0, true).Render(ctx, w)
 
 	if normalizedURL != "" {
-		duplicates, err := h.entryRepo.ListByNormalizedURL(ctx, normalizedURL)
+		duplicates, err := h.entryRepo.ListByNormalizedURL(ctx, auth.UserID(ctx), normalizedURL)
 		if err == nil && len(duplicates) > 0 {
 			duplicateCount := len(duplicates)
 			for _, duplicate := range duplicates {
@@ -366,26 +403,26 @@ func (h *EntryHandler) RefreshEnrichment(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := h.entryRepo.ResetEnrichment(ctx, id); err != nil {
+	if err := h.entryRepo.ResetEnrichment(ctx, auth.UserID(ctx), id); err != nil {
 		slog.Error("failed to reset enrichment", "handler", "RefreshEnrichment", "id", id, "error", err)
 		http.Error(w, "Failed to reset enrichment", http.StatusInternalServerError)
 		return
 	}
 
-	entry, err := h.entryRepo.GetByID(ctx, id)
+	entry, err := h.entryRepo.GetByID(ctx, auth.UserID(ctx), id)
 	if err != nil || entry == nil {
 		http.Error(w, "Entry not found", http.StatusNotFound)
 		return
 	}
 
-	h.htmxToast(w, "Enrichment queued", &id, "")
+	htmxToast(w, "Enrichment queued", &id, "")
 
 	duplicateCount := getDuplicateCount(ctx, h.entryRepo, entry)
 	entryView := buildEntryView(entry, duplicateCount)
 	partials.EntryRow(entryView).Render(ctx, w)
 }
 
-// RefreshSummary resets summary status to pending
+// RefreshSummary resets summary status to pending, optionally bypassing the summary cache
 func (h *EntryHandler) RefreshSummary(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -396,19 +433,22 @@ func (h *EntryHandler) RefreshSummary(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.entryRepo.ResetSummary(ctx, id); err != nil {
+	// bypass_cache regenerates the summary even if a cached one matches
+	bypassCache := r.FormValue("bypass_cache") == "1"
+
+	if err := h.entryRepo.ResetSummary(ctx, auth.UserID(ctx), id, bypassCache); err != nil {
 		slog.Error("failed to reset summary", "handler", "RefreshSummary", "id", id, "error", err)
 		http.Error(w, "Failed to reset summary", http.StatusInternalServerError)
 		return
 	}
 
-	entry, err := h.entryRepo.GetByID(ctx, id)
+	entry, err := h.entryRepo.GetByID(ctx, auth.UserID(ctx), id)
 	if err != nil || entry == nil {
 		http.Error(w, "Entry not found", http.StatusNotFound)
 		return
 	}
 
-	h.htmxToast(w, "Summary queued", &id, "")
+	htmxToast(w, "Summary queued", &id, "")
 
 	duplicateCount := getDuplicateCount(ctx, h.entryRepo, entry)
 	entryView := buildEntryView(entry, duplicateCount)
@@ -426,7 +466,7 @@ func (h *EntryHandler) Status(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entry, err := h.entryRepo.GetByID(ctx, id)
+	entry, err := h.entryRepo.GetByID(ctx, auth.UserID(ctx), id)
 	if err != nil || entry == nil {
 		http.Error(w, "Entry not found", http.StatusNotFound)
 		return
@@ -437,32 +477,6 @@ func (h *EntryHandler) Status(w http.ResponseWriter, r *http.Request) {
 	partials.EntryRow(entryView).Render(ctx, w)
 }
 
-func (h *EntryHandler) htmxError(w http.ResponseWriter, msg string) {
-	w.Header().Set("HX-Retarget", "#form-error")
-	w.Header().Set("HX-Reswap", "innerHTML")
-	w.WriteHeader(http.StatusUnprocessableEntity)
-	fmt.Fprintf(w, `<p class="text-sm" style="color: var(--color-error);">%s</p>`, html.EscapeString(msg))
-}
-
-func (h *EntryHandler) htmxToast(w http.ResponseWriter, msg string, entryID *uuid.UUID, toastType string) {
-	showToast := map[string]string{
-		"message": msg,
-	}
-	if entryID != nil {
-		showToast["id"] = entryID.String()
-	}
-	if toastType != "" {
-		showToast["type"] = toastType
-	}
-
-	payload := map[string]map[string]string{
-		"showToast": showToast,
-	}
-	if data, err := json.Marshal(payload); err == nil {
-		w.Header().Set("HX-Trigger", string(data))
-	}
-}
-
 var tagRegex = regexp.MustCompile(`^[a-z0-9-]+$`)
 
 // parseTag trims whitespace, lowercases the input, and validates it as a single tag.
@@ -11,16 +11,17 @@ import (
 // EntryRepo defines the interface for entry repository operations.
 // This interface allows for easier testing with mock implementations.
 type EntryRepo interface {
-	GetByID(ctx context.Context, id uuid.UUID) (*model.Entry, error)
+	GetByID(ctx context.Context, userID, id uuid.UUID) (*model.Entry, error)
 	Create(ctx context.Context, input *model.CreateEntryInput) (*model.Entry, error)
-	Update(ctx context.Context, id uuid.UUID, input *model.UpdateEntryInput) (*model.Entry, error)
-	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, opts repository.ListOptions) ([]model.Entry, error)
-	Count(ctx context.Context) (int, error)
-	GetLatestByNormalizedURL(ctx context.Context, normalizedURL string) (*repository.DuplicateEntry, error)
-	CountByNormalizedURL(ctx context.Context, normalizedURL string) (int, error)
-	GetDuplicateCountsByNormalizedURL(ctx context.Context, normalizedURLs []string) (map[string]int, error)
-	ListByNormalizedURL(ctx context.Context, normalizedURL string) ([]model.Entry, error)
-	ResetEnrichment(ctx context.Context, id uuid.UUID) error
-	ResetSummary(ctx context.Context, id uuid.UUID) error
+	Update(ctx context.Context, userID, id uuid.UUID, input *model.UpdateEntryInput) (*model.Entry, error)
+	UpdateTag(ctx context.Context, userID, id uuid.UUID, tag *string) (bool, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) error
+	List(ctx context.Context, userID uuid.UUID, opts repository.ListOptions) ([]model.Entry, error)
+	Count(ctx context.Context, userID uuid.UUID) (int, error)
+	GetLatestByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (*repository.DuplicateEntry, error)
+	CountByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (int, error)
+	GetDuplicateCountsByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURLs []string) (map[string]int, error)
+	ListByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) ([]model.Entry, error)
+	ResetEnrichment(ctx context.Context, userID, id uuid.UUID) error
+	ResetSummary(ctx context.Context, userID, id uuid.UUID, bypassCache bool) error
 }
@@ -400,6 +400,7 @@ type mockEntryRepo struct {
 	getByIDFn                         func(ctx context.Context, id uuid.UUID) (*model.Entry, error)
 	createFn                          func(ctx context.Context, input *model.CreateEntryInput) (*model.Entry, error)
 	updateFn                          func(ctx context.Context, id uuid.UUID, input *model.UpdateEntryInput) (*model.Entry, error)
+	updateTagFn                       func(ctx context.Context, id uuid.UUID, tag *string) (bool, error)
 	deleteFn                          func(ctx context.Context, id uuid.UUID) error
 	listFn                            func(ctx context.Context, opts repository.ListOptions) ([]model.Entry, error)
 	countFn                           func(ctx context.Context) (int, error)
@@ -408,13 +409,13 @@ type mockEntryRepo struct {
 	getDuplicateCountsByNormalizedURL func(ctx context.Context, normalizedURLs []string) (map[string]int, error)
 	listByNormalizedURLFn             func(ctx context.Context, normalizedURL string) ([]model.Entry, error)
 	resetEnrichmentFn                 func(ctx context.Context, id uuid.UUID) error
-	resetSummaryFn                    func(ctx context.Context, id uuid.UUID) error
+	resetSummaryFn                    func(ctx context.Context, id uuid.UUID, bypassCache bool) error
 
 	// Track calls for assertions
 	updateCalledWith *model.UpdateEntryInput
 }
 
-func (m *mockEntryRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Entry, error) {
+func (m *mockEntryRepo) GetByID(ctx context.Context, userID, id uuid.UUID) (*model.Entry, error) {
 	if m.getByIDFn != nil {
 		return m.getByIDFn(ctx, id)
 	}
@@ -428,7 +429,7 @@ func (m *mockEntryRepo) Create(ctx context.Context, input *model.CreateEntryInpu
 	return nil, nil
 }
 
-func (m *mockEntryRepo) Update(ctx context.Context, id uuid.UUID, input *model.UpdateEntryInput) (*model.Entry, error) {
+func (m *mockEntryRepo) Update(ctx context.Context, userID, id uuid.UUID, input *model.UpdateEntryInput) (*model.Entry, error) {
 	m.updateCalledWith = input
 	if m.updateFn != nil {
 		return m.updateFn(ctx, id, input)
@@ -436,65 +437,72 @@ func (m *mockEntryRepo) Update(ctx context.Context, id uuid.UUID, input *model.U
 	return nil, nil
 }
 
-func (m *mockEntryRepo) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *mockEntryRepo) UpdateTag(ctx context.Context, userID, id uuid.UUID, tag *string) (bool, error) {
+	if m.updateTagFn != nil {
+		return m.updateTagFn(ctx, id, tag)
+	}
+	return true, nil
+}
+
+func (m *mockEntryRepo) Delete(ctx context.Context, userID, id uuid.UUID) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockEntryRepo) List(ctx context.Context, opts repository.ListOptions) ([]model.Entry, error) {
+func (m *mockEntryRepo) List(ctx context.Context, userID uuid.UUID, opts repository.ListOptions) ([]model.Entry, error) {
 	if m.listFn != nil {
 		return m.listFn(ctx, opts)
 	}
 	return nil, nil
 }
 
-func (m *mockEntryRepo) Count(ctx context.Context) (int, error) {
+func (m *mockEntryRepo) Count(ctx context.Context, userID uuid.UUID) (int, error) {
 	if m.countFn != nil {
 		return m.countFn(ctx)
 	}
 	return 0, nil
 }
 
-func (m *mockEntryRepo) GetLatestByNormalizedURL(ctx context.Context, normalizedURL string) (*repository.DuplicateEntry, error) {
+func (m *mockEntryRepo) GetLatestByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (*repository.DuplicateEntry, error) {
 	if m.getLatestByNormalizedURLFn != nil {
 		return m.getLatestByNormalizedURLFn(ctx, normalizedURL)
 	}
 	return nil, nil
 }
 
-func (m *mockEntryRepo) CountByNormalizedURL(ctx context.Context, normalizedURL string) (int, error) {
+func (m *mockEntryRepo) CountByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (int, error) {
 	if m.countByNormalizedURLFn != nil {
 		return m.countByNormalizedURLFn(ctx, normalizedURL)
 	}
 	return 1, nil
 }
 
-func (m *mockEntryRepo) GetDuplicateCountsByNormalizedURL(ctx context.Context, normalizedURLs []string) (map[string]int, error) {
+func (m *mockEntryRepo) GetDuplicateCountsByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURLs []string) (map[string]int, error) {
 	if m.getDuplicateCountsByNormalizedURL != nil {
 		return m.getDuplicateCountsByNormalizedURL(ctx, normalizedURLs)
 	}
 	return nil, nil
 }
 
-func (m *mockEntryRepo) ListByNormalizedURL(ctx context.Context, normalizedURL string) ([]model.Entry, error) {
+func (m *mockEntryRepo) ListByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) ([]model.Entry, error) {
 	if m.listByNormalizedURLFn != nil {
 		return m.listByNormalizedURLFn(ctx, normalizedURL)
 	}
 	return nil, nil
 }
 
-func (m *mockEntryRepo) ResetEnrichment(ctx context.Context, id uuid.UUID) error {
+func (m *mockEntryRepo) ResetEnrichment(ctx context.Context, userID, id uuid.UUID) error {
 	if m.resetEnrichmentFn != nil {
 		return m.resetEnrichmentFn(ctx, id)
 	}
 	return nil
 }
 
-func (m *mockEntryRepo) ResetSummary(ctx context.Context, id uuid.UUID) error {
+func (m *mockEntryRepo) ResetSummary(ctx context.Context, userID, id uuid.UUID, bypassCache bool) error {
 	if m.resetSummaryFn != nil {
-		return m.resetSummaryFn(ctx, id)
+		return m.resetSummaryFn(ctx, id, bypassCache)
 	}
 	return nil
 }
@@ -526,6 +534,8 @@ func setupTestHandler(repo EntryRepo) *chi.Mux {
 	r := chi.NewRouter()
 	r.Get("/entries/{id}/edit", handler.EditPage)
 	r.Put("/entries/{id}", handler.Update)
+	r.Post("/entries/{id}/refresh-summary", handler.RefreshSummary)
+	r.Post("/entries/{id}/tag", handler.AcceptTag)
 	return r
 }
 
@@ -821,3 +831,89 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshSummaryBypassCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantBypass bool
+	}{
+		{name: "default uses cache", body: "", wantBypass: false},
+		{name: "bypass_cache=1 skips cache", body: "bypass_cache=1", wantBypass: true},
+		{name: "other values use cache", body: "bypass_cache=yes", wantBypass: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+			var gotBypass *bool
+			mock := &mockEntryRepo{
+				resetSummaryFn: func(ctx context.Context, reqID uuid.UUID, bypassCache bool) error {
+					gotBypass = &bypassCache
+					return nil
+				},
+				getByIDFn: func(ctx context.Context, reqID uuid.UUID) (*model.Entry, error) {
+					return createTestEntry(reqID), nil
+				},
+			}
+
+			router := setupTestHandler(mock)
+			req := httptest.NewRequest(http.MethodPost, "/entries/"+id.String()+"/refresh-summary", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("RefreshSummary() status = %d, want %d", rec.Code, http.StatusOK)
+			}
+			if gotBypass == nil {
+				t.Fatal("RefreshSummary() did not reset the summary")
+			}
+			if *gotBypass != tt.wantBypass {
+				t.Errorf("RefreshSummary() bypassCache = %v, want %v", *gotBypass, tt.wantBypass)
+			}
+		})
+	}
+}
+
+func TestAcceptTag(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		found          bool
+		expectedStatus int
+		expectedTag    string
+	}{
+		{name: "valid tag", body: "tag=Databases", found: true, expectedStatus: http.StatusNoContent, expectedTag: "databases"},
+		{name: "invalid tag", body: "tag=not+valid", found: true, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "empty tag", body: "tag=", found: true, expectedStatus: http.StatusUnprocessableEntity},
+		{name: "missing entry", body: "tag=go", found: false, expectedStatus: http.StatusNotFound, expectedTag: "go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTag string
+			mock := &mockEntryRepo{
+				updateTagFn: func(ctx context.Context, id uuid.UUID, tag *string) (bool, error) {
+					gotTag = *tag
+					return tt.found, nil
+				},
+			}
+
+			router := setupTestHandler(mock)
+			req := httptest.NewRequest(http.MethodPost, "/entries/550e8400-e29b-41d4-a716-446655440000/tag", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("AcceptTag() status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+			if gotTag != tt.expectedTag {
+				t.Errorf("AcceptTag() saved tag = %q, want %q", gotTag, tt.expectedTag)
+			}
+		})
+	}
+}
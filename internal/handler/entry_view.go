@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 
+	"github.com/drywaters/learnd/internal/auth"
 	"github.com/drywaters/learnd/internal/model"
 	"github.com/drywaters/learnd/internal/ui"
 )
@@ -22,7 +23,7 @@ func getDuplicateCount(ctx context.Context, repo EntryRepo, entry *model.Entry)
 		normalizedURL = entry.SourceURL
 	}
 	if normalizedURL != "" {
-		if count, err := repo.CountByNormalizedURL(ctx, normalizedURL); err == nil && count > 0 {
+		if count, err := repo.CountByNormalizedURL(ctx, auth.UserID(ctx), normalizedURL); err == nil && count > 0 {
 			return count
 		}
 	}
@@ -48,7 +49,7 @@ func buildEntryViews(ctx context.Context, repo EntryRepo, entries []model.Entry)
 
 	counts := map[string]int{}
 	if len(normalizedURLs) > 0 {
-		if fetched, err := repo.GetDuplicateCountsByNormalizedURL(ctx, normalizedURLs); err == nil {
+		if fetched, err := repo.GetDuplicateCountsByNormalizedURL(ctx, auth.UserID(ctx), normalizedURLs); err == nil {
 			counts = fetched
 		}
 	}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/repository"
+	"github.com/google/uuid"
+)
+
+// exportPageSize is how many entries exports fetch per query
+const exportPageSize = 1000
+
+// ankiRootDeck is the parent deck; tagged entries go into a subdeck per tag
+const ankiRootDeck = "learnd"
+
+// ExportHandler handles exports to other tools
+type ExportHandler struct {
+	entryRepo  *repository.EntryRepository
+	reviewRepo *repository.ReviewRepository
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(entryRepo *repository.EntryRepository, reviewRepo *repository.ReviewRepository) *ExportHandler {
+	return &ExportHandler{
+		entryRepo:  entryRepo,
+		reviewRepo: reviewRepo,
+	}
+}
+
+// ExportAnki exports entries and their generated question cards as an Anki-importable
+// tab-separated file. Notes carry a stable GUID so re-importing updates them in place.
+func (h *ExportHandler) ExportAnki(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	start, end, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := repository.ListOptions{
+		Limit:  exportPageSize,
+		Offset: 0,
+		Start:  &start,
+		End:    &end,
+	}
+
+	// Fetch first page before writing headers to allow clean error response
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), opts)
+	if err != nil {
+		slog.Error("failed to list entries", "handler", "ExportAnki", "offset", opts.Offset, "error", err)
+		http.Error(w, "Failed to get entries", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/tab-separated-values; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=learnd-anki-%s.txt", time.Now().Format("2006-01-02")))
+
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	writeAnkiHeader(out)
+
+	for {
+		ids := make([]uuid.UUID, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+		cards, err := h.reviewRepo.ListCardsByEntries(ctx, auth.UserID(ctx), ids, model.CardKindQA)
+		if err != nil {
+			// Headers already sent, can only log and stop
+			slog.Error("failed to list review cards", "handler", "ExportAnki", "offset", opts.Offset, "error", err)
+			return
+		}
+
+		for _, entry := range entries {
+			for _, note := range ankiNotes(entry, cards[entry.ID]) {
+				fmt.Fprintln(out, strings.Join(note, "\t"))
+			}
+		}
+
+		if len(entries) < exportPageSize {
+			break
+		}
+
+		opts.Offset += len(entries)
+		entries, err = h.entryRepo.List(ctx, auth.UserID(ctx), opts)
+		if err != nil {
+			slog.Error("failed to list entries", "handler", "ExportAnki", "offset", opts.Offset, "error", err)
+			return
+		}
+	}
+}
+
+// writeAnkiHeader writes the file headers Anki uses to map columns on import
+func writeAnkiHeader(w io.Writer) {
+	fmt.Fprint(w, "#separator:tab\n#html:true\n#notetype:Basic\n#guid column:1\n#deck column:4\n#tags column:5\n")
+}
+
+// ankiNotes returns the rows for an entry: GUID, front, back, deck and tags.
+// The entry itself becomes one note, followed by its question cards.
+func ankiNotes(entry model.Entry, cards []model.ReviewCard) [][]string {
+	title := entry.SourceURL
+	if entry.Title != nil && strings.TrimSpace(*entry.Title) != "" {
+		title = strings.TrimSpace(*entry.Title)
+	}
+
+	link := fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(entry.SourceURL), ankiField(title))
+
+	var back []string
+	if entry.SummaryText != nil && *entry.SummaryText != "" {
+		back = append(back, ankiField(*entry.SummaryText))
+	} else if entry.Description != nil && *entry.Description != "" {
+		back = append(back, ankiField(*entry.Description))
+	}
+	back = append(back, link)
+	if entry.Notes != nil && *entry.Notes != "" {
+		back = append(back, "<i>Notes:</i> "+ankiField(*entry.Notes))
+	}
+
+	deck := ankiDeck(entry)
+	tags := ankiTags(entry)
+
+	notes := [][]string{{
+		"learnd-" + entry.ID.String(),
+		ankiField(title),
+		strings.Join(back, "<br><br>"),
+		deck,
+		tags,
+	}}
+	for _, card := range cards {
+		notes = append(notes, []string{
+			"learnd-" + card.ID.String(),
+			ankiField(card.Front),
+			ankiField(card.Back) + "<br><br>" + link,
+			deck,
+			tags,
+		})
+	}
+	return notes
+}
+
+// ankiField escapes text for an HTML field and keeps it on a single TSV line
+func ankiField(s string) string {
+	s = html.EscapeString(strings.TrimSpace(s))
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = strings.ReplaceAll(s, "\n", "<br>")
+	return strings.ReplaceAll(s, "\t", " ")
+}
+
+func ankiDeck(entry model.Entry) string {
+	if entry.Tag == nil || *entry.Tag == "" {
+		return ankiRootDeck
+	}
+	return ankiRootDeck + "::" + *entry.Tag
+}
+
+func ankiTags(entry model.Entry) string {
+	tags := []string{ankiRootDeck, string(entry.SourceType)}
+	if entry.Tag != nil && *entry.Tag != "" {
+		tags = append(tags, *entry.Tag)
+	}
+	return strings.Join(tags, " ")
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/repository"
+	"github.com/drywaters/learnd/internal/urlutil"
+	"github.com/google/uuid"
+)
+
+// maxImportBytes limits the size of an uploaded backup
+const maxImportBytes = 64 << 20
+
+// Export streams every entry field as NDJSON (default) or a JSON array for backups.
+// Without start or end parameters the whole journal is exported. If it fails partway
+// the response is aborted, so a truncated backup fails to download rather than
+// looking complete.
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "ndjson"
+	}
+	if format != "ndjson" && format != "json" {
+		http.Error(w, "Invalid format: use ndjson or json", http.StatusBadRequest)
+		return
+	}
+
+	opts := repository.ListOptions{
+		Limit:  exportPageSize,
+		Offset: 0,
+	}
+	if r.URL.Query().Get("start") != "" || r.URL.Query().Get("end") != "" {
+		start, end, err := parseDateRange(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.Start = &start
+		opts.End = &end
+	}
+
+	// Fetch first page before writing headers to allow clean error response
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), opts)
+	if err != nil {
+		slog.Error("failed to list entries", "handler", "Export", "offset", opts.Offset, "error", err)
+		http.Error(w, "Failed to get entries", http.StatusInternalServerError)
+		return
+	}
+
+	contentType := "application/x-ndjson"
+	if format == "json" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=learnd-backup-%s.%s", time.Now().Format("2006-01-02"), format))
+
+	out := bufio.NewWriter(w)
+	defer out.Flush()
+
+	// json.Encoder ends each value with a newline, which is also the NDJSON separator
+	enc := json.NewEncoder(out)
+	first := true
+	if format == "json" {
+		out.WriteString("[\n")
+	}
+
+	for {
+		for i := range entries {
+			if format == "json" && !first {
+				out.WriteString(",")
+			}
+			first = false
+			if err := enc.Encode(&entries[i]); err != nil {
+				// Headers already sent, can only log and abort
+				slog.Error("failed to encode entry", "handler", "Export", "id", entries[i].ID, "error", err)
+				panic(http.ErrAbortHandler)
+			}
+		}
+
+		if len(entries) < exportPageSize {
+			break
+		}
+
+		opts.Offset += len(entries)
+		entries, err = h.entryRepo.List(ctx, auth.UserID(ctx), opts)
+		if err != nil {
+			slog.Error("failed to list entries", "handler", "Export", "offset", opts.Offset, "error", err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+
+	if format == "json" {
+		out.WriteString("]\n")
+	}
+}
+
+// Import restores entries from an NDJSON or JSON array backup. Entries are matched by
+// ID, so importing the same backup twice changes nothing. With dry_run=true the
+// response reports what would change without saving anything.
+func (h *ExportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	dryRun := r.URL.Query().Get("dry_run") == "true" || r.URL.Query().Get("dry_run") == "1"
+
+	entries, err := decodeImportEntries(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(entries) == 0 {
+		http.Error(w, "No entries to import", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.entryRepo.ImportEntries(ctx, auth.UserID(ctx), entries, dryRun)
+	if errors.Is(err, repository.ErrEntryOwnedByOtherUser) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to import entries", "handler", "Import", "count", len(entries), "dry_run", dryRun, "error", err)
+		http.Error(w, "Failed to import entries", http.StatusInternalServerError)
+		return
+	}
+
+	slog.Info("imported entries", "dry_run", dryRun, "created", result.Created, "updated", result.Updated, "unchanged", result.Unchanged)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.Error("failed to encode import result", "handler", "Import", "error", err)
+	}
+}
+
+// decodeImportEntries reads a JSON array or newline-delimited JSON entries and validates them
+func decodeImportEntries(body io.Reader) ([]model.Entry, error) {
+	reader := bufio.NewReader(body)
+	dec := json.NewDecoder(reader)
+
+	// Peek past whitespace to tell a JSON array from NDJSON
+	isArray := false
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			reader.ReadByte()
+			continue
+		}
+		isArray = b[0] == '['
+		break
+	}
+
+	var entries []model.Entry
+	if isArray {
+		if err := dec.Decode(&entries); err != nil {
+			return nil, fmt.Errorf("invalid JSON backup: %w", err)
+		}
+	} else {
+		for {
+			var entry model.Entry
+			err := dec.Decode(&entry)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid NDJSON backup at entry %d: %w", len(entries)+1, err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	seen := make(map[uuid.UUID]bool, len(entries))
+	for i := range entries {
+		if err := prepareImportEntry(&entries[i]); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		if seen[entries[i].ID] {
+			return nil, fmt.Errorf("entry %d: duplicate id %s", i+1, entries[i].ID)
+		}
+		seen[entries[i].ID] = true
+	}
+	return entries, nil
+}
+
+// prepareImportEntry validates an imported entry and fills in defaults for fields
+// that older exports or hand-written files may omit
+func prepareImportEntry(entry *model.Entry) error {
+	if entry.ID == uuid.Nil {
+		return fmt.Errorf("missing id")
+	}
+	if entry.SourceURL == "" {
+		return fmt.Errorf("missing source_url")
+	}
+	if entry.CreatedAt.IsZero() {
+		return fmt.Errorf("missing created_at")
+	}
+	if entry.UpdatedAt.IsZero() {
+		entry.UpdatedAt = entry.CreatedAt
+	}
+	if entry.NormalizedURL == "" {
+		normalized, err := urlutil.NormalizeURL(entry.SourceURL)
+		if err != nil {
+			return fmt.Errorf("invalid source_url: %w", err)
+		}
+		entry.NormalizedURL = normalized
+	}
+
+	if entry.SourceType == "" {
+		entry.SourceType = model.SourceTypeOther
+	} else if parseSourceType(string(entry.SourceType)) == nil {
+		return fmt.Errorf("invalid source_type %q", entry.SourceType)
+	}
+
+	// A backup taken while the worker was busy has entries in processing, which the
+	// worker never picks up again, so they're queued to run afresh
+	for _, status := range []*model.ProcessingStatus{&entry.EnrichmentStatus, &entry.SummaryStatus} {
+		switch *status {
+		case "", model.StatusProcessing:
+			*status = model.StatusPending
+		case model.StatusPending, model.StatusOK, model.StatusFailed, model.StatusSkipped:
+		default:
+			return fmt.Errorf("invalid status %q", *status)
+		}
+	}
+
+	switch entry.TagStatus {
+	case "":
+		entry.TagStatus = model.TagStatusConfirmed
+	case model.TagStatusConfirmed, model.TagStatusSuggested:
+	default:
+		return fmt.Errorf("invalid tag_status %q", entry.TagStatus)
+	}
+
+	if entry.Tag != nil {
+		tag, err := parseTag(*entry.Tag)
+		if err != nil {
+			return err
+		}
+		entry.Tag = tag
+	}
+
+	return nil
+}
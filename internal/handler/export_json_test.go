@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestDecodeImportEntries(t *testing.T) {
+	id1 := "550e8400-e29b-41d4-a716-446655440000"
+	id2 := "550e8400-e29b-41d4-a716-446655440001"
+	line1 := `{"id":"` + id1 + `","created_at":"2026-03-01T09:00:00Z","source_url":"https://Example.com/a","source_type":"article","tag":"Go"}`
+	line2 := `{"id":"` + id2 + `","created_at":"2026-03-02T09:00:00Z","updated_at":"2026-03-03T09:00:00Z","source_url":"https://example.com/b","normalized_url":"example.com/b","enrichment_status":"ok","summary_status":"skipped","tag_status":"suggested"}`
+
+	tests := []struct {
+		name    string
+		body    string
+		wantIDs []string
+		wantErr string
+	}{
+		{name: "ndjson", body: line1 + "\n" + line2 + "\n", wantIDs: []string{id1, id2}},
+		{name: "json array", body: "  \n[" + line1 + ",\n" + line2 + "]", wantIDs: []string{id1, id2}},
+		{name: "empty body", body: "  \n"},
+		{name: "malformed line", body: line1 + "\n{not json}\n", wantErr: "entry 2"},
+		{name: "missing id", body: `{"created_at":"2026-03-01T09:00:00Z","source_url":"https://example.com"}`, wantErr: "missing id"},
+		{name: "missing created_at", body: `{"id":"` + id1 + `","source_url":"https://example.com"}`, wantErr: "missing created_at"},
+		{name: "invalid status", body: `{"id":"` + id1 + `","created_at":"2026-03-01T09:00:00Z","source_url":"https://example.com","summary_status":"done"}`, wantErr: "invalid status"},
+		{name: "invalid source type", body: `{"id":"` + id1 + `","created_at":"2026-03-01T09:00:00Z","source_url":"https://example.com","source_type":"book"}`, wantErr: "invalid source_type"},
+		{name: "invalid tag", body: `{"id":"` + id1 + `","created_at":"2026-03-01T09:00:00Z","source_url":"https://example.com","tag":"two words"}`, wantErr: "Invalid tag"},
+		{name: "duplicate id", body: line1 + "\n" + line1 + "\n", wantErr: "duplicate id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := decodeImportEntries(strings.NewReader(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("decodeImportEntries() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeImportEntries() error = %v", err)
+			}
+			var ids []string
+			for _, e := range entries {
+				ids = append(ids, e.ID.String())
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("decodeImportEntries() ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestPrepareImportEntryDefaults(t *testing.T) {
+	entries, err := decodeImportEntries(strings.NewReader(`{"id":"550e8400-e29b-41d4-a716-446655440000","created_at":"2026-03-01T09:00:00Z","source_url":"https://Example.com/a/","tag":" Go "}`))
+	if err != nil {
+		t.Fatalf("decodeImportEntries() error = %v", err)
+	}
+	e := entries[0]
+
+	if !e.UpdatedAt.Equal(e.CreatedAt) {
+		t.Errorf("UpdatedAt = %v, want created_at", e.UpdatedAt)
+	}
+	if e.NormalizedURL == "" {
+		t.Error("NormalizedURL not filled in")
+	}
+	if e.SourceType != model.SourceTypeOther || e.EnrichmentStatus != model.StatusPending ||
+		e.SummaryStatus != model.StatusPending || e.TagStatus != model.TagStatusConfirmed {
+		t.Errorf("defaults = %q %q %q %q", e.SourceType, e.EnrichmentStatus, e.SummaryStatus, e.TagStatus)
+	}
+	if e.Tag == nil || *e.Tag != "go" {
+		t.Errorf("Tag = %v, want go", e.Tag)
+	}
+}
+
+func TestPrepareImportEntryRequeuesProcessing(t *testing.T) {
+	entries, err := decodeImportEntries(strings.NewReader(`{"id":"550e8400-e29b-41d4-a716-446655440000","created_at":"2026-03-01T09:00:00Z","source_url":"https://example.com/a","enrichment_status":"processing","summary_status":"processing"}`))
+	if err != nil {
+		t.Fatalf("decodeImportEntries() error = %v", err)
+	}
+	e := entries[0]
+
+	// The worker only picks up pending entries, so a job caught mid-run is queued again
+	if e.EnrichmentStatus != model.StatusPending || e.SummaryStatus != model.StatusPending {
+		t.Errorf("statuses = %q %q, want pending", e.EnrichmentStatus, e.SummaryStatus)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	title := "Go generics"
+	published := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	source := "rules"
+	original := model.Entry{
+		ID:               uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+		CreatedAt:        time.Date(2026, 3, 1, 9, 0, 0, 123000, time.UTC),
+		UpdatedAt:        time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC),
+		SourceURL:        "https://go.dev/blog/intro-generics",
+		NormalizedURL:    "go.dev/blog/intro-generics",
+		SourceType:       model.SourceTypeArticle,
+		Title:            &title,
+		PublishedAt:      &published,
+		MetadataJSON:     []byte(`{"author":"gopher"}`),
+		EnrichmentStatus: model.StatusOK,
+		SummaryStatus:    model.StatusFailed,
+		SummaryInsights:  &model.SummaryInsights{Takeaways: []string{"a", "b", "c"}, Difficulty: model.DifficultyBeginner},
+		TagStatus:        model.TagStatusSuggested,
+		TagSource:        &source,
+	}
+
+	data, err := json.Marshal(&original)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	entries, err := decodeImportEntries(strings.NewReader(string(data) + "\n"))
+	if err != nil {
+		t.Fatalf("decodeImportEntries() error = %v", err)
+	}
+	if !reflect.DeepEqual(entries[0], original) {
+		t.Errorf("round trip = %+v, want %+v", entries[0], original)
+	}
+}
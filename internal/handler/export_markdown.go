@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/repository"
+)
+
+// ExportMarkdown exports entries as a zip of Markdown notes for an Obsidian vault:
+// one note per entry plus daily and weekly index notes. File names only depend on
+// the entry's creation date and ID, so re-exporting overwrites the same files. Index
+// notes are only written for days and weeks the date range covers completely, so a
+// partial export doesn't overwrite a complete index with a shorter one.
+func (h *ExportHandler) ExportMarkdown(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	start, end, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	opts := repository.ListOptions{
+		Limit:  exportPageSize,
+		Offset: 0,
+		Start:  &start,
+		End:    &end,
+	}
+
+	// Fetch first page before writing headers to allow clean error response
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), opts)
+	if err != nil {
+		slog.Error("failed to list entries", "handler", "ExportMarkdown", "offset", opts.Offset, "error", err)
+		http.Error(w, "Failed to get entries", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=learnd-markdown-%s.zip", time.Now().Format("2006-01-02")))
+
+	zw := zip.NewWriter(w)
+	defer zw.Close()
+
+	index := newMarkdownIndex(start, end)
+	for {
+		for _, entry := range entries {
+			path := markdownEntryPath(entry)
+			if err := writeZipFile(zw, path, entry.UpdatedAt, markdownEntry(entry)); err != nil {
+				// Headers already sent, can only log and stop
+				slog.Error("failed to write markdown note", "handler", "ExportMarkdown", "id", entry.ID, "error", err)
+				return
+			}
+			index.add(entry, path)
+		}
+
+		if len(entries) < exportPageSize {
+			break
+		}
+
+		opts.Offset += len(entries)
+		entries, err = h.entryRepo.List(ctx, auth.UserID(ctx), opts)
+		if err != nil {
+			slog.Error("failed to list entries", "handler", "ExportMarkdown", "offset", opts.Offset, "error", err)
+			return
+		}
+	}
+
+	indexNotes := index.notes()
+	paths := make([]string, 0, len(indexNotes))
+	for path := range indexNotes {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if err := writeZipFile(zw, path, time.Now(), indexNotes[path]); err != nil {
+			slog.Error("failed to write markdown index", "handler", "ExportMarkdown", "path", path, "error", err)
+			return
+		}
+	}
+}
+
+func writeZipFile(zw *zip.Writer, path string, modified time.Time, content string) error {
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+// markdownEntryPath returns the note's path, e.g. "entries/2026-03-01-550e8400.md"
+func markdownEntryPath(entry model.Entry) string {
+	return fmt.Sprintf("entries/%s-%s.md", entry.CreatedAt.Format("2006-01-02"), entry.ID.String()[:8])
+}
+
+// markdownEntry renders an entry as a note with YAML front matter
+func markdownEntry(entry model.Entry) string {
+	title := markdownTitle(entry)
+
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	fmt.Fprintf(&sb, "id: %s\n", entry.ID)
+	fmt.Fprintf(&sb, "title: %s\n", strconv.Quote(title))
+	fmt.Fprintf(&sb, "aliases: [%s]\n", strconv.Quote(title))
+	fmt.Fprintf(&sb, "url: %s\n", strconv.Quote(entry.SourceURL))
+	if entry.Tag != nil && *entry.Tag != "" {
+		fmt.Fprintf(&sb, "tag: %s\n", *entry.Tag)
+		fmt.Fprintf(&sb, "tags: [%s]\n", *entry.Tag)
+	}
+	fmt.Fprintf(&sb, "type: %s\n", entry.SourceType)
+	fmt.Fprintf(&sb, "created: %s\n", entry.CreatedAt.Format(time.RFC3339))
+	if entry.RuntimeSeconds != nil {
+		fmt.Fprintf(&sb, "runtime: %d\n", *entry.RuntimeSeconds)
+	}
+	if entry.TimeSpentSeconds != nil {
+		fmt.Fprintf(&sb, "time_spent: %d\n", *entry.TimeSpentSeconds)
+	}
+	fmt.Fprintf(&sb, "summary_status: %s\n", entry.SummaryStatus)
+	sb.WriteString("---\n\n")
+
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "<%s>\n", entry.SourceURL)
+
+	if entry.SummaryText != nil && *entry.SummaryText != "" {
+		fmt.Fprintf(&sb, "\n## Summary\n\n%s\n", strings.TrimSpace(*entry.SummaryText))
+	}
+	if entry.SummaryInsights != nil && len(entry.SummaryInsights.Takeaways) > 0 {
+		sb.WriteString("\n## Key Takeaways\n\n")
+		for _, takeaway := range entry.SummaryInsights.Takeaways {
+			fmt.Fprintf(&sb, "- %s\n", takeaway)
+		}
+	}
+	if entry.Notes != nil && *entry.Notes != "" {
+		fmt.Fprintf(&sb, "\n## Notes\n\n%s\n", strings.TrimSpace(*entry.Notes))
+	}
+
+	return sb.String()
+}
+
+func markdownTitle(entry model.Entry) string {
+	if entry.Title != nil && strings.TrimSpace(*entry.Title) != "" {
+		return strings.TrimSpace(*entry.Title)
+	}
+	return entry.SourceURL
+}
+
+// markdownIndex collects wiki links to exported notes by day and ISO week, for the
+// days and weeks that lie wholly within the export's date range
+type markdownIndex struct {
+	start, end time.Time
+	daily      map[string][]string
+	weekly     map[string][]string
+}
+
+func newMarkdownIndex(start, end time.Time) *markdownIndex {
+	return &markdownIndex{
+		start:  start,
+		end:    end,
+		daily:  make(map[string][]string),
+		weekly: make(map[string][]string),
+	}
+}
+
+func (idx *markdownIndex) add(entry model.Entry, path string) {
+	// Characters that would end an Obsidian wiki link early
+	label := strings.NewReplacer("|", "-", "[", "(", "]", ")").Replace(markdownTitle(entry))
+	link := fmt.Sprintf("- [[%s|%s]]", strings.TrimSuffix(path, ".md"), label)
+
+	created := entry.CreatedAt
+	day := time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, created.Location())
+	if idx.covers(day, day.AddDate(0, 0, 1)) {
+		key := day.Format("2006-01-02")
+		idx.daily[key] = append(idx.daily[key], link)
+	}
+
+	monday := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	if idx.covers(monday, monday.AddDate(0, 0, 7)) {
+		year, week := created.ISOWeek()
+		key := fmt.Sprintf("%d-W%02d", year, week)
+		idx.weekly[key] = append(idx.weekly[key], link)
+	}
+}
+
+// covers reports whether the period from start up to next is inside the date range,
+// whose end is the last second of its final day
+func (idx *markdownIndex) covers(start, next time.Time) bool {
+	return !start.Before(idx.start) && !next.Add(-time.Second).After(idx.end)
+}
+
+// notes renders the index notes keyed by path
+func (idx *markdownIndex) notes() map[string]string {
+	notes := make(map[string]string, len(idx.daily)+len(idx.weekly))
+	for day, links := range idx.daily {
+		notes["index/daily/"+day+".md"] = markdownIndexNote(day, links)
+	}
+	for week, links := range idx.weekly {
+		notes["index/weekly/"+week+".md"] = markdownIndexNote(week, links)
+	}
+	return notes
+}
+
+func markdownIndexNote(title string, links []string) string {
+	sorted := append([]string(nil), links...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("# %s\n\n%s\n", title, strings.Join(sorted, "\n"))
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestAnkiField(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "plain", want: "plain"},
+		{input: "  a <b> & \"c\"  ", want: "a &lt;b&gt; &amp; &#34;c&#34;"},
+		{input: "line one\r\nline two\nthree", want: "line one<br>line two<br>three"},
+		{input: "tab\tseparated", want: "tab separated"},
+	}
+
+	for _, tt := range tests {
+		if got := ankiField(tt.input); got != tt.want {
+			t.Errorf("ankiField(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAnkiNotes(t *testing.T) {
+	entryID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	cardID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+	title := "Go generics"
+	summary := "How type parameters work."
+	notes := "Try at work"
+	tag := "go"
+
+	t.Run("tagged entry with question card", func(t *testing.T) {
+		entry := model.Entry{
+			ID:          entryID,
+			SourceURL:   "https://go.dev/blog/intro-generics",
+			SourceType:  model.SourceTypeArticle,
+			Title:       &title,
+			SummaryText: &summary,
+			Notes:       &notes,
+			Tag:         &tag,
+		}
+		cards := []model.ReviewCard{{ID: cardID, Front: "What is a type parameter?", Back: "A placeholder type."}}
+
+		got := ankiNotes(entry, cards)
+		link := `<a href="https://go.dev/blog/intro-generics">Go generics</a>`
+		want := [][]string{
+			{
+				"learnd-" + entryID.String(),
+				"Go generics",
+				"How type parameters work.<br><br>" + link + "<br><br><i>Notes:</i> Try at work",
+				"learnd::go",
+				"learnd article go",
+			},
+			{
+				"learnd-" + cardID.String(),
+				"What is a type parameter?",
+				"A placeholder type.<br><br>" + link,
+				"learnd::go",
+				"learnd article go",
+			},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ankiNotes() = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("untagged entry without title or summary", func(t *testing.T) {
+		entry := model.Entry{ID: entryID, SourceURL: "https://example.com/?a=1&b=2", SourceType: model.SourceTypeOther}
+
+		got := ankiNotes(entry, nil)
+		if len(got) != 1 {
+			t.Fatalf("ankiNotes() returned %d notes, want 1", len(got))
+		}
+		note := got[0]
+		if note[1] != "https://example.com/?a=1&amp;b=2" {
+			t.Errorf("front = %q", note[1])
+		}
+		if note[3] != "learnd" || note[4] != "learnd other" {
+			t.Errorf("deck/tags = %q / %q", note[3], note[4])
+		}
+		for _, field := range note {
+			if strings.ContainsAny(field, "\t\n") {
+				t.Errorf("field %q contains a tab or newline", field)
+			}
+		}
+	})
+}
+
+func TestMarkdownEntry(t *testing.T) {
+	id := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	title := `Go "generics"`
+	summary := "How type parameters work."
+	notes := "Try at work"
+	tag := "go"
+	runtime := 600
+	entry := model.Entry{
+		ID:             id,
+		CreatedAt:      time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		SourceURL:      "https://go.dev/blog/intro-generics",
+		SourceType:     model.SourceTypeArticle,
+		Title:          &title,
+		Tag:            &tag,
+		RuntimeSeconds: &runtime,
+		SummaryText:    &summary,
+		SummaryStatus:  model.StatusOK,
+		Notes:          &notes,
+		SummaryInsights: &model.SummaryInsights{
+			Takeaways: []string{"Use constraints", "Prefer any"},
+		},
+	}
+
+	if got := markdownEntryPath(entry); got != "entries/2026-03-01-550e8400.md" {
+		t.Errorf("markdownEntryPath() = %q", got)
+	}
+
+	want := `---
+id: 550e8400-e29b-41d4-a716-446655440000
+title: "Go \"generics\""
+aliases: ["Go \"generics\""]
+url: "https://go.dev/blog/intro-generics"
+tag: go
+tags: [go]
+type: article
+created: 2026-03-01T09:30:00Z
+runtime: 600
+summary_status: ok
+---
+
+# Go "generics"
+
+<https://go.dev/blog/intro-generics>
+
+## Summary
+
+How type parameters work.
+
+## Key Takeaways
+
+- Use constraints
+- Prefer any
+
+## Notes
+
+Try at work
+`
+	if got := markdownEntry(entry); got != want {
+		t.Errorf("markdownEntry() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarkdownIndex(t *testing.T) {
+	title := "A [[tricky]] | title"
+	monday := model.Entry{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), CreatedAt: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC), SourceURL: "https://a.example", Title: &title}
+	tuesday := model.Entry{ID: uuid.MustParse("660e8400-e29b-41d4-a716-446655440000"), CreatedAt: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC), SourceURL: "https://b.example"}
+
+	// The whole of ISO week 10, as parseDateRange returns it
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 3, 8, 23, 59, 59, 0, time.UTC)
+	idx := newMarkdownIndex(start, end)
+	idx.add(tuesday, markdownEntryPath(tuesday))
+	idx.add(monday, markdownEntryPath(monday))
+	notes := idx.notes()
+
+	if len(notes) != 3 {
+		t.Fatalf("notes() returned %d notes, want 3: %v", len(notes), notes)
+	}
+	wantDaily := "# 2026-03-02\n\n- [[entries/2026-03-02-550e8400|A ((tricky)) - title]]\n"
+	if got := notes["index/daily/2026-03-02.md"]; got != wantDaily {
+		t.Errorf("daily note = %q, want %q", got, wantDaily)
+	}
+	wantWeekly := "# 2026-W10\n\n- [[entries/2026-03-02-550e8400|A ((tricky)) - title]]\n- [[entries/2026-03-03-660e8400|https://b.example]]\n"
+	if got := notes["index/weekly/2026-W10.md"]; got != wantWeekly {
+		t.Errorf("weekly note = %q, want %q", got, wantWeekly)
+	}
+}
+
+func TestMarkdownIndexSkipsPartialPeriods(t *testing.T) {
+	entry := model.Entry{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"), CreatedAt: time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC), SourceURL: "https://a.example"}
+
+	// Tuesday to Thursday covers Tuesday but only part of its week
+	idx := newMarkdownIndex(time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 5, 23, 59, 59, 0, time.UTC))
+	idx.add(entry, markdownEntryPath(entry))
+	notes := idx.notes()
+	if _, ok := notes["index/daily/2026-03-03.md"]; !ok || len(notes) != 1 {
+		t.Errorf("notes() = %v, want only the daily note", notes)
+	}
+
+	// A range starting mid-day, like the default last 30 days, covers neither
+	idx = newMarkdownIndex(time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC))
+	idx.add(entry, markdownEntryPath(entry))
+	if notes := idx.notes(); len(notes) != 0 {
+		t.Errorf("notes() = %v, want none", notes)
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// htmxError renders an error message into the page's #form-error element
+func htmxError(w http.ResponseWriter, msg string) {
+	w.Header().Set("HX-Retarget", "#form-error")
+	w.Header().Set("HX-Reswap", "innerHTML")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	fmt.Fprintf(w, `<p class="text-sm" style="color: var(--color-error);">%s</p>`, html.EscapeString(msg))
+}
+
+// htmxToast triggers a toast notification on the client
+func htmxToast(w http.ResponseWriter, msg string, entryID *uuid.UUID, toastType string) {
+	showToast := map[string]string{
+		"message": msg,
+	}
+	if entryID != nil {
+		showToast["id"] = entryID.String()
+	}
+	if toastType != "" {
+		showToast["type"] = toastType
+	}
+
+	payload := map[string]map[string]string{
+		"showToast": showToast,
+	}
+	if data, err := json.Marshal(payload); err == nil {
+		w.Header().Set("HX-Trigger", string(data))
+	}
+}
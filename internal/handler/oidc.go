@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/oidc"
+	"golang.org/x/oauth2"
+)
+
+// oidcCookieName holds a single sign-on attempt's state between the redirect to the
+// identity provider and its callback
+const oidcCookieName = "learnd_oidc"
+
+// oidcTimeout is how long a user has to finish signing in at the identity provider
+const oidcTimeout = 10 * time.Minute
+
+// OIDCProvider runs single sign-on against an external identity provider
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error)
+	Exchange(ctx context.Context, code, verifier, nonce string) (*oidc.Identity, error)
+	CreatesUsers() bool
+}
+
+// oidcAttempt is what the callback needs to check that it answers this browser's
+// sign-in: the state sent to the provider, the ID token's nonce and the PKCE verifier
+type oidcAttempt struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Redirect string `json:"redirect,omitempty"`
+}
+
+// OIDCLogin sends the browser to the identity provider to sign in
+func (h *AuthHandler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if h.sso == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	attempt := oidcAttempt{
+		State:    rand.Text(),
+		Nonce:    rand.Text(),
+		Verifier: oauth2.GenerateVerifier(),
+	}
+	if redirectURL := r.URL.Query().Get("redirect"); isValidRedirect(redirectURL) {
+		attempt.Redirect = redirectURL
+	}
+
+	authURL, err := h.sso.AuthCodeURL(r.Context(), attempt.State, attempt.Nonce, attempt.Verifier)
+	if err != nil {
+		slog.Error("failed to start single sign-on", "error", err, "handler", "OIDCLogin")
+		http.Redirect(w, r, "/login?error=sso_failed", http.StatusSeeOther)
+		return
+	}
+
+	value, err := json.Marshal(attempt)
+	if err != nil {
+		slog.Error("failed to encode sign-on attempt", "error", err, "handler", "OIDCLogin")
+		http.Redirect(w, r, "/login?error=sso_failed", http.StatusSeeOther)
+		return
+	}
+	h.setOIDCCookie(w, base64.RawURLEncoding.EncodeToString(value), int(oidcTimeout.Seconds()))
+	http.Redirect(w, r, authURL, http.StatusSeeOther)
+}
+
+// OIDCCallback finishes single sign-on: it checks the provider's answer belongs to
+// this browser's attempt, validates the ID token and signs in the linked local user
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if h.sso == nil {
+		http.NotFound(w, r)
+		return
+	}
+	ctx := r.Context()
+
+	attempt, ok := h.oidcAttempt(r)
+	h.setOIDCCookie(w, "", -1)
+	if !ok || !constantTimeEqual(r.URL.Query().Get("state"), attempt.State) {
+		http.Redirect(w, r, "/login?error=sso_failed", http.StatusSeeOther)
+		return
+	}
+	if errCode := r.URL.Query().Get("error"); errCode != "" {
+		slog.Warn("identity provider refused sign-on", "error", errCode, "description", r.URL.Query().Get("error_description"))
+		http.Redirect(w, r, "/login?error=sso_failed", http.StatusSeeOther)
+		return
+	}
+
+	identity, err := h.sso.Exchange(ctx, r.URL.Query().Get("code"), attempt.Verifier, attempt.Nonce)
+	if errors.Is(err, oidc.ErrEmailNotVerified) || errors.Is(err, oidc.ErrDomainNotAllowed) {
+		slog.Warn("single sign-on denied", "error", err)
+		recordAuthEvent(r, h.events, model.AuthEvent{Kind: model.AuthEventLoginFailed, Method: model.AuthMethodSSO})
+		http.Redirect(w, r, "/login?error=sso_denied", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to finish single sign-on", "error", err, "handler", "OIDCCallback")
+		http.Redirect(w, r, "/login?error=sso_failed", http.StatusSeeOther)
+		return
+	}
+
+	user, err := h.ssoUser(ctx, identity)
+	if err != nil {
+		slog.Error("failed to map identity to user", "error", err, "handler", "OIDCCallback")
+		http.Redirect(w, r, "/login?error=sso_failed", http.StatusSeeOther)
+		return
+	}
+	if user == nil {
+		slog.Warn("single sign-on for unknown user", "email", identity.Email)
+		recordAuthEvent(r, h.events, model.AuthEvent{Kind: model.AuthEventLoginFailed, Method: model.AuthMethodSSO, Email: identity.Email})
+		http.Redirect(w, r, "/login?error=sso_denied", http.StatusSeeOther)
+		return
+	}
+
+	h.startSession(w, r, "OIDCCallback", user, model.AuthMethodSSO, attempt.Redirect)
+}
+
+// ssoUser returns the local user an identity signs in as. An identity signs in as
+// the user it was first linked to; a new identity is linked to the account with its
+// verified email, which is created if the provider allows it. The user is nil when
+// the identity has no account.
+func (h *AuthHandler) ssoUser(ctx context.Context, identity *oidc.Identity) (*model.User, error) {
+	user, err := h.users.GetByIdentity(ctx, identity.Issuer, identity.Subject)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	user, err = h.users.GetByEmail(ctx, identity.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if !h.sso.CreatesUsers() {
+			return nil, nil
+		}
+		if user, err = h.users.Create(ctx, identity.Email, nil, false); err != nil {
+			return nil, err
+		}
+		slog.Info("created user from single sign-on", "user_id", user.ID, "email", user.Email)
+	}
+
+	if err := h.users.LinkIdentity(ctx, user.ID, identity.Issuer, identity.Subject, identity.Email); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// oidcAttempt reads the sign-on attempt saved in the browser's cookie
+func (h *AuthHandler) oidcAttempt(r *http.Request) (oidcAttempt, bool) {
+	var attempt oidcAttempt
+
+	cookie, err := r.Cookie(oidcCookieName)
+	if err != nil {
+		return attempt, false
+	}
+	value, err := base64.RawURLEncoding.DecodeString(cookie.Value)
+	if err != nil {
+		return attempt, false
+	}
+	if err := json.Unmarshal(value, &attempt); err != nil || attempt.State == "" {
+		return attempt, false
+	}
+	return attempt, true
+}
+
+// setOIDCCookie stores or, with a negative maxAge, clears the sign-on attempt cookie.
+// It must be Lax, not Strict, so it's sent on the provider's redirect back.
+func (h *AuthHandler) setOIDCCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCookieName,
+		Value:    value,
+		Path:     "/login/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/oidc"
+	"github.com/drywaters/learnd/internal/oidc/oidctest"
+	"github.com/google/uuid"
+)
+
+// identityKey is an identity provider's issuer and subject
+type identityKey struct{ issuer, subject string }
+
+func (m *mockUserRepo) GetByIdentity(ctx context.Context, issuer, subject string) (*model.User, error) {
+	if id, ok := m.identities[identityKey{issuer, subject}]; ok {
+		return m.GetByID(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *mockUserRepo) LinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject, email string) error {
+	if m.identities == nil {
+		m.identities = make(map[identityKey]uuid.UUID)
+	}
+	m.identities[identityKey{issuer, subject}] = userID
+	return nil
+}
+
+func (m *mockUserRepo) Create(ctx context.Context, email string, passwordHash *string, isAdmin bool) (*model.User, error) {
+	user := &model.User{ID: uuid.New(), Email: email, PasswordHash: passwordHash, IsAdmin: isAdmin}
+	m.users = append(m.users, user)
+	return user, nil
+}
+
+// oidcSignIn runs single sign-on against the mock provider, returning the callback's response
+func oidcSignIn(t *testing.T, h *AuthHandler, idp *oidctest.Server) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.OIDCLogin(rec, httptest.NewRequest(http.MethodGet, "/login/oidc?redirect=/reports", nil))
+	if rec.Code != http.StatusSeeOther || !strings.HasPrefix(rec.Header().Get("Location"), idp.URL+"/auth?") {
+		t.Fatalf("OIDCLogin status = %d, Location = %q", rec.Code, rec.Header().Get("Location"))
+	}
+	var attempt *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == oidcCookieName {
+			attempt = c
+		}
+	}
+	if attempt == nil {
+		t.Fatal("expected a sign-on attempt cookie")
+	}
+
+	callback := idp.Authorize(t, rec.Header().Get("Location"))
+	req := httptest.NewRequest(http.MethodGet, callback.String(), nil)
+	req.AddCookie(attempt)
+	rec = httptest.NewRecorder()
+	h.OIDCCallback(rec, req)
+	return rec
+}
+
+func newOIDCHandler(t *testing.T, users *mockUserRepo, allowedDomains ...string) (*AuthHandler, *mockSessionRepo, *oidctest.Server) {
+	t.Helper()
+	idp := oidctest.NewServer(t, "learnd")
+	sso := oidc.New(oidc.Config{
+		Issuer:         idp.URL,
+		ClientID:       "learnd",
+		RedirectURL:    "http://learnd.test/login/oidc/callback",
+		AllowedDomains: allowedDomains,
+	})
+	sessions := newMockSessionRepo()
+	return NewAuthHandler("", false, time.Hour, users, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), sso), sessions, idp
+}
+
+func TestOIDCLinksExistingUser(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	users := &mockUserRepo{users: []*model.User{alice}}
+	h, sessions, idp := newOIDCHandler(t, users)
+
+	rec := oidcSignIn(t, h, idp)
+	if got := rec.Header().Get("Location"); got != "/reports" {
+		t.Fatalf("Location = %q, want /reports", got)
+	}
+	if len(sessions.sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(sessions.sessions))
+	}
+	for _, session := range sessions.sessions {
+		if session.UserID != alice.ID {
+			t.Errorf("session user = %v, want alice", session.UserID)
+		}
+	}
+	if users.identities[identityKey{idp.URL, "user-1"}] != alice.ID {
+		t.Error("expected the identity to be linked to alice")
+	}
+
+	// Once linked, the identity keeps signing in as alice even if its email changes
+	idp.Email = "alice@new.example.com"
+	if got := oidcSignIn(t, h, idp).Header().Get("Location"); got != "/reports" {
+		t.Errorf("second sign-in Location = %q, want /reports", got)
+	}
+	if len(users.users) != 1 {
+		t.Error("expected no new user")
+	}
+}
+
+func TestOIDCUnknownUser(t *testing.T) {
+	users := &mockUserRepo{}
+	h, sessions, idp := newOIDCHandler(t, users)
+
+	if got := oidcSignIn(t, h, idp).Header().Get("Location"); got != "/login?error=sso_denied" {
+		t.Errorf("Location = %q, want denied", got)
+	}
+	if len(sessions.sessions) != 0 || len(users.users) != 0 {
+		t.Error("expected no session or user without allowed domains")
+	}
+}
+
+func TestOIDCCreatesUserInAllowedDomain(t *testing.T) {
+	users := &mockUserRepo{}
+	h, sessions, idp := newOIDCHandler(t, users, "example.com")
+
+	if got := oidcSignIn(t, h, idp).Header().Get("Location"); got != "/reports" {
+		t.Fatalf("Location = %q, want /reports", got)
+	}
+	if len(users.users) != 1 || users.users[0].Email != "alice@example.com" || users.users[0].PasswordHash != nil {
+		t.Fatalf("users = %+v, want alice created without a password", users.users)
+	}
+	if len(sessions.sessions) != 1 {
+		t.Errorf("got %d sessions, want 1", len(sessions.sessions))
+	}
+
+	idp.Subject, idp.Email = "user-2", "mallory@evil.example"
+	if got := oidcSignIn(t, h, idp).Header().Get("Location"); got != "/login?error=sso_denied" {
+		t.Errorf("other domain Location = %q, want denied", got)
+	}
+}
+
+func TestOIDCTwoFactor(t *testing.T) {
+	secret := testTOTPSecret
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com", TOTPSecret: &secret}
+	h, sessions, idp := newOIDCHandler(t, &mockUserRepo{users: []*model.User{alice}})
+
+	if got := oidcSignIn(t, h, idp).Header().Get("Location"); got != "/login/2fa?redirect=%2Freports" {
+		t.Errorf("Location = %q, want the two-factor page", got)
+	}
+	if len(sessions.sessions) != 0 || len(sessions.pending) != 1 {
+		t.Error("expected only a pending session until the code is entered")
+	}
+}
+
+func TestOIDCCallbackRejectsForgedState(t *testing.T) {
+	alice := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	h, sessions, idp := newOIDCHandler(t, &mockUserRepo{users: []*model.User{alice}})
+
+	rec := httptest.NewRecorder()
+	h.OIDCLogin(rec, httptest.NewRequest(http.MethodGet, "/login/oidc", nil))
+	callback := idp.Authorize(t, rec.Header().Get("Location"))
+
+	// A callback without this browser's attempt cookie, e.g. one an attacker started
+	req := httptest.NewRequest(http.MethodGet, callback.String(), nil)
+	rec = httptest.NewRecorder()
+	h.OIDCCallback(rec, req)
+
+	if got := rec.Header().Get("Location"); got != "/login?error=sso_failed" {
+		t.Errorf("Location = %q, want failed", got)
+	}
+	if len(sessions.sessions) != 0 {
+		t.Error("expected no session")
+	}
+}
+
+func TestOIDCDisabled(t *testing.T) {
+	h := NewAuthHandler("", false, time.Hour, &mockUserRepo{}, newMockSessionRepo(), &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	rec := httptest.NewRecorder()
+	h.OIDCLogin(rec, httptest.NewRequest(http.MethodGet, "/login/oidc", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	h.LoginPage(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if strings.Contains(rec.Body.String(), "/login/oidc") {
+		t.Error("expected no single sign-on link")
+	}
+}
+
+func TestLoginPageOffersSSO(t *testing.T) {
+	h, _, _ := newOIDCHandler(t, &mockUserRepo{})
+
+	rec := httptest.NewRecorder()
+	h.LoginPage(rec, httptest.NewRequest(http.MethodGet, "/login?redirect=/reports", nil))
+	if !strings.Contains(rec.Body.String(), `href="/login/oidc?redirect=%2Freports"`) {
+		t.Error("expected a single sign-on link keeping the redirect")
+	}
+	if !strings.Contains(rec.Body.String(), `name="password"`) {
+		t.Error("expected password sign-in to remain as a fallback")
+	}
+}
@@ -8,21 +8,25 @@ import (
 	"strings"
 	"time"
 
+	"github.com/drywaters/learnd/internal/auth"
 	"github.com/drywaters/learnd/internal/model"
 	"github.com/drywaters/learnd/internal/repository"
+	"github.com/drywaters/learnd/internal/review"
 	"github.com/drywaters/learnd/internal/ui/pages"
 	"github.com/drywaters/learnd/internal/ui/partials"
 )
 
 // ReportHandler handles reporting
 type ReportHandler struct {
-	entryRepo *repository.EntryRepository
+	entryRepo  *repository.EntryRepository
+	reviewRepo *repository.ReviewRepository
 }
 
 // NewReportHandler creates a new ReportHandler
-func NewReportHandler(entryRepo *repository.EntryRepository) *ReportHandler {
+func NewReportHandler(entryRepo *repository.EntryRepository, reviewRepo *repository.ReviewRepository) *ReportHandler {
 	return &ReportHandler{
-		entryRepo: entryRepo,
+		entryRepo:  entryRepo,
+		reviewRepo: reviewRepo,
 	}
 }
 
@@ -35,37 +39,14 @@ func (h *ReportHandler) ReportsPage(w http.ResponseWriter, r *http.Request) {
 func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
-
-	var start, end time.Time
-	var err error
-
-	if startStr != "" {
-		start, err = time.Parse("2006-01-02", startStr)
-		if err != nil {
-			http.Error(w, "Invalid start date", http.StatusBadRequest)
-			return
-		}
-	} else {
-		// Default to 30 days ago
-		start = time.Now().AddDate(0, 0, -30)
-	}
-
-	if endStr != "" {
-		end, err = time.Parse("2006-01-02", endStr)
-		if err != nil {
-			http.Error(w, "Invalid end date", http.StatusBadRequest)
-			return
-		}
-		// Include the full end day
-		end = end.Add(24*time.Hour - time.Second)
-	} else {
-		end = time.Now()
+	start, end, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Get totals from database
-	totals, err := h.entryRepo.GetReportTotals(ctx, start, end)
+	totals, err := h.entryRepo.GetReportTotals(ctx, auth.UserID(ctx), start, end)
 	if err != nil {
 		slog.Error("failed to get report totals", "handler", "GetReport", "error", err)
 		http.Error(w, "Failed to get report", http.StatusInternalServerError)
@@ -73,7 +54,7 @@ func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get aggregations by tag from database
-	tagAggs, err := h.entryRepo.AggregateByTag(ctx, start, end)
+	tagAggs, err := h.entryRepo.AggregateByTag(ctx, auth.UserID(ctx), start, end)
 	if err != nil {
 		slog.Error("failed to aggregate by tag", "handler", "GetReport", "error", err)
 		http.Error(w, "Failed to get report", http.StatusInternalServerError)
@@ -81,13 +62,31 @@ func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get aggregations by type from database
-	typeAggs, err := h.entryRepo.AggregateByType(ctx, start, end)
+	typeAggs, err := h.entryRepo.AggregateByType(ctx, auth.UserID(ctx), start, end)
 	if err != nil {
 		slog.Error("failed to aggregate by type", "handler", "GetReport", "error", err)
 		http.Error(w, "Failed to get report", http.StatusInternalServerError)
 		return
 	}
 
+	// Get review activity; the streak always runs up to today
+	reviewTotals, err := h.reviewRepo.GetReviewTotals(ctx, auth.UserID(ctx), start, end)
+	if err != nil {
+		slog.Error("failed to get review totals", "handler", "GetReport", "error", err)
+		http.Error(w, "Failed to get report", http.StatusInternalServerError)
+		return
+	}
+
+	// Days are counted in the browser's time zone so late-evening reviews land on the right day
+	loc := reportLocation(r)
+	now := time.Now().In(loc)
+	reviewDays, err := h.reviewRepo.ReviewDays(ctx, auth.UserID(ctx), now.AddDate(-1, 0, 0), loc)
+	if err != nil {
+		slog.Error("failed to get review days", "handler", "GetReport", "error", err)
+		http.Error(w, "Failed to get report", http.StatusInternalServerError)
+		return
+	}
+
 	// Build report data
 	var tagReport []partials.TagReport
 	totalTagEntries := 0
@@ -128,6 +127,9 @@ func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
 		TotalTypeTime:    totalTypeTime,
 		ByTag:            tagReport,
 		ByType:           typeReport,
+		Reviews:          reviewTotals.Reviews,
+		Retention:        reviewTotals.Retention(),
+		ReviewStreak:     review.Streak(reviewDays, now),
 	}
 
 	partials.ReportResults(data).Render(ctx, w)
@@ -137,44 +139,21 @@ func (h *ReportHandler) GetReport(w http.ResponseWriter, r *http.Request) {
 func (h *ReportHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	startStr := r.URL.Query().Get("start")
-	endStr := r.URL.Query().Get("end")
-
-	var start, end time.Time
-	var err error
-
-	if startStr != "" {
-		start, err = time.Parse("2006-01-02", startStr)
-		if err != nil {
-			http.Error(w, "Invalid start date", http.StatusBadRequest)
-			return
-		}
-	} else {
-		start = time.Now().AddDate(0, 0, -30)
-	}
-
-	if endStr != "" {
-		end, err = time.Parse("2006-01-02", endStr)
-		if err != nil {
-			http.Error(w, "Invalid end date", http.StatusBadRequest)
-			return
-		}
-		end = end.Add(24*time.Hour - time.Second)
-	} else {
-		end = time.Now()
+	start, end, err := parseDateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	const pageSize = 1000
-
 	opts := repository.ListOptions{
-		Limit:  pageSize,
+		Limit:  exportPageSize,
 		Offset: 0,
 		Start:  &start,
 		End:    &end,
 	}
 
 	// Fetch first page before writing headers to allow clean error response
-	entries, err := h.entryRepo.List(ctx, opts)
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), opts)
 	if err != nil {
 		slog.Error("failed to list entries", "handler", "ExportCSV", "offset", opts.Offset, "error", err)
 		http.Error(w, "Failed to get entries", http.StatusInternalServerError)
@@ -240,13 +219,13 @@ func (h *ReportHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Check if this was the last page
-		if len(entries) < pageSize {
+		if len(entries) < exportPageSize {
 			break
 		}
 
 		// Fetch next page
 		opts.Offset += len(entries)
-		entries, err = h.entryRepo.List(ctx, opts)
+		entries, err = h.entryRepo.List(ctx, auth.UserID(ctx), opts)
 		if err != nil {
 			// Headers already sent, can only log and stop
 			slog.Error("failed to list entries", "handler", "ExportCSV", "offset", opts.Offset, "error", err)
@@ -255,6 +234,53 @@ func (h *ReportHandler) ExportCSV(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// parseDateRange reads the start and end query parameters shared by reports and exports.
+// Defaults to the last 30 days; the end date is inclusive.
+func parseDateRange(r *http.Request) (time.Time, time.Time, error) {
+	startStr := r.URL.Query().Get("start")
+	endStr := r.URL.Query().Get("end")
+
+	var start, end time.Time
+	var err error
+
+	if startStr != "" {
+		start, err = time.Parse("2006-01-02", startStr)
+		if err != nil {
+			return start, end, fmt.Errorf("Invalid start date")
+		}
+	} else {
+		// Default to 30 days ago
+		start = time.Now().AddDate(0, 0, -30)
+	}
+
+	if endStr != "" {
+		end, err = time.Parse("2006-01-02", endStr)
+		if err != nil {
+			return start, end, fmt.Errorf("Invalid end date")
+		}
+		// Include the full end day
+		end = end.Add(24*time.Hour - time.Second)
+	} else {
+		end = time.Now()
+	}
+
+	return start, end, nil
+}
+
+// reportLocation returns the IANA time zone the reports page sends as tz, or UTC
+// when it's missing or unknown
+func reportLocation(r *http.Request) *time.Location {
+	name := r.URL.Query().Get("tz")
+	if name == "" || name == "Local" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 func reportTrackedSeconds(entry model.Entry) int {
 	if entry.TimeSpentSeconds != nil && *entry.TimeSpentSeconds > 0 {
 		return *entry.TimeSpentSeconds
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/review"
+	"github.com/drywaters/learnd/internal/ui"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// ReviewRepo defines the repository operations used by the review page
+type ReviewRepo interface {
+	NextDue(ctx context.Context, userID uuid.UUID, now time.Time) (*model.ReviewCard, error)
+	CountDue(ctx context.Context, userID uuid.UUID, now time.Time) (int, error)
+	GetCard(ctx context.Context, userID, id uuid.UUID) (*model.ReviewCard, error)
+	RecordReview(ctx context.Context, card *model.ReviewCard, log *model.ReviewLog) error
+}
+
+// ReviewHandler handles spaced-repetition review of cards
+type ReviewHandler struct {
+	reviewRepo ReviewRepo
+}
+
+// NewReviewHandler creates a new ReviewHandler
+func NewReviewHandler(reviewRepo ReviewRepo) *ReviewHandler {
+	return &ReviewHandler{
+		reviewRepo: reviewRepo,
+	}
+}
+
+// ReviewPage renders the next due card
+func (h *ReviewHandler) ReviewPage(w http.ResponseWriter, r *http.Request) {
+	view, err := h.nextCard(r.Context())
+	if err != nil {
+		slog.Error("failed to load review card", "handler", "ReviewPage", "error", err)
+		http.Error(w, "Failed to load review", http.StatusInternalServerError)
+		return
+	}
+
+	pages.ReviewPage(*view).Render(r.Context(), w)
+}
+
+// Rate records a rating for a card, reschedules it and renders the next due card
+func (h *ReviewHandler) Rate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	rating, err := review.ParseRating(r.FormValue("rating"))
+	if err != nil {
+		http.Error(w, "Invalid rating", http.StatusBadRequest)
+		return
+	}
+
+	card, err := h.reviewRepo.GetCard(ctx, auth.UserID(ctx), id)
+	if err != nil {
+		slog.Error("failed to get review card", "handler", "Rate", "id", id, "error", err)
+		http.Error(w, "Failed to get card", http.StatusInternalServerError)
+		return
+	}
+	if card == nil {
+		http.Error(w, "Card not found", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	next := review.Schedule(*card, rating, now)
+	log := &model.ReviewLog{
+		CardID:               card.ID,
+		Rating:               rating,
+		ReviewedAt:           now,
+		PreviousIntervalDays: card.IntervalDays,
+		IntervalDays:         next.IntervalDays,
+		Ease:                 next.Ease,
+	}
+	if err := h.reviewRepo.RecordReview(ctx, &next, log); err != nil {
+		slog.Error("failed to record review", "handler", "Rate", "id", id, "error", err)
+		http.Error(w, "Failed to record review", http.StatusInternalServerError)
+		return
+	}
+
+	view, err := h.nextCard(ctx)
+	if err != nil {
+		slog.Error("failed to load review card", "handler", "Rate", "error", err)
+		http.Error(w, "Failed to load review", http.StatusInternalServerError)
+		return
+	}
+
+	partials.ReviewCard(*view).Render(ctx, w)
+}
+
+func (h *ReviewHandler) nextCard(ctx context.Context) (*ui.ReviewCardView, error) {
+	now := time.Now()
+
+	card, err := h.reviewRepo.NextDue(ctx, auth.UserID(ctx), now)
+	if err != nil {
+		return nil, err
+	}
+	count, err := h.reviewRepo.CountDue(ctx, auth.UserID(ctx), now)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &ui.ReviewCardView{Card: card, DueCount: count}
+	if card != nil {
+		for _, rating := range model.ReviewRatings {
+			view.Options = append(view.Options, ui.ReviewOption{
+				Rating: rating,
+				Label:  review.IntervalLabel(*card, rating, now),
+			})
+		}
+	}
+	return view, nil
+}
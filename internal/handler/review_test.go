@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type mockReviewRepo struct {
+	cards    map[uuid.UUID]*model.ReviewCard
+	recorded *model.ReviewCard
+	log      *model.ReviewLog
+}
+
+func (m *mockReviewRepo) NextDue(ctx context.Context, userID uuid.UUID, now time.Time) (*model.ReviewCard, error) {
+	for _, card := range m.cards {
+		if !card.DueAt.After(now) {
+			return card, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockReviewRepo) CountDue(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	count := 0
+	for _, card := range m.cards {
+		if !card.DueAt.After(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockReviewRepo) GetCard(ctx context.Context, userID, id uuid.UUID) (*model.ReviewCard, error) {
+	return m.cards[id], nil
+}
+
+func (m *mockReviewRepo) RecordReview(ctx context.Context, card *model.ReviewCard, log *model.ReviewLog) error {
+	m.recorded = card
+	m.log = log
+	m.cards[card.ID] = card
+	return nil
+}
+
+func TestReviewRate(t *testing.T) {
+	cardID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+
+	tests := []struct {
+		name           string
+		id             string
+		body           string
+		expectedStatus int
+		wantRating     model.ReviewRating
+		expectedBody   string
+	}{
+		{name: "good", id: cardID.String(), body: "rating=good", expectedStatus: http.StatusOK, wantRating: model.RatingGood, expectedBody: "Nothing is due"},
+		{name: "again brings the card back later", id: cardID.String(), body: "rating=again", expectedStatus: http.StatusOK, wantRating: model.RatingAgain, expectedBody: "Nothing is due"},
+		{name: "invalid rating", id: cardID.String(), body: "rating=perfect", expectedStatus: http.StatusBadRequest},
+		{name: "invalid id", id: "not-a-uuid", body: "rating=good", expectedStatus: http.StatusBadRequest},
+		{name: "missing card", id: "550e8400-e29b-41d4-a716-446655440099", body: "rating=good", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockReviewRepo{cards: map[uuid.UUID]*model.ReviewCard{
+				cardID: {ID: cardID, Kind: model.CardKindEntry, Front: "Go generics", Back: "Summary", Ease: 2.5, DueAt: time.Now().Add(-time.Hour)},
+			}}
+			handler := NewReviewHandler(repo)
+			router := chi.NewRouter()
+			router.Post("/api/review/{id}", handler.Rate)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/review/"+tt.id, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("Rate() status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+			if tt.wantRating == "" {
+				if repo.log != nil {
+					t.Errorf("Rate() recorded a review for a rejected request")
+				}
+				return
+			}
+			if repo.log == nil || repo.log.Rating != tt.wantRating || repo.log.CardID != cardID {
+				t.Fatalf("Rate() log = %+v, want rating %q", repo.log, tt.wantRating)
+			}
+			if !repo.recorded.DueAt.After(time.Now()) {
+				t.Errorf("Rate() left the card due at %v", repo.recorded.DueAt)
+			}
+			if !strings.Contains(rec.Body.String(), tt.expectedBody) {
+				t.Errorf("Rate() body missing %q", tt.expectedBody)
+			}
+		})
+	}
+}
+
+func TestReviewPage(t *testing.T) {
+	cardID := uuid.New()
+	repo := &mockReviewRepo{cards: map[uuid.UUID]*model.ReviewCard{
+		cardID: {ID: cardID, Kind: model.CardKindQA, Front: "What is a type parameter?", Back: "A placeholder type.", Ease: 2.5, DueAt: time.Now().Add(-time.Minute)},
+	}}
+
+	rec := httptest.NewRecorder()
+	NewReviewHandler(repo).ReviewPage(rec, httptest.NewRequest(http.MethodGet, "/review", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("ReviewPage() status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"What is a type parameter?", "1 due", "/api/review/" + cardID.String(), "10m", "1d", "4d"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("ReviewPage() body missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// SessionRepo defines the repository operations for browser sessions
+type SessionRepo interface {
+	Create(ctx context.Context, userID uuid.UUID, tokenHash, userAgent, ip string, expiresAt time.Time) (*model.Session, error)
+	CreatePending(ctx context.Context, userID uuid.UUID, tokenHash, userAgent, ip string, expiresAt time.Time) (*model.Session, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error)
+	GetPendingByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error)
+	CompletePending(ctx context.Context, id uuid.UUID, expiresAt time.Time) error
+	RecordFailedTOTP(ctx context.Context, id uuid.UUID) (int, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]model.Session, error)
+	Delete(ctx context.Context, userID, id uuid.UUID) (bool, error)
+	DeleteByTokenHash(ctx context.Context, tokenHash string) error
+	DeleteAllForUser(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// SessionHandler lists and revokes the signed-in user's browser sessions
+type SessionHandler struct {
+	sessions      SessionRepo
+	secureCookies bool
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(sessions SessionRepo, secureCookies bool) *SessionHandler {
+	return &SessionHandler{
+		sessions:      sessions,
+		secureCookies: secureCookies,
+	}
+}
+
+// SessionsPage renders the user's active sessions
+func (h *SessionHandler) SessionsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sessions, err := h.sessions.ListByUser(ctx, auth.UserID(ctx))
+	if err != nil {
+		slog.Error("failed to list sessions", "handler", "SessionsPage", "error", err)
+		http.Error(w, "Failed to load sessions", http.StatusInternalServerError)
+		return
+	}
+
+	pages.SessionsPage(sessions, currentSessionID(ctx)).Render(ctx, w)
+}
+
+// Revoke signs out one of the user's other browsers
+func (h *SessionHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	if id == currentSessionID(ctx) {
+		htmxError(w, "Use Sign Out to end this session")
+		return
+	}
+
+	found, err := h.sessions.Delete(ctx, auth.UserID(ctx), id)
+	if err != nil {
+		slog.Error("failed to revoke session", "handler", "Revoke", "id", id, "error", err)
+		htmxError(w, "Failed to sign out session")
+		return
+	}
+	if !found {
+		http.Error(w, "Session not found", http.StatusNotFound)
+		return
+	}
+
+	sessions, err := h.sessions.ListByUser(ctx, auth.UserID(ctx))
+	if err != nil {
+		slog.Error("failed to list sessions", "handler", "Revoke", "error", err)
+		htmxError(w, "Failed to reload sessions")
+		return
+	}
+
+	htmxToast(w, "Session signed out", nil, "")
+	partials.SessionList(sessions, currentSessionID(ctx)).Render(ctx, w)
+}
+
+// RevokeAll signs the user out of every browser, including this one
+func (h *SessionHandler) RevokeAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	count, err := h.sessions.DeleteAllForUser(ctx, auth.UserID(ctx))
+	if err != nil {
+		slog.Error("failed to revoke sessions", "handler", "RevokeAll", "error", err)
+		htmxError(w, "Failed to sign out everywhere")
+		return
+	}
+	slog.Info("revoked all sessions", "user_id", auth.UserID(ctx), "count", count)
+
+	auth.ClearSessionCookie(w, h.secureCookies)
+	w.Header().Set("HX-Redirect", "/login")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentSessionID returns the ID of the session the request was made with, if any
+func currentSessionID(ctx context.Context) uuid.UUID {
+	if session := auth.SessionFromContext(ctx); session != nil {
+		return session.ID
+	}
+	return uuid.Nil
+}
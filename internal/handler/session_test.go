@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type mockSessionRepo struct {
+	sessions map[string]*model.Session // by token hash
+	pending  map[string]*model.Session // awaiting a second factor, by token hash
+	attempts map[uuid.UUID]int
+}
+
+func newMockSessionRepo() *mockSessionRepo {
+	return &mockSessionRepo{
+		sessions: make(map[string]*model.Session),
+		pending:  make(map[string]*model.Session),
+		attempts: make(map[uuid.UUID]int),
+	}
+}
+
+func (m *mockSessionRepo) Create(ctx context.Context, userID uuid.UUID, tokenHash, userAgent, ip string, expiresAt time.Time) (*model.Session, error) {
+	now := time.Now()
+	session := &model.Session{
+		ID: uuid.New(), UserID: userID, UserAgent: userAgent, IP: ip,
+		CreatedAt: now, LastSeenAt: now, ExpiresAt: expiresAt,
+	}
+	m.sessions[tokenHash] = session
+	return session, nil
+}
+
+func (m *mockSessionRepo) CreatePending(ctx context.Context, userID uuid.UUID, tokenHash, userAgent, ip string, expiresAt time.Time) (*model.Session, error) {
+	now := time.Now()
+	session := &model.Session{
+		ID: uuid.New(), UserID: userID, UserAgent: userAgent, IP: ip,
+		CreatedAt: now, LastSeenAt: now, ExpiresAt: expiresAt,
+	}
+	m.pending[tokenHash] = session
+	return session, nil
+}
+
+func (m *mockSessionRepo) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	return m.pending[tokenHash], nil
+}
+
+func (m *mockSessionRepo) CompletePending(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	for hash, s := range m.pending {
+		if s.ID == id {
+			s.ExpiresAt = expiresAt
+			m.sessions[hash] = s
+			delete(m.pending, hash)
+		}
+	}
+	return nil
+}
+
+func (m *mockSessionRepo) RecordFailedTOTP(ctx context.Context, id uuid.UUID) (int, error) {
+	m.attempts[id]++
+	return m.attempts[id], nil
+}
+
+func (m *mockSessionRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	return m.sessions[tokenHash], nil
+}
+
+func (m *mockSessionRepo) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	var sessions []model.Session
+	for _, s := range m.sessions {
+		if s.UserID == userID {
+			sessions = append(sessions, *s)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *mockSessionRepo) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	for hash, s := range m.sessions {
+		if s.ID == id && s.UserID == userID {
+			delete(m.sessions, hash)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockSessionRepo) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
+	delete(m.sessions, tokenHash)
+	delete(m.pending, tokenHash)
+	return nil
+}
+
+func (m *mockSessionRepo) DeleteAllForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	for hash, s := range m.sessions {
+		if s.UserID == userID {
+			delete(m.sessions, hash)
+			count++
+		}
+	}
+	return count, nil
+}
+
+// withSession returns a request made by the user from the given session
+func withSession(req *http.Request, user *model.User, session *model.Session) *http.Request {
+	ctx := auth.WithSession(auth.WithUser(req.Context(), user), session)
+	return req.WithContext(ctx)
+}
+
+func TestSessionsPage(t *testing.T) {
+	repo := newMockSessionRepo()
+	user := &model.User{ID: uuid.New()}
+	current, _ := repo.Create(context.Background(), user.ID, "current", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) Firefox/128.0", "10.0.0.1", time.Now().Add(time.Hour))
+	repo.Create(context.Background(), user.ID, "phone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) Version/17.0 Mobile Safari/604.1", "10.0.0.2", time.Now().Add(time.Hour))
+	repo.Create(context.Background(), uuid.New(), "other", "curl/8.0", "10.0.0.3", time.Now().Add(time.Hour))
+
+	h := NewSessionHandler(repo, false)
+	rec := httptest.NewRecorder()
+	h.SessionsPage(rec, withSession(httptest.NewRequest(http.MethodGet, "/settings/sessions", nil), user, current))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"Firefox on macOS", "Safari on iPhone", "10.0.0.2", "This device", "/api/sessions/"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q", want)
+		}
+	}
+	if strings.Contains(body, "10.0.0.3") {
+		t.Error("body lists another user's session")
+	}
+}
+
+func TestPagesEmbedCSRFToken(t *testing.T) {
+	user := &model.User{ID: uuid.New()}
+	session := &model.Session{ID: uuid.New(), UserID: user.ID}
+	token := auth.CSRFToken("session-token")
+
+	h := NewSessionHandler(newMockSessionRepo(), false)
+	rec := httptest.NewRecorder()
+	req := withSession(httptest.NewRequest(http.MethodGet, "/settings/sessions", nil), user, session)
+	h.SessionsPage(rec, req.WithContext(auth.WithCSRFToken(req.Context(), token)))
+
+	body := rec.Body.String()
+	if !strings.Contains(body, `hx-headers="{&#34;X-CSRF-Token&#34;:&#34;`+token+`&#34;}"`) {
+		t.Error("expected htmx to send the CSRF token header")
+	}
+	if !strings.Contains(body, `name="csrf_token" value="`+token+`"`) {
+		t.Error("expected the sign out form to carry the CSRF token")
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	repo := newMockSessionRepo()
+	user := &model.User{ID: uuid.New()}
+	current, _ := repo.Create(context.Background(), user.ID, "current", "", "", time.Now().Add(time.Hour))
+	phone, _ := repo.Create(context.Background(), user.ID, "phone", "", "", time.Now().Add(time.Hour))
+	other, _ := repo.Create(context.Background(), uuid.New(), "other", "", "", time.Now().Add(time.Hour))
+
+	h := NewSessionHandler(repo, false)
+	revoke := func(id uuid.UUID) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/api/sessions/"+id.String(), nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id.String())
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rec := httptest.NewRecorder()
+		h.Revoke(rec, withSession(req, user, current))
+		return rec
+	}
+
+	if rec := revoke(other.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("revoking another user's session: status = %d, want 404", rec.Code)
+	}
+	if rec := revoke(current.ID); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("revoking the current session: status = %d, want 422", rec.Code)
+	}
+	if rec := revoke(phone.ID); rec.Code != http.StatusOK {
+		t.Errorf("revoking another session: status = %d, want 200", rec.Code)
+	}
+
+	if _, ok := repo.sessions["phone"]; ok {
+		t.Error("phone session was not revoked")
+	}
+	if len(repo.sessions) != 2 {
+		t.Errorf("%d sessions left, want 2", len(repo.sessions))
+	}
+}
+
+func TestRevokeAllSessions(t *testing.T) {
+	repo := newMockSessionRepo()
+	user := &model.User{ID: uuid.New()}
+	current, _ := repo.Create(context.Background(), user.ID, "current", "", "", time.Now().Add(time.Hour))
+	repo.Create(context.Background(), user.ID, "phone", "", "", time.Now().Add(time.Hour))
+	repo.Create(context.Background(), uuid.New(), "other", "", "", time.Now().Add(time.Hour))
+
+	h := NewSessionHandler(repo, false)
+	rec := httptest.NewRecorder()
+	h.RevokeAll(rec, withSession(httptest.NewRequest(http.MethodPost, "/api/sessions/revoke-all", nil), user, current))
+
+	if got := rec.Header().Get("HX-Redirect"); got != "/login" {
+		t.Errorf("HX-Redirect = %q, want /login", got)
+	}
+	if len(repo.sessions) != 1 || repo.sessions["other"] == nil {
+		t.Errorf("expected only the other user's session to remain, got %d", len(repo.sessions))
+	}
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != auth.SessionCookieName || cookies[0].MaxAge >= 0 {
+		t.Errorf("expected the session cookie to be cleared, got %+v", cookies)
+	}
+}
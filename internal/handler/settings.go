@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/repository"
+	"github.com/drywaters/learnd/internal/summarizer"
+	"github.com/drywaters/learnd/internal/ui"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// previewEntryLimit is the number of recent entries offered for prompt previews
+const previewEntryLimit = 20
+
+// promptSourceTypes lists the source types with editable prompts, in display order
+var promptSourceTypes = []struct {
+	Type  model.SourceType
+	Label string
+}{
+	{model.SourceTypeArticle, "Article"},
+	{model.SourceTypeYouTube, "YouTube"},
+	{model.SourceTypePodcast, "Podcast"},
+	{model.SourceTypeDoc, "Documentation"},
+	{model.SourceTypeOther, "Other"},
+}
+
+// SettingsHandler handles the settings pages
+type SettingsHandler struct {
+	entryRepo  *repository.EntryRepository
+	promptRepo *repository.PromptTemplateRepository
+}
+
+// NewSettingsHandler creates a new SettingsHandler
+func NewSettingsHandler(entryRepo *repository.EntryRepository, promptRepo *repository.PromptTemplateRepository) *SettingsHandler {
+	return &SettingsHandler{
+		entryRepo:  entryRepo,
+		promptRepo: promptRepo,
+	}
+}
+
+// PromptsPage renders the prompt template settings page
+func (h *SettingsHandler) PromptsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	saved, err := h.promptRepo.List(ctx)
+	if err != nil {
+		slog.Error("failed to list prompt templates", "handler", "PromptsPage", "error", err)
+		http.Error(w, "Failed to load prompt templates", http.StatusInternalServerError)
+		return
+	}
+
+	var views []ui.PromptTemplateView
+	for _, st := range promptSourceTypes {
+		view, err := h.promptView(ctx, st.Type, saved[st.Type])
+		if err != nil {
+			slog.Error("failed to build prompt template view", "handler", "PromptsPage", "type", st.Type, "error", err)
+			http.Error(w, "Failed to load prompt templates", http.StatusInternalServerError)
+			return
+		}
+		views = append(views, view)
+	}
+
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), repository.ListOptions{Limit: previewEntryLimit})
+	if err != nil {
+		slog.Error("failed to list entries", "handler", "PromptsPage", "error", err)
+		http.Error(w, "Failed to load entries", http.StatusInternalServerError)
+		return
+	}
+
+	pages.PromptsPage(views, entries).Render(ctx, w)
+}
+
+// SavePrompt validates and saves a prompt template as a new version
+func (h *SettingsHandler) SavePrompt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sourceType := parseSourceType(chi.URLParam(r, "type"))
+	if sourceType == nil {
+		http.Error(w, "Invalid source type", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		htmxError(w, "Invalid form data")
+		return
+	}
+
+	text := r.FormValue("template")
+	if _, err := summarizer.ParsePromptTemplate(text); err != nil {
+		htmxError(w, fmt.Sprintf("Invalid %s template: %v", *sourceType, err))
+		return
+	}
+
+	saved, err := h.promptRepo.Save(ctx, *sourceType, text)
+	if err != nil {
+		slog.Error("failed to save prompt template", "handler", "SavePrompt", "type", *sourceType, "error", err)
+		htmxError(w, "Failed to save template")
+		return
+	}
+
+	htmxToast(w, fmt.Sprintf("Saved %s template version %d", *sourceType, saved.Version), nil, "")
+	h.renderPromptCard(w, r, *sourceType, saved)
+}
+
+// ResetPrompt saves the default template as a new version
+func (h *SettingsHandler) ResetPrompt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sourceType := parseSourceType(chi.URLParam(r, "type"))
+	if sourceType == nil {
+		http.Error(w, "Invalid source type", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := h.promptRepo.Save(ctx, *sourceType, summarizer.DefaultPromptTemplate)
+	if err != nil {
+		slog.Error("failed to reset prompt template", "handler", "ResetPrompt", "type", *sourceType, "error", err)
+		htmxError(w, "Failed to reset template")
+		return
+	}
+
+	htmxToast(w, fmt.Sprintf("Reset %s template to default", *sourceType), nil, "")
+	h.renderPromptCard(w, r, *sourceType, saved)
+}
+
+// PreviewPrompt renders the submitted template for an entry without saving it. The
+// entry is rendered as the template's source type, whatever its own type is.
+func (h *SettingsHandler) PreviewPrompt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sourceType := parseSourceType(chi.URLParam(r, "type"))
+	if sourceType == nil {
+		http.Error(w, "Invalid source type", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		htmxError(w, "Invalid form data")
+		return
+	}
+
+	tmpl, err := summarizer.ParsePromptTemplate(r.FormValue("template"))
+	if err != nil {
+		htmxError(w, fmt.Sprintf("Invalid template: %v", err))
+		return
+	}
+
+	id, err := uuid.Parse(r.FormValue("entry_id"))
+	if err != nil {
+		htmxError(w, "Choose an entry to preview")
+		return
+	}
+
+	entry, err := h.entryRepo.GetByID(ctx, auth.UserID(ctx), id)
+	if err != nil {
+		slog.Error("failed to get entry", "handler", "PreviewPrompt", "id", id, "error", err)
+		htmxError(w, "Failed to load entry")
+		return
+	}
+	if entry == nil {
+		htmxError(w, "Entry not found")
+		return
+	}
+
+	input := summarizer.InputFromEntry(entry)
+	input.SourceType = *sourceType
+	prompt, err := summarizer.RenderPrompt(tmpl, input)
+	if err != nil {
+		htmxError(w, err.Error())
+		return
+	}
+
+	partials.PromptPreview(prompt).Render(ctx, w)
+}
+
+// ResummarizePrompt requeues summaries generated with an outdated version of a template
+func (h *SettingsHandler) ResummarizePrompt(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	sourceType := parseSourceType(chi.URLParam(r, "type"))
+	if sourceType == nil {
+		http.Error(w, "Invalid source type", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := h.promptRepo.Get(ctx, *sourceType)
+	if err != nil {
+		slog.Error("failed to get prompt template", "handler", "ResummarizePrompt", "type", *sourceType, "error", err)
+		htmxError(w, "Failed to load template")
+		return
+	}
+
+	version := 0
+	if saved != nil {
+		version = saved.Version
+	}
+
+	count, err := h.entryRepo.RequeueOutdatedPromptSummaries(ctx, *sourceType, version)
+	if err != nil {
+		slog.Error("failed to requeue outdated summaries", "handler", "ResummarizePrompt", "type", *sourceType, "error", err)
+		htmxError(w, "Failed to queue summaries")
+		return
+	}
+
+	htmxToast(w, fmt.Sprintf("Queued %d entries for re-summarization", count), nil, "")
+	h.renderPromptCard(w, r, *sourceType, saved)
+}
+
+// renderPromptCard renders the settings card for a template after a change
+func (h *SettingsHandler) renderPromptCard(w http.ResponseWriter, r *http.Request, sourceType model.SourceType, saved *model.PromptTemplate) {
+	ctx := r.Context()
+
+	view, err := h.promptView(ctx, sourceType, saved)
+	if err != nil {
+		slog.Error("failed to build prompt template view", "type", sourceType, "error", err)
+		http.Error(w, "Failed to load template", http.StatusInternalServerError)
+		return
+	}
+
+	entries, err := h.entryRepo.List(ctx, auth.UserID(ctx), repository.ListOptions{Limit: previewEntryLimit})
+	if err != nil {
+		slog.Error("failed to list entries", "error", err)
+		http.Error(w, "Failed to load entries", http.StatusInternalServerError)
+		return
+	}
+
+	partials.PromptTemplateCard(view, entries).Render(ctx, w)
+}
+
+// promptView builds the view for a source type; saved is nil when the default is in use
+func (h *SettingsHandler) promptView(ctx context.Context, sourceType model.SourceType, saved *model.PromptTemplate) (ui.PromptTemplateView, error) {
+	view := ui.PromptTemplateView{
+		SourceType: sourceType,
+		Label:      string(sourceType),
+		Template:   summarizer.DefaultPromptTemplate,
+	}
+	for _, st := range promptSourceTypes {
+		if st.Type == sourceType {
+			view.Label = st.Label
+		}
+	}
+	if saved != nil {
+		view.Template = saved.Template
+		view.Version = saved.Version
+		view.UpdatedAt = &saved.UpdatedAt
+	}
+
+	count, err := h.entryRepo.CountOutdatedPromptSummaries(ctx, sourceType, view.Version)
+	if err != nil {
+		return view, err
+	}
+	view.OutdatedCount = count
+
+	return view, nil
+}
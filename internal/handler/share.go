@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/google/uuid"
+)
+
+// sharedURLPattern finds links inside shared text, e.g. "Great read https://example.com/post"
+var sharedURLPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// MailKeyRepo defines the repository operation for replacing a user's emailing address
+type MailKeyRepo interface {
+	SetMailKey(ctx context.Context, id uuid.UUID, key string) (bool, error)
+}
+
+// ShareHandler handles captures coming from the OS share sheet, the bookmarklet and email
+type ShareHandler struct {
+	users MailKeyRepo
+	// mailRecipient is the address emailed links are sent to, or "" when email-in is off
+	mailRecipient string
+}
+
+// NewShareHandler creates a new ShareHandler
+func NewShareHandler(users MailKeyRepo, mailRecipient string) *ShareHandler {
+	return &ShareHandler{
+		users:         users,
+		mailRecipient: mailRecipient,
+	}
+}
+
+// Share renders the quick-capture popup for a Web Share Target or bookmarklet request.
+// Loading it never saves anything. When a link can be found in a signed-in browser,
+// the popup posts it back with the session's CSRF token and closes itself, so the
+// save passes the same cross-origin and token checks as any other form.
+func (h *ShareHandler) Share(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	link, notes := sharedLink(query.Get("title"), query.Get("text"), query.Get("url"))
+
+	autosave := link != "" && auth.CSRFTokenFromContext(r.Context()) != ""
+	if err := pages.QuickCapturePage(link, notes, autosave).Render(r.Context(), w); err != nil {
+		slog.Error("failed to render page", "handler", "Share", "error", err)
+	}
+}
+
+// BookmarkletPage renders a draggable bookmarklet that opens the current page in the quick-capture popup
+func (h *ShareHandler) BookmarkletPage(w http.ResponseWriter, r *http.Request) {
+	script := bookmarkletScript(requestOrigin(r))
+
+	// The emailing address can save entries, so it's only shown to signed-in browsers,
+	// never to a read-only token
+	mailEnabled := h.mailRecipient != "" && auth.SessionFromContext(r.Context()) != nil
+	address := ""
+	if mailEnabled {
+		address = mailAddress(h.mailRecipient, auth.UserFromContext(r.Context()))
+	}
+
+	if err := pages.BookmarkletPage(script, mailEnabled, address).Render(r.Context(), w); err != nil {
+		slog.Error("failed to render page", "handler", "BookmarkletPage", "error", err)
+	}
+}
+
+// NewMailAddress gives the user a new emailing address, so mail sent to the old one is rejected
+func (h *ShareHandler) NewMailAddress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+	if h.mailRecipient == "" {
+		htmxError(w, "Email capture isn't enabled")
+		return
+	}
+
+	key, err := auth.NewMailKey()
+	if err != nil {
+		slog.Error("failed to generate mail key", "handler", "NewMailAddress", "error", err)
+		htmxError(w, "Failed to create address")
+		return
+	}
+	if _, err := h.users.SetMailKey(ctx, user.ID, key); err != nil {
+		slog.Error("failed to set mail key", "handler", "NewMailAddress", "error", err)
+		htmxError(w, "Failed to create address")
+		return
+	}
+
+	slog.Info("replaced mail address", "user_id", user.ID)
+	updated := *user
+	updated.MailKey = &key
+	partials.MailAddress(mailAddress(h.mailRecipient, &updated)).Render(ctx, w)
+}
+
+// mailAddress returns the user's address for emailing links, e.g. save+key@example.com,
+// or "" if they don't have a mail key yet
+func mailAddress(recipient string, user *model.User) string {
+	if user == nil || user.MailKey == nil {
+		return ""
+	}
+	local, domain, ok := strings.Cut(recipient, "@")
+	if !ok {
+		return ""
+	}
+	return local + "+" + *user.MailKey + "@" + domain
+}
+
+// Bookmarklet returns the bookmarklet script as plain text, for installing it by hand
+func (h *ShareHandler) Bookmarklet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, bookmarkletScript(requestOrigin(r)))
+}
+
+// sharedLink picks the link to capture from share parameters. Many apps put the link in
+// text rather than url, so text and then title are searched when url isn't usable.
+// Any other shared text is returned as notes, unless it only repeats the title.
+func sharedLink(title, text, rawURL string) (string, string) {
+	title = strings.TrimSpace(title)
+	text = strings.TrimSpace(text)
+
+	link := strings.TrimSpace(rawURL)
+	if !isHTTPURL(link) {
+		link = ""
+		if found, match := findSharedURL(text); found != "" {
+			link = found
+			text = strings.TrimSpace(strings.Replace(text, match, "", 1))
+		} else if found, match := findSharedURL(title); found != "" {
+			link = found
+			title = strings.TrimSpace(strings.Replace(title, match, "", 1))
+		}
+	}
+
+	notes := text
+	if notes == link || strings.EqualFold(notes, title) {
+		notes = ""
+	}
+	return link, notes
+}
+
+// findSharedURL returns the first http(s) link in s without trailing punctuation,
+// along with the text it was found in
+func findSharedURL(s string) (string, string) {
+	match := sharedURLPattern.FindString(s)
+	found := strings.TrimRight(match, ".,;:!?)]}'")
+	if !isHTTPURL(found) {
+		return "", ""
+	}
+	return found, match
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// requestOrigin returns the scheme and host the request was made to, honoring a TLS-terminating proxy
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// bookmarkletScript opens the quick-capture popup for the current page, passing any
+// selected text along as notes
+func bookmarkletScript(origin string) string {
+	return fmt.Sprintf("javascript:(function(){"+
+		"var q='url='+encodeURIComponent(location.href)"+
+		"+'&title='+encodeURIComponent(document.title)"+
+		"+'&text='+encodeURIComponent(String(window.getSelection()));"+
+		"window.open(%q+'/share?'+q,'learnd','width=480,height=520');"+
+		"})();", origin)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestSharedLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		title     string
+		text      string
+		url       string
+		wantLink  string
+		wantNotes string
+	}{
+		{
+			name:     "url param",
+			title:    "Post",
+			url:      "https://example.com/post",
+			wantLink: "https://example.com/post",
+		},
+		{
+			name:      "url param with selected text",
+			url:       "https://example.com/post",
+			text:      "a quote worth keeping",
+			wantLink:  "https://example.com/post",
+			wantNotes: "a quote worth keeping",
+		},
+		{
+			name:     "link inside text",
+			title:    "Great read",
+			text:     "Great read https://example.com/a?b=c.",
+			wantLink: "https://example.com/a?b=c",
+		},
+		{
+			name:      "link inside text with comment",
+			text:      "Check this out: https://example.com/x",
+			wantLink:  "https://example.com/x",
+			wantNotes: "Check this out:",
+		},
+		{
+			name:     "link in title",
+			title:    "https://example.com/t",
+			wantLink: "https://example.com/t",
+		},
+		{
+			name:     "text is the link",
+			text:     "https://example.com/only",
+			wantLink: "https://example.com/only",
+		},
+		{
+			name:      "non-http url falls back to text",
+			url:       "content://shared/123",
+			text:      "see https://example.com/y",
+			wantLink:  "https://example.com/y",
+			wantNotes: "see",
+		},
+		{
+			name:      "no link",
+			text:      "just words",
+			wantNotes: "just words",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, notes := sharedLink(tt.title, tt.text, tt.url)
+			if link != tt.wantLink {
+				t.Errorf("link = %q, want %q", link, tt.wantLink)
+			}
+			if notes != tt.wantNotes {
+				t.Errorf("notes = %q, want %q", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestShareAutosave(t *testing.T) {
+	h := NewShareHandler(nil, "")
+
+	share := func(target, csrfToken string) string {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		// The bookmarklet opens the popup from the page being captured
+		req.Header.Set("Sec-Fetch-Site", "cross-site")
+		req = req.WithContext(auth.WithCSRFToken(req.Context(), csrfToken))
+		rec := httptest.NewRecorder()
+		h.Share(rec, req)
+		return rec.Body.String()
+	}
+	const autosave = "htmx.trigger(document.getElementById('quick-capture'), 'submit')"
+
+	body := share("/share?url=https://example.com/z", "csrf-token")
+	if !strings.Contains(body, `value="https://example.com/z"`) || !strings.Contains(body, autosave) {
+		t.Errorf("expected a prefilled, autosaving form, got %s", body)
+	}
+	// The form posts itself with the session's CSRF token, like any other htmx form
+	if !strings.Contains(html.UnescapeString(body), `"X-CSRF-Token":"csrf-token"`) {
+		t.Error("expected the popup to send the session's CSRF token")
+	}
+
+	if strings.Contains(share("/share?text=no+link+here", "csrf-token"), autosave) {
+		t.Error("expected no autosave without a link")
+	}
+	if body := share("/share?url=https://example.com/z", ""); strings.Contains(body, autosave) {
+		t.Error("expected no autosave without a browser session")
+	}
+}
+
+func TestBookmarklet(t *testing.T) {
+	h := NewShareHandler(nil, "")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/bookmarklet", nil)
+	req.Host = "learnd.example.com"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	rec := httptest.NewRecorder()
+	h.Bookmarklet(rec, req)
+
+	script := rec.Body.String()
+	if !strings.HasPrefix(script, "javascript:") {
+		t.Errorf("script = %q, want a javascript: URL", script)
+	}
+	if !strings.Contains(script, `"https://learnd.example.com"+'/share?'`) {
+		t.Errorf("script = %q, want the request origin", script)
+	}
+}
+
+type mockMailKeys struct {
+	keys map[uuid.UUID]string
+}
+
+func (m *mockMailKeys) SetMailKey(ctx context.Context, id uuid.UUID, key string) (bool, error) {
+	m.keys[id] = key
+	return true, nil
+}
+
+func TestMailAddress(t *testing.T) {
+	keys := &mockMailKeys{keys: map[uuid.UUID]string{}}
+	h := NewShareHandler(keys, "save@learnd.example.com")
+	user := &model.User{ID: uuid.New()}
+
+	request := func(method, target string, session *model.Session) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		ctx := auth.WithUser(req.Context(), user)
+		if session != nil {
+			ctx = auth.WithSession(ctx, session)
+		}
+		rec := httptest.NewRecorder()
+		if method == http.MethodPost {
+			h.NewMailAddress(rec, req.WithContext(ctx))
+		} else {
+			h.BookmarkletPage(rec, req.WithContext(ctx))
+		}
+		return rec
+	}
+
+	rec := request(http.MethodPost, "/api/mail-address", &model.Session{ID: uuid.New()})
+	key := keys.keys[user.ID]
+	if rec.Code != http.StatusOK || key == "" {
+		t.Fatalf("status = %d, key = %q, want a new key", rec.Code, key)
+	}
+	address := "save+" + key + "@learnd.example.com"
+	if !strings.Contains(rec.Body.String(), address) {
+		t.Errorf("body = %s, want %s", rec.Body.String(), address)
+	}
+
+	// The address is shown to the browser, but not to a token that can only read
+	user.MailKey = &key
+	if body := request(http.MethodGet, "/capture-tools", &model.Session{ID: uuid.New()}).Body.String(); !strings.Contains(body, address) {
+		t.Errorf("expected the address on the page, got %s", body)
+	}
+	if body := request(http.MethodGet, "/capture-tools", nil).Body.String(); strings.Contains(body, key) {
+		t.Error("expected no address without a browser session")
+	}
+}
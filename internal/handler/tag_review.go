@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/google/uuid"
+)
+
+// tagReviewLimit is the number of suggestions shown in the review queue at once
+const tagReviewLimit = 100
+
+// TagReviewRepo defines the repository operations used by the tag review queue
+type TagReviewRepo interface {
+	ListSuggestedTags(ctx context.Context, userID uuid.UUID, limit int) ([]model.Entry, error)
+	ConfirmTags(ctx context.Context, userID uuid.UUID, tags map[uuid.UUID]string) (int64, error)
+	RejectTags(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error)
+}
+
+// TagReviewHandler handles bulk review of auto-suggested tags
+type TagReviewHandler struct {
+	entryRepo TagReviewRepo
+}
+
+// NewTagReviewHandler creates a new TagReviewHandler
+func NewTagReviewHandler(entryRepo TagReviewRepo) *TagReviewHandler {
+	return &TagReviewHandler{
+		entryRepo: entryRepo,
+	}
+}
+
+// ReviewPage renders the queue of suggested tags
+func (h *TagReviewHandler) ReviewPage(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.entryRepo.ListSuggestedTags(r.Context(), auth.UserID(r.Context()), tagReviewLimit)
+	if err != nil {
+		slog.Error("failed to list suggested tags", "handler", "ReviewPage", "error", err)
+		http.Error(w, "Failed to load suggested tags", http.StatusInternalServerError)
+		return
+	}
+
+	pages.TagReviewPage(entries).Render(r.Context(), w)
+}
+
+// Review accepts or rejects the selected suggestions. Accepted entries take the
+// tag from their row's input, so suggestions can be corrected before accepting.
+func (h *TagReviewHandler) Review(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, idStr := range r.Form["ids"] {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			http.Error(w, "Invalid ID", http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		htmxError(w, "Select at least one entry")
+		return
+	}
+
+	var message string
+	switch action := r.FormValue("action"); action {
+	case "accept":
+		tags := make(map[uuid.UUID]string, len(ids))
+		for _, id := range ids {
+			tag, err := parseTag(r.FormValue("tag-" + id.String()))
+			if err != nil {
+				htmxError(w, err.Error())
+				return
+			}
+			if tag == nil {
+				htmxError(w, "Every accepted entry needs a tag")
+				return
+			}
+			tags[id] = *tag
+		}
+
+		count, err := h.entryRepo.ConfirmTags(ctx, auth.UserID(ctx), tags)
+		if err != nil {
+			slog.Error("failed to confirm tags", "handler", "Review", "error", err)
+			htmxError(w, "Failed to accept tags")
+			return
+		}
+		message = fmt.Sprintf("Accepted %d tags", count)
+	case "reject":
+		count, err := h.entryRepo.RejectTags(ctx, auth.UserID(ctx), ids)
+		if err != nil {
+			slog.Error("failed to reject tags", "handler", "Review", "error", err)
+			htmxError(w, "Failed to reject tags")
+			return
+		}
+		message = fmt.Sprintf("Rejected %d tags", count)
+	default:
+		http.Error(w, "Invalid action", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.entryRepo.ListSuggestedTags(ctx, auth.UserID(ctx), tagReviewLimit)
+	if err != nil {
+		slog.Error("failed to list suggested tags", "handler", "Review", "error", err)
+		htmxError(w, "Failed to reload suggested tags")
+		return
+	}
+
+	htmxToast(w, message, nil, "")
+	partials.TagReviewPanel(entries).Render(ctx, w)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type mockTagReviewRepo struct {
+	entries   []model.Entry
+	confirmed map[uuid.UUID]string
+	rejected  []uuid.UUID
+	err       error
+}
+
+func (m *mockTagReviewRepo) ListSuggestedTags(ctx context.Context, userID uuid.UUID, limit int) ([]model.Entry, error) {
+	return m.entries, nil
+}
+
+func (m *mockTagReviewRepo) ConfirmTags(ctx context.Context, userID uuid.UUID, tags map[uuid.UUID]string) (int64, error) {
+	m.confirmed = tags
+	return int64(len(tags)), m.err
+}
+
+func (m *mockTagReviewRepo) RejectTags(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	m.rejected = ids
+	return int64(len(ids)), m.err
+}
+
+func TestTagReview(t *testing.T) {
+	id1 := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
+	id2 := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+
+	tests := []struct {
+		name           string
+		form           url.Values
+		repoErr        error
+		expectedStatus int
+		wantConfirmed  map[uuid.UUID]string
+		wantRejected   int
+	}{
+		{
+			name: "accept with edited tag",
+			form: url.Values{
+				"action":              {"accept"},
+				"ids":                 {id1.String(), id2.String()},
+				"tag-" + id1.String(): {"go"},
+				"tag-" + id2.String(): {" Databases "},
+			},
+			expectedStatus: http.StatusOK,
+			wantConfirmed:  map[uuid.UUID]string{id1: "go", id2: "databases"},
+		},
+		{
+			name: "accept without tag",
+			form: url.Values{
+				"action":              {"accept"},
+				"ids":                 {id1.String()},
+				"tag-" + id1.String(): {""},
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "reject",
+			form:           url.Values{"action": {"reject"}, "ids": {id1.String(), id2.String()}},
+			expectedStatus: http.StatusOK,
+			wantRejected:   2,
+		},
+		{
+			name:           "nothing selected",
+			form:           url.Values{"action": {"reject"}},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "invalid id",
+			form:           url.Values{"action": {"reject"}, "ids": {"not-a-uuid"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid action",
+			form:           url.Values{"action": {"delete"}, "ids": {id1.String()}},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "repository error",
+			form:           url.Values{"action": {"reject"}, "ids": {id1.String()}},
+			repoErr:        errors.New("db down"),
+			expectedStatus: http.StatusUnprocessableEntity,
+			wantRejected:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockTagReviewRepo{err: tt.repoErr}
+			handler := NewTagReviewHandler(repo)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/tags/review", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+
+			handler.Review(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Review() status = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+			if len(repo.confirmed) != len(tt.wantConfirmed) {
+				t.Errorf("Review() confirmed = %v, want %v", repo.confirmed, tt.wantConfirmed)
+			}
+			for id, tag := range tt.wantConfirmed {
+				if repo.confirmed[id] != tag {
+					t.Errorf("Review() confirmed %s as %q, want %q", id, repo.confirmed[id], tag)
+				}
+			}
+			if len(repo.rejected) != tt.wantRejected {
+				t.Errorf("Review() rejected %d entries, want %d", len(repo.rejected), tt.wantRejected)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/ui"
+	"github.com/drywaters/learnd/internal/ui/pages"
+	"github.com/drywaters/learnd/internal/ui/partials"
+	"github.com/google/uuid"
+)
+
+// SecondFactorRepo checks off authenticator and recovery codes as they're used
+type SecondFactorRepo interface {
+	AdvanceTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error)
+}
+
+// TOTPRepo defines the repository operations for managing two-factor sign-in
+type TOTPRepo interface {
+	SecondFactorRepo
+	EnableTOTP(ctx context.Context, id uuid.UUID, secret string, lastStep int64, codeHashes []string) error
+	DisableTOTP(ctx context.Context, id uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, id uuid.UUID, codeHashes []string) error
+	CountRecoveryCodes(ctx context.Context, id uuid.UUID) (int, error)
+}
+
+// TOTPHandler lets the signed-in user turn two-factor sign-in on and off
+type TOTPHandler struct {
+	users TOTPRepo
+}
+
+// NewTOTPHandler creates a new TOTPHandler
+func NewTOTPHandler(users TOTPRepo) *TOTPHandler {
+	return &TOTPHandler{
+		users: users,
+	}
+}
+
+// SecurityPage renders the two-factor status, or a fresh secret to enrol with
+func (h *TOTPHandler) SecurityPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	view, err := h.view(ctx, auth.UserFromContext(ctx), nil)
+	if err != nil {
+		slog.Error("failed to load two-factor settings", "handler", "SecurityPage", "error", err)
+		http.Error(w, "Failed to load two-factor settings", http.StatusInternalServerError)
+		return
+	}
+
+	pages.TwoFactorPage(view).Render(ctx, w)
+}
+
+// Enable turns on two-factor sign-in once the user proves their authenticator has
+// the secret, and shows their recovery codes
+func (h *TOTPHandler) Enable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		htmxError(w, "Invalid form")
+		return
+	}
+	if user.TOTPEnabled() {
+		htmxError(w, "Two-factor sign-in is already on")
+		return
+	}
+
+	secret := strings.TrimSpace(r.FormValue("secret"))
+	if _, err := auth.TOTPCode(secret, 0); err != nil || secret == "" {
+		htmxError(w, "Invalid secret, reload the page and try again")
+		return
+	}
+	step, ok := auth.VerifyTOTP(secret, r.FormValue("code"), time.Now())
+	if !ok {
+		htmxError(w, "That code doesn't match. Check the time on your device and try again.")
+		return
+	}
+
+	codes, hashes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		slog.Error("failed to generate recovery codes", "handler", "Enable", "error", err)
+		htmxError(w, "Failed to turn on two-factor sign-in")
+		return
+	}
+	if err := h.users.EnableTOTP(ctx, user.ID, secret, step, hashes); err != nil {
+		slog.Error("failed to enable totp", "handler", "Enable", "error", err)
+		htmxError(w, "Failed to turn on two-factor sign-in")
+		return
+	}
+
+	enabled := *user
+	enabled.TOTPSecret = &secret
+	htmxToast(w, "Two-factor sign-in turned on", nil, "")
+	h.render(w, r, "Enable", &enabled, codes)
+}
+
+// Disable turns off two-factor sign-in after checking a current code
+func (h *TOTPHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		htmxError(w, "Invalid form")
+		return
+	}
+	if user.TOTPRequired {
+		htmxError(w, "Two-factor sign-in is required for your account")
+		return
+	}
+	if !h.checkCode(w, r, "Disable", user) {
+		return
+	}
+
+	if err := h.users.DisableTOTP(ctx, user.ID); err != nil {
+		slog.Error("failed to disable totp", "handler", "Disable", "error", err)
+		htmxError(w, "Failed to turn off two-factor sign-in")
+		return
+	}
+
+	disabled := *user
+	disabled.TOTPSecret = nil
+	htmxToast(w, "Two-factor sign-in turned off", nil, "")
+	h.render(w, r, "Disable", &disabled, nil)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking a current code
+func (h *TOTPHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := auth.UserFromContext(ctx)
+
+	if err := r.ParseForm(); err != nil {
+		htmxError(w, "Invalid form")
+		return
+	}
+	if !user.TOTPEnabled() {
+		htmxError(w, "Two-factor sign-in is off")
+		return
+	}
+	if !h.checkCode(w, r, "RegenerateRecoveryCodes", user) {
+		return
+	}
+
+	codes, hashes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		slog.Error("failed to generate recovery codes", "handler", "RegenerateRecoveryCodes", "error", err)
+		htmxError(w, "Failed to generate recovery codes")
+		return
+	}
+	if err := h.users.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		slog.Error("failed to replace recovery codes", "handler", "RegenerateRecoveryCodes", "error", err)
+		htmxError(w, "Failed to generate recovery codes")
+		return
+	}
+
+	htmxToast(w, "New recovery codes generated", nil, "")
+	h.render(w, r, "RegenerateRecoveryCodes", user, codes)
+}
+
+// checkCode verifies the submitted second factor, writing an error and returning
+// false if it's wrong
+func (h *TOTPHandler) checkCode(w http.ResponseWriter, r *http.Request, handlerName string, user *model.User) bool {
+	ok, err := verifySecondFactor(r.Context(), h.users, user, r.FormValue("code"))
+	if err != nil {
+		slog.Error("failed to verify second factor", "handler", handlerName, "error", err)
+		htmxError(w, "Failed to verify code")
+		return false
+	}
+	if !ok {
+		htmxError(w, "Invalid code")
+		return false
+	}
+	return true
+}
+
+func (h *TOTPHandler) render(w http.ResponseWriter, r *http.Request, handlerName string, user *model.User, newCodes []string) {
+	ctx := r.Context()
+
+	view, err := h.view(ctx, user, newCodes)
+	if err != nil {
+		slog.Error("failed to load two-factor settings", "handler", handlerName, "error", err)
+		htmxError(w, "Failed to reload two-factor settings")
+		return
+	}
+
+	partials.TwoFactor(view).Render(ctx, w)
+}
+
+// view builds the settings state for user, generating a new secret to enrol with
+// when two-factor sign-in is off
+func (h *TOTPHandler) view(ctx context.Context, user *model.User, newCodes []string) (ui.TwoFactorView, error) {
+	view := ui.TwoFactorView{
+		Enabled:          user.TOTPEnabled(),
+		Required:         user.TOTPRequired,
+		NewRecoveryCodes: newCodes,
+	}
+
+	if view.Enabled {
+		count, err := h.users.CountRecoveryCodes(ctx, user.ID)
+		if err != nil {
+			return view, err
+		}
+		view.RecoveryCodesLeft = count
+		return view, nil
+	}
+
+	secret, err := auth.NewTOTPSecret()
+	if err != nil {
+		return view, err
+	}
+	qrCode, err := auth.TOTPQRCode(auth.TOTPURL(user.Email, secret))
+	if err != nil {
+		return view, err
+	}
+	view.Secret = secret
+	view.QRCode = qrCode
+	return view, nil
+}
+
+// verifySecondFactor checks code as an authenticator code, then as a recovery code.
+// Each code is accepted only once.
+func verifySecondFactor(ctx context.Context, users SecondFactorRepo, user *model.User, code string) (bool, error) {
+	if !user.TOTPEnabled() {
+		return false, nil
+	}
+
+	if step, ok := auth.VerifyTOTP(*user.TOTPSecret, code, time.Now()); ok {
+		return users.AdvanceTOTPStep(ctx, user.ID, step)
+	}
+
+	if strings.TrimSpace(code) == "" {
+		return false, nil
+	}
+	return users.UseRecoveryCode(ctx, user.ID, auth.HashRecoveryCode(code))
+}
@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func (m *mockUserRepo) EnableTOTP(ctx context.Context, id uuid.UUID, secret string, lastStep int64, codeHashes []string) error {
+	user, _ := m.GetByID(ctx, id)
+	user.TOTPSecret = &secret
+	user.TOTPLastStep = lastStep
+	return m.ReplaceRecoveryCodes(ctx, id, codeHashes)
+}
+
+func (m *mockUserRepo) DisableTOTP(ctx context.Context, id uuid.UUID) error {
+	user, _ := m.GetByID(ctx, id)
+	user.TOTPSecret = nil
+	m.recoveryCodes = nil
+	return nil
+}
+
+func (m *mockUserRepo) ReplaceRecoveryCodes(ctx context.Context, id uuid.UUID, codeHashes []string) error {
+	m.recoveryCodes = make(map[string]bool)
+	for _, hash := range codeHashes {
+		m.recoveryCodes[hash] = true
+	}
+	return nil
+}
+
+func (m *mockUserRepo) CountRecoveryCodes(ctx context.Context, id uuid.UUID) (int, error) {
+	count := 0
+	for _, unused := range m.recoveryCodes {
+		if unused {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func currentTOTPCode(t *testing.T) string {
+	t.Helper()
+	code, err := auth.TOTPCode(testTOTPSecret, auth.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func newTwoFactorUser(t *testing.T) (*model.User, *mockUserRepo) {
+	t.Helper()
+	hash, err := auth.HashPassword("hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := testTOTPSecret
+	user := &model.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: &hash, TOTPSecret: &secret}
+	users := &mockUserRepo{
+		users:         []*model.User{user},
+		recoveryCodes: map[string]bool{auth.HashRecoveryCode("abcd-efgh-ijkl-mnop"): true},
+	}
+	return user, users
+}
+
+// verifyCode posts a code to the two-factor page with the pending sign-in cookie
+func verifyCode(h *AuthHandler, token, code string) *httptest.ResponseRecorder {
+	form := url.Values{"code": {code}, "redirect": {"/report"}}
+	req := httptest.NewRequest(http.MethodPost, "/login/2fa", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: auth.TwoFactorCookieName, Value: token})
+	rec := httptest.NewRecorder()
+	h.VerifyTwoFactor(rec, req)
+	return rec
+}
+
+// startTwoFactorLogin signs in with the password and returns the pending sign-in token
+func startTwoFactorLogin(t *testing.T, h *AuthHandler, sessions *mockSessionRepo) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.Login(rec, newLoginRequest(url.Values{"email": {"alice@example.com"}, "password": {"hunter2"}, "redirect": {"/report"}}))
+
+	if got := rec.Header().Get("Location"); got != "/login/2fa?redirect=%2Freport" {
+		t.Fatalf("Location = %q, want the two-factor page", got)
+	}
+	if len(sessions.sessions) != 0 || len(sessions.pending) != 1 {
+		t.Fatalf("got %d sessions and %d pending, want only a pending one", len(sessions.sessions), len(sessions.pending))
+	}
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == auth.SessionCookieName {
+			t.Fatal("session cookie set before the second factor")
+		}
+		if c.Name == auth.TwoFactorCookieName {
+			return c.Value
+		}
+	}
+	t.Fatal("expected a two-factor cookie")
+	return ""
+}
+
+func TestLoginTwoFactor(t *testing.T) {
+	user, users := newTwoFactorUser(t)
+	sessions := newMockSessionRepo()
+	h := NewAuthHandler("", false, time.Hour, users, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	token := startTwoFactorLogin(t, h, sessions)
+
+	rec := verifyCode(h, token, "000000")
+	if got := rec.Header().Get("Location"); got != "/login/2fa?error=invalid_code&redirect=%2Freport" {
+		t.Errorf("wrong code Location = %q", got)
+	}
+	if len(sessions.sessions) != 0 {
+		t.Fatal("wrong code signed in")
+	}
+
+	code := currentTOTPCode(t)
+	rec = verifyCode(h, token, code)
+	if got := rec.Header().Get("Location"); got != "/report" {
+		t.Fatalf("Location = %q, want /report", got)
+	}
+	session := sessions.sessions[auth.HashToken(token)]
+	if session == nil || session.UserID != user.ID || len(sessions.pending) != 0 {
+		t.Fatalf("session = %+v, pending = %d; want a completed session", session, len(sessions.pending))
+	}
+
+	var sessionCookie string
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == auth.SessionCookieName {
+			sessionCookie = c.Value
+		}
+	}
+	if sessionCookie != token {
+		t.Errorf("session cookie = %q, want the verified token", sessionCookie)
+	}
+
+	// The same code can't sign in a second browser
+	sessions = newMockSessionRepo()
+	h = NewAuthHandler("", false, time.Hour, users, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+	token = startTwoFactorLogin(t, h, sessions)
+	rec = verifyCode(h, token, code)
+	if got := rec.Header().Get("Location"); !strings.Contains(got, "error=invalid_code") {
+		t.Errorf("replayed code Location = %q, want invalid code", got)
+	}
+}
+
+func TestLoginTwoFactorRecoveryCode(t *testing.T) {
+	_, users := newTwoFactorUser(t)
+	sessions := newMockSessionRepo()
+	h := NewAuthHandler("", false, time.Hour, users, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+
+	token := startTwoFactorLogin(t, h, sessions)
+	if got := verifyCode(h, token, "ABCD EFGH IJKL MNOP").Header().Get("Location"); got != "/report" {
+		t.Fatalf("Location = %q, want /report", got)
+	}
+
+	// Recovery codes work once
+	sessions = newMockSessionRepo()
+	h = NewAuthHandler("", false, time.Hour, users, sessions, &mockAuthEvents{}, auth.NewLimiter(auth.LimiterConfig{}), nil)
+	token = startTwoFactorLogin(t, h, sessions)
+	if got := verifyCode(h, token, "abcd-efgh-ijkl-mnop").Header().Get("Location"); !strings.Contains(got, "error=invalid_code") {
+		t.Errorf("reused recovery code Location = %q, want invalid code", got)
+	}
+}
+
+func TestLoginTwoFactorTooManyAttempts(t *testing.T) {
+	_, users := newTwoFactorUser(t)
+	sessions := newMockSessionRepo()
+	// Leave room under the address lockout so the per-sign-in limit is what ends it
+	limiter := auth.NewLimiter(auth.LimiterConfig{MaxFailures: maxTwoFactorAttempts + 1})
+	h := NewAuthHandler("", false, time.Hour, users, sessions, &mockAuthEvents{}, limiter, nil)
+
+	token := startTwoFactorLogin(t, h, sessions)
+	var rec *httptest.ResponseRecorder
+	for range maxTwoFactorAttempts {
+		rec = verifyCode(h, token, "000000")
+	}
+
+	if got := rec.Header().Get("Location"); got != "/login?error=too_many_attempts" {
+		t.Errorf("Location = %q, want too many attempts", got)
+	}
+	if len(sessions.pending) != 0 {
+		t.Error("expected the pending sign-in to be deleted")
+	}
+	if got := verifyCode(h, token, currentTOTPCode(t)).Header().Get("Location"); got != "/login?error=two_factor_expired" {
+		t.Errorf("Location after lockout = %q, want expired", got)
+	}
+}
+
+func TestLoginTwoFactorLockedOut(t *testing.T) {
+	_, users := newTwoFactorUser(t)
+	sessions := newMockSessionRepo()
+	limiter := auth.NewLimiter(auth.LimiterConfig{MaxFailures: 1})
+	h := NewAuthHandler("", false, time.Hour, users, sessions, &mockAuthEvents{}, limiter, nil)
+
+	token := startTwoFactorLogin(t, h, sessions)
+	limiter.Fail("192.0.2.1")
+
+	// Even the right code is refused once the address is locked out
+	if got := verifyCode(h, token, currentTOTPCode(t)).Header().Get("Location"); got != "/login?error=locked_out" {
+		t.Errorf("Location = %q, want locked out", got)
+	}
+	if len(sessions.sessions) != 0 || len(sessions.pending) != 0 {
+		t.Error("expected no session and the pending sign-in to be deleted")
+	}
+}
+
+func TestTOTPEnable(t *testing.T) {
+	user := &model.User{ID: uuid.New(), Email: "alice@example.com"}
+	users := &mockUserRepo{users: []*model.User{user}}
+	h := NewTOTPHandler(users)
+
+	post := func(code string) *httptest.ResponseRecorder {
+		form := url.Values{"secret": {testTOTPSecret}, "code": {code}}
+		req := httptest.NewRequest(http.MethodPost, "/api/totp", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.Enable(rec, withSession(req, user, &model.Session{ID: uuid.New(), UserID: user.ID}))
+		return rec
+	}
+
+	if rec := post("000000"); rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("wrong code status = %d, want 422", rec.Code)
+	}
+	if user.TOTPEnabled() {
+		t.Fatal("enabled with a wrong code")
+	}
+
+	step := auth.TOTPStep(time.Now())
+	code, _ := auth.TOTPCode(testTOTPSecret, step)
+	rec := post(code)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+	if !user.TOTPEnabled() || user.TOTPLastStep != step {
+		t.Errorf("user = %+v, want two-factor on with the code's step used", user)
+	}
+	if n, _ := users.CountRecoveryCodes(context.Background(), user.ID); n != 10 {
+		t.Errorf("got %d recovery codes, want 10", n)
+	}
+	if strings.Count(rec.Body.String(), "<li>") != 10 {
+		t.Error("expected the recovery codes to be shown")
+	}
+}
+
+func TestTOTPDisable(t *testing.T) {
+	tests := []struct {
+		name        string
+		required    bool
+		code        string
+		wantStatus  int
+		wantEnabled bool
+	}{
+		{name: "recovery code", code: "abcd-efgh-ijkl-mnop", wantStatus: http.StatusOK},
+		{name: "wrong code", code: "nope", wantStatus: http.StatusUnprocessableEntity, wantEnabled: true},
+		{name: "required", required: true, code: "abcd-efgh-ijkl-mnop", wantStatus: http.StatusUnprocessableEntity, wantEnabled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, users := newTwoFactorUser(t)
+			user.TOTPRequired = tt.required
+			h := NewTOTPHandler(users)
+
+			form := url.Values{"code": {tt.code}}
+			req := httptest.NewRequest(http.MethodPost, "/api/totp/disable", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			rec := httptest.NewRecorder()
+			h.Disable(rec, withSession(req, user, &model.Session{ID: uuid.New(), UserID: user.ID}))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if user.TOTPEnabled() != tt.wantEnabled {
+				t.Errorf("enabled = %v, want %v", user.TOTPEnabled(), tt.wantEnabled)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// csvRows reads a CSV export with a header row, returning each row keyed by lowercased column name
+func csvRows(r io.Reader) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+	}
+
+	var rows []map[string]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+
+		row := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// parseRaindropCSV reads Raindrop.io's CSV export
+// (id, title, note, excerpt, url, folder, tags, created, ...)
+func parseRaindropCSV(r io.Reader) ([]Bookmark, error) {
+	rows, err := csvRows(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks []Bookmark
+	for _, row := range rows {
+		url := strings.TrimSpace(row["url"])
+		if url == "" {
+			continue
+		}
+		b := Bookmark{
+			URL:   url,
+			Title: strings.TrimSpace(row["title"]),
+			Tag:   chooseTag(splitTags(row["tags"]), lastFolder(row["folder"])),
+			Notes: strings.TrimSpace(row["note"]),
+		}
+		if created, err := time.Parse(time.RFC3339, strings.TrimSpace(row["created"])); err == nil {
+			b.SavedAt = created.UTC()
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, nil
+}
+
+// parseInstapaperCSV reads Instapaper's CSV export (URL, Title, Selection, Folder, Timestamp, and Tags in newer exports)
+func parseInstapaperCSV(r io.Reader) ([]Bookmark, error) {
+	rows, err := csvRows(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks []Bookmark
+	for _, row := range rows {
+		url := strings.TrimSpace(row["url"])
+		if url == "" {
+			continue
+		}
+		bookmarks = append(bookmarks, Bookmark{
+			URL:     url,
+			Title:   strings.TrimSpace(row["title"]),
+			Tag:     chooseTag(splitTags(row["tags"]), lastFolder(row["folder"])),
+			Notes:   strings.TrimSpace(row["selection"]),
+			SavedAt: parseUnixTime(row["timestamp"]),
+		})
+	}
+	return bookmarks, nil
+}
+
+// lastFolder returns the innermost folder of a nested path like "Dev / Go"
+func lastFolder(path string) string {
+	parts := strings.Split(path, "/")
+	return strings.TrimSpace(parts[len(parts)-1])
+}
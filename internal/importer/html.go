@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// parseBookmarksHTML reads the Netscape bookmark format used by browser exports and
+// Pocket. Folders are <H3> headings followed by a nested <DL>; links carry ADD_DATE
+// (or Pocket's time_added) and an optional TAGS attribute.
+func parseBookmarksHTML(r io.Reader) ([]Bookmark, error) {
+	z := html.NewTokenizer(r)
+
+	var bookmarks []Bookmark
+	var folders []string
+	var pendingFolder string
+	var current *Bookmark
+	var inFolderName bool
+	var text strings.Builder
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return bookmarks, nil
+			}
+			return nil, fmt.Errorf("failed to parse bookmarks: %w", z.Err())
+
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "h3":
+				inFolderName = true
+				text.Reset()
+			case "dl", "ul":
+				folders = append(folders, pendingFolder)
+				pendingFolder = ""
+			case "a":
+				b := Bookmark{}
+				var tags []string
+				for {
+					key, val, more := z.TagAttr()
+					switch string(key) {
+					case "href":
+						b.URL = strings.TrimSpace(string(val))
+					case "add_date", "time_added":
+						b.SavedAt = parseUnixTime(string(val))
+					case "tags":
+						tags = splitTags(string(val))
+					}
+					if !more {
+						break
+					}
+				}
+				folder := ""
+				if len(folders) > 0 {
+					folder = folders[len(folders)-1]
+				}
+				b.Tag = chooseTag(tags, folder)
+				current = &b
+				text.Reset()
+			}
+
+		case html.TextToken:
+			if inFolderName || current != nil {
+				text.Write(z.Text())
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "h3":
+				inFolderName = false
+				pendingFolder = strings.TrimSpace(text.String())
+			case "dl", "ul":
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case "a":
+				if current != nil {
+					current.Title = strings.TrimSpace(text.String())
+					if current.URL != "" {
+						bookmarks = append(bookmarks, *current)
+					}
+					current = nil
+				}
+			}
+		}
+	}
+}
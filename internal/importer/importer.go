@@ -0,0 +1,158 @@
+// Package importer parses saved-link exports from browsers and read-later services.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported export formats
+const (
+	FormatAuto       = "auto"
+	FormatBookmarks  = "bookmarks"
+	FormatRaindrop   = "raindrop"
+	FormatInstapaper = "instapaper"
+)
+
+// Formats lists the formats offered on the import page, in order
+var Formats = []string{FormatAuto, FormatBookmarks, FormatRaindrop, FormatInstapaper}
+
+// Bookmark is a saved link read from an export
+type Bookmark struct {
+	URL   string
+	Title string
+	Tag   string
+	Notes string
+	// SavedAt is zero when the export has no save date
+	SavedAt time.Time
+}
+
+// Parse reads bookmarks in the given format. Netscape bookmark HTML covers browser
+// exports and Pocket's export; FormatAuto detects the format from the content.
+func Parse(format string, r io.Reader) ([]Bookmark, error) {
+	br := bufio.NewReader(r)
+
+	if format == FormatAuto || format == "" {
+		detected, err := detect(br)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+
+	switch format {
+	case FormatBookmarks:
+		return parseBookmarksHTML(br)
+	case FormatRaindrop:
+		return parseRaindropCSV(br)
+	case FormatInstapaper:
+		return parseInstapaperCSV(br)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// detect guesses the format from the start of the file without consuming it
+func detect(br *bufio.Reader) (string, error) {
+	head, err := br.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return "", fmt.Errorf("failed to read import: %w", err)
+	}
+
+	head = bytes.TrimPrefix(bytes.TrimSpace(head), []byte("\xef\xbb\xbf"))
+	if len(head) == 0 {
+		return "", fmt.Errorf("empty import file")
+	}
+	if head[0] == '<' {
+		return FormatBookmarks, nil
+	}
+
+	firstLine := strings.ToLower(string(head))
+	if i := strings.IndexByte(firstLine, '\n'); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	switch {
+	case strings.Contains(firstLine, "selection") && strings.Contains(firstLine, "timestamp"):
+		return FormatInstapaper, nil
+	case strings.Contains(firstLine, "url") && strings.Contains(firstLine, "created"):
+		return FormatRaindrop, nil
+	}
+	return "", fmt.Errorf("unrecognized import format")
+}
+
+// Folders that every export has, which say nothing about a link's topic
+var genericFolders = map[string]bool{
+	"bookmarks":         true,
+	"bookmarks bar":     true,
+	"bookmarks toolbar": true,
+	"bookmarks menu":    true,
+	"favorites bar":     true,
+	"other bookmarks":   true,
+	"mobile bookmarks":  true,
+	"unsorted":          true,
+	"unread":            true,
+	"archive":           true,
+	"starred":           true,
+}
+
+var tagInvalidChars = regexp.MustCompile(`[^a-z0-9-]+`)
+var tagRepeatedHyphens = regexp.MustCompile(`-{2,}`)
+
+// NormalizeTag turns a folder or tag name into a valid learnd tag, e.g. "Machine Learning" -> "machine-learning".
+// Returns "" for names that normalize to nothing.
+func NormalizeTag(name string) string {
+	tag := strings.ToLower(strings.TrimSpace(name))
+	tag = strings.NewReplacer(" ", "-", "_", "-", "/", "-", ".", "-").Replace(tag)
+	tag = tagInvalidChars.ReplaceAllString(tag, "")
+	tag = tagRepeatedHyphens.ReplaceAllString(tag, "-")
+	return strings.Trim(tag, "-")
+}
+
+// chooseTag picks the first usable tag, falling back to the folder
+func chooseTag(tags []string, folder string) string {
+	for _, t := range tags {
+		if tag := NormalizeTag(t); tag != "" {
+			return tag
+		}
+	}
+	if genericFolders[strings.ToLower(strings.TrimSpace(folder))] {
+		return ""
+	}
+	return NormalizeTag(folder)
+}
+
+// splitTags splits a comma-separated tag list, also accepting a JSON-style ["a","b"] list
+func splitTags(s string) []string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	var tags []string
+	for _, t := range strings.Split(s, ",") {
+		t = strings.Trim(strings.TrimSpace(t), `"`)
+		if t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// parseUnixTime parses a Unix timestamp in seconds, milliseconds or microseconds
+func parseUnixTime(s string) time.Time {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}
+	}
+	switch {
+	case n > 1e15:
+		return time.UnixMicro(n).UTC()
+	case n > 1e12:
+		return time.UnixMilli(n).UTC()
+	default:
+		return time.Unix(n, 0).UTC()
+	}
+}
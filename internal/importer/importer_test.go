@@ -0,0 +1,148 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const bookmarksHTML = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1700000000" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://go.dev/blog/" ADD_DATE="1700000100">The Go Blog</A>
+        <DT><H3>Machine Learning</H3>
+        <DL><p>
+            <DT><A HREF="https://example.com/attention" ADD_DATE="1700000200000">Attention Is All You Need</A>
+        </DL><p>
+        <DT><A HREF="https://example.com/tagged" ADD_DATE="1700000300" TAGS="Rust,systems">Tagged</A>
+    </DL><p>
+    <DT><A HREF="https://example.com/root">Root link</A>
+</DL><p>`
+
+func TestParseBookmarksHTML(t *testing.T) {
+	bookmarks, err := Parse(FormatAuto, strings.NewReader(bookmarksHTML))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []Bookmark{
+		{URL: "https://go.dev/blog/", Title: "The Go Blog", SavedAt: time.Unix(1700000100, 0).UTC()},
+		{URL: "https://example.com/attention", Title: "Attention Is All You Need", Tag: "machine-learning", SavedAt: time.Unix(1700000200, 0).UTC()},
+		{URL: "https://example.com/tagged", Title: "Tagged", Tag: "rust", SavedAt: time.Unix(1700000300, 0).UTC()},
+		{URL: "https://example.com/root", Title: "Root link"},
+	}
+	if len(bookmarks) != len(want) {
+		t.Fatalf("got %d bookmarks, want %d: %+v", len(bookmarks), len(want), bookmarks)
+	}
+	for i := range want {
+		if bookmarks[i] != want[i] {
+			t.Errorf("bookmark %d = %+v, want %+v", i, bookmarks[i], want[i])
+		}
+	}
+}
+
+func TestParsePocketHTML(t *testing.T) {
+	const pocket = `<!DOCTYPE html>
+<html><head><title>Pocket Export</title></head><body>
+<h1>Unread</h1>
+<ul>
+<li><a href="https://example.com/a" time_added="1690000000" tags="golang,concurrency">Article A</a></li>
+<li><a href="https://example.com/b" time_added="1690000100" tags="">Article B</a></li>
+</ul>
+</body></html>`
+
+	bookmarks, err := Parse(FormatAuto, strings.NewReader(pocket))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(bookmarks) != 2 {
+		t.Fatalf("got %d bookmarks, want 2", len(bookmarks))
+	}
+	if bookmarks[0].Tag != "golang" || !bookmarks[0].SavedAt.Equal(time.Unix(1690000000, 0)) {
+		t.Errorf("bookmark 0 = %+v", bookmarks[0])
+	}
+	if bookmarks[1].Tag != "" {
+		t.Errorf("bookmark 1 tag = %q, want empty", bookmarks[1].Tag)
+	}
+}
+
+func TestParseRaindropCSV(t *testing.T) {
+	const raindrop = "id,title,note,excerpt,url,folder,tags,created,cover,highlights,favorite\n" +
+		`1,Go Memory Model,"worth rereading",,https://go.dev/ref/mem,Dev / Go,,2024-03-01T10:00:00.000Z,,,false` + "\n" +
+		`2,Tagged,,,https://example.com/t,Unsorted,"Distributed Systems, papers",2024-03-02T10:00:00.000Z,,,false` + "\n" +
+		`3,Unsorted,,,https://example.com/u,Unsorted,,not-a-date,,,false` + "\n"
+
+	bookmarks, err := Parse(FormatAuto, strings.NewReader(raindrop))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(bookmarks) != 3 {
+		t.Fatalf("got %d bookmarks, want 3", len(bookmarks))
+	}
+
+	first := bookmarks[0]
+	if first.Tag != "go" || first.Notes != "worth rereading" || first.Title != "Go Memory Model" {
+		t.Errorf("bookmark 0 = %+v", first)
+	}
+	if !first.SavedAt.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("bookmark 0 SavedAt = %v", first.SavedAt)
+	}
+	if bookmarks[1].Tag != "distributed-systems" {
+		t.Errorf("bookmark 1 tag = %q, want distributed-systems", bookmarks[1].Tag)
+	}
+	if bookmarks[2].Tag != "" || !bookmarks[2].SavedAt.IsZero() {
+		t.Errorf("bookmark 2 = %+v, want no tag or date", bookmarks[2])
+	}
+}
+
+func TestParseInstapaperCSV(t *testing.T) {
+	const instapaper = "URL,Title,Selection,Folder,Timestamp\n" +
+		`https://example.com/1,First,"a highlight",Unread,1680000000` + "\n" +
+		`https://example.com/2,Second,,Databases,1680000100` + "\n"
+
+	bookmarks, err := Parse(FormatAuto, strings.NewReader(instapaper))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(bookmarks) != 2 {
+		t.Fatalf("got %d bookmarks, want 2", len(bookmarks))
+	}
+	if bookmarks[0].Tag != "" || bookmarks[0].Notes != "a highlight" {
+		t.Errorf("bookmark 0 = %+v", bookmarks[0])
+	}
+	if bookmarks[1].Tag != "databases" || !bookmarks[1].SavedAt.Equal(time.Unix(1680000100, 0)) {
+		t.Errorf("bookmark 1 = %+v", bookmarks[1])
+	}
+}
+
+func TestParseUnrecognized(t *testing.T) {
+	if _, err := Parse(FormatAuto, strings.NewReader("just some text\n")); err == nil {
+		t.Error("expected an error for an unrecognized file")
+	}
+	if _, err := Parse(FormatAuto, strings.NewReader("")); err == nil {
+		t.Error("expected an error for an empty file")
+	}
+	if _, err := Parse("delicious", strings.NewReader("<html></html>")); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := map[string]string{
+		"Machine Learning": "machine-learning",
+		"  Go  ":           "go",
+		"C++ / Systems":    "c-systems",
+		"node.js":          "node-js",
+		"!!!":              "",
+		"snake_case_tag":   "snake-case-tag",
+	}
+	for in, want := range tests {
+		if got := NormalizeTag(in); got != want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package mailin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxURLsPerMessage caps how many entries one email can create, so a link-heavy
+// newsletter doesn't flood the capture list
+const maxURLsPerMessage = 20
+
+// maxPartBytes limits how much of each MIME part is read
+const maxPartBytes = 5 << 20
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+
+// Message is the part of an incoming email used for capture
+type Message struct {
+	Subject string
+	URLs    []string
+}
+
+// ParseMessage reads a raw RFC 5322 message, decoding the subject and collecting
+// the links from its text and HTML parts in order
+func ParseMessage(r io.Reader) (*Message, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	var plain, htmlLinks []string
+	err = walkPart(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body, func(mediaType string, body []byte) {
+		switch mediaType {
+		case "text/plain":
+			plain = append(plain, textURLs(string(body))...)
+		case "text/html":
+			htmlLinks = append(htmlLinks, htmlURLs(body)...)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Plain text is what the sender wrote; HTML bodies repeat it with more tracking links
+	urls := plain
+	if len(urls) == 0 {
+		urls = htmlLinks
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject),
+		URLs:    filterURLs(urls),
+	}, nil
+}
+
+// walkPart decodes a MIME part, recursing into multipart bodies, and passes text parts to fn
+func walkPart(contentType, transferEncoding string, body io.Reader, fn func(mediaType string, body []byte)) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read message part: %w", err)
+			}
+			if err := walkPart(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	if mediaType != "text/plain" && mediaType != "text/html" {
+		return nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(transferEncoding)) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, newlineStripper{body})
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, maxPartBytes))
+	if err != nil {
+		return fmt.Errorf("failed to decode message part: %w", err)
+	}
+	fn(mediaType, data)
+	return nil
+}
+
+// newlineStripper drops line breaks from base64 bodies, which the decoder doesn't accept
+type newlineStripper struct {
+	r io.Reader
+}
+
+func (n newlineStripper) Read(p []byte) (int, error) {
+	count, err := n.r.Read(p)
+	kept := 0
+	for _, b := range p[:count] {
+		if b != '\r' && b != '\n' {
+			p[kept] = b
+			kept++
+		}
+	}
+	return kept, err
+}
+
+// textURLs finds links in plain text, dropping trailing sentence punctuation
+func textURLs(text string) []string {
+	var urls []string
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		urls = append(urls, strings.TrimRight(match, ".,;:!?"))
+	}
+	return urls
+}
+
+// htmlURLs collects anchor hrefs from an HTML body
+func htmlURLs(body []byte) []string {
+	var urls []string
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return urls
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "a" || !hasAttr {
+				continue
+			}
+			for {
+				key, val, more := z.TagAttr()
+				if string(key) == "href" {
+					urls = append(urls, strings.TrimSpace(string(val)))
+				}
+				if !more {
+					break
+				}
+			}
+		}
+	}
+}
+
+// filterURLs keeps unique http(s) links, skipping unsubscribe links, up to maxURLsPerMessage
+func filterURLs(urls []string) []string {
+	seen := make(map[string]bool)
+	var kept []string
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			continue
+		}
+		if strings.Contains(strings.ToLower(raw), "unsubscribe") || seen[raw] {
+			continue
+		}
+		seen[raw] = true
+		kept = append(kept, raw)
+		if len(kept) == maxURLsPerMessage {
+			break
+		}
+	}
+	return kept
+}
@@ -0,0 +1,66 @@
+package mailin
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMessageMultipart(t *testing.T) {
+	raw := "From: me@example.com\r\n" +
+		"Subject: Fwd: Interesting\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: multipart/alternative; boundary=XYZ\r\n" +
+		"\r\n" +
+		"--XYZ\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"Content-Transfer-Encoding: quoted-printable\r\n" +
+		"\r\n" +
+		"See https://example.com/a-very-long-article-path-that-wraps-across=\r\n" +
+		"-lines\r\n" +
+		"--XYZ\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"\r\n" +
+		`<p><a href="https://tracker.example.com/click?id=1">See</a></p>` + "\r\n" +
+		"--XYZ--\r\n"
+
+	msg, err := ParseMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if msg.Subject != "Fwd: Interesting" {
+		t.Errorf("Subject = %q", msg.Subject)
+	}
+	want := []string{"https://example.com/a-very-long-article-path-that-wraps-across-lines"}
+	if !reflect.DeepEqual(msg.URLs, want) {
+		t.Errorf("URLs = %v, want %v", msg.URLs, want)
+	}
+}
+
+func TestParseMessageHTMLOnly(t *testing.T) {
+	raw := "Subject: Newsletter\r\n" +
+		"Content-Type: text/html\r\n" +
+		"Content-Transfer-Encoding: base64\r\n" +
+		"\r\n" +
+		"PGEgaHJlZj0iaHR0cHM6Ly9leGFtcGxlLmNvbS8xIj4xPC9hPjxhIGhyZWY9Im1haWx0bzp4QHku\r\n" +
+		"eiI+bWFpbDwvYT48YSBocmVmPSJodHRwczovL2V4YW1wbGUuY29tLzIiPjI8L2E+\r\n"
+
+	msg, err := ParseMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	want := []string{"https://example.com/1", "https://example.com/2"}
+	if !reflect.DeepEqual(msg.URLs, want) {
+		t.Errorf("URLs = %v, want %v", msg.URLs, want)
+	}
+}
+
+func TestFilterURLsLimit(t *testing.T) {
+	var urls []string
+	for i := 0; i < maxURLsPerMessage+5; i++ {
+		urls = append(urls, "https://example.com/"+strings.Repeat("a", i+1))
+	}
+	if got := filterURLs(urls); len(got) != maxURLsPerMessage {
+		t.Errorf("kept %d URLs, want %d", len(got), maxURLsPerMessage)
+	}
+}
@@ -0,0 +1,392 @@
+// Package mailin receives forwarded emails over SMTP and captures the links in them as entries.
+package mailin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/urlutil"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxMessageBytes is the largest message accepted, advertised with SIZE
+	maxMessageBytes = 10 << 20
+	// maxRecipients limits RCPT TO commands per message
+	maxRecipients = 20
+	// commandTimeout bounds how long a client may idle between commands
+	commandTimeout = 5 * time.Minute
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// EntryStore defines the repository operations used to capture emailed links
+type EntryStore interface {
+	CountByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (int, error)
+	Create(ctx context.Context, input *model.CreateEntryInput) (*model.Entry, error)
+}
+
+// UserStore looks up the account a recipient address belongs to
+type UserStore interface {
+	GetByMailKey(ctx context.Context, key string) (*model.User, error)
+}
+
+// Config holds SMTP receiver configuration
+type Config struct {
+	// Addr is the listen address, e.g. ":2525"
+	Addr string
+	// Hostname is announced in the greeting
+	Hostname string
+	// Recipients are the base addresses mail is accepted for. Each user sends to one
+	// with their secret mail key after a "+", e.g. save+key@example.com, and may add
+	// a tag after another "+", e.g. save+key+go@example.com.
+	Recipients []string
+}
+
+// Server is a minimal SMTP receiver that turns emailed links into entries in the
+// journal of the user whose mail key is in the recipient address. The sender isn't
+// trusted, since anyone can forge it.
+// It doesn't support TLS or authentication; run it behind a relay or on a private network.
+type Server struct {
+	store      EntryStore
+	users      UserStore
+	hostname   string
+	addr       string
+	recipients map[string]bool
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// New creates a new SMTP receiver
+func New(cfg Config, store EntryStore, users UserStore) *Server {
+	hostname := cfg.Hostname
+	if hostname == "" {
+		hostname = "learnd"
+	}
+
+	recipients := make(map[string]bool, len(cfg.Recipients))
+	for _, r := range cfg.Recipients {
+		if r = strings.ToLower(strings.TrimSpace(r)); r != "" {
+			recipients[r] = true
+		}
+	}
+
+	return &Server{
+		store:      store,
+		users:      users,
+		hostname:   hostname,
+		addr:       cfg.Addr,
+		recipients: recipients,
+		conns:      make(map[net.Conn]struct{}),
+	}
+}
+
+// ListenAndServe listens on the configured address and serves until Close is called
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for SMTP: %w", err)
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts SMTP connections on ln until Close is called
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ln.Close()
+	}
+	s.listener = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("failed to accept SMTP connection: %w", err)
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				conn.Close()
+			}()
+			s.handle(conn)
+		}()
+	}
+}
+
+// Close stops accepting connections and waits for open sessions to end
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// session is the state of one SMTP transaction
+type session struct {
+	from   string
+	userID uuid.UUID
+	tag    *string
+	rcpt   int
+}
+
+func (s *Server) handle(conn net.Conn) {
+	tp := textproto.NewConn(conn)
+	reply := func(format string, args ...any) bool {
+		return tp.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 %s ESMTP learnd", s.hostname) {
+		return
+	}
+
+	var sess session
+	greeted := false
+	for {
+		conn.SetReadDeadline(time.Now().Add(commandTimeout))
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			greeted = true
+			sess = session{}
+			reply("250 %s", s.hostname)
+		case "EHLO":
+			greeted = true
+			sess = session{}
+			reply("250-%s", s.hostname)
+			reply("250-SIZE %d", maxMessageBytes)
+			reply("250 8BITMIME")
+		case "MAIL":
+			if !greeted {
+				reply("503 5.5.1 Send HELO first")
+				continue
+			}
+			from, ok := pathArg(arg, "FROM:")
+			if !ok {
+				reply("501 5.5.4 Syntax: MAIL FROM:<address>")
+				continue
+			}
+			sess = session{from: from}
+			reply("250 2.1.0 OK")
+		case "RCPT":
+			if sess.from == "" {
+				reply("503 5.5.1 Send MAIL first")
+				continue
+			}
+			to, ok := pathArg(arg, "TO:")
+			if !ok {
+				reply("501 5.5.4 Syntax: RCPT TO:<address>")
+				continue
+			}
+			if sess.rcpt >= maxRecipients {
+				reply("452 4.5.3 Too many recipients")
+				continue
+			}
+			key, tag, ok := s.matchRecipient(to)
+			if !ok {
+				reply("550 5.1.1 Recipient not accepted")
+				continue
+			}
+			user, err := s.users.GetByMailKey(context.Background(), key)
+			if err != nil {
+				slog.Error("failed to look up email recipient", "error", err)
+				reply("451 4.3.0 Try again later")
+				continue
+			}
+			if user == nil {
+				slog.Warn("rejected email for unknown mail key", "from", sess.from)
+				reply("550 5.1.1 Recipient not accepted")
+				continue
+			}
+			if sess.rcpt > 0 && user.ID != sess.userID {
+				// One message is saved to one journal; the client retries the rest separately
+				reply("452 4.5.3 Send to one learnd user per message")
+				continue
+			}
+			if sess.rcpt == 0 {
+				sess.userID, sess.tag = user.ID, tag
+			}
+			sess.rcpt++
+			reply("250 2.1.5 OK")
+		case "DATA":
+			if sess.rcpt == 0 {
+				reply("503 5.5.1 Send RCPT first")
+				continue
+			}
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			reply("%s", s.receive(tp, sess))
+			sess = session{}
+		case "RSET":
+			sess = session{}
+			reply("250 2.0.0 OK")
+		case "NOOP":
+			reply("250 2.0.0 OK")
+		case "VRFY":
+			reply("252 2.5.2 Cannot verify user")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Command not implemented")
+		}
+	}
+}
+
+// receive reads the message body and creates entries, returning the final reply
+func (s *Server) receive(tp *textproto.Conn, sess session) string {
+	body := io.LimitReader(tp.DotReader(), maxMessageBytes+1)
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "451 4.3.0 Failed to read message"
+	}
+	if len(data) > maxMessageBytes {
+		// Drain the rest so the connection stays in sync
+		io.Copy(io.Discard, tp.DotReader())
+		return "552 5.3.4 Message too large"
+	}
+
+	msg, err := ParseMessage(bytes.NewReader(data))
+	if err != nil {
+		slog.Warn("failed to parse email", "from", sess.from, "error", err)
+		return "554 5.6.0 Could not parse message"
+	}
+	if len(msg.URLs) == 0 {
+		slog.Info("email had no links", "from", sess.from, "subject", msg.Subject)
+		return "554 5.6.0 No links found in message"
+	}
+
+	created, err := s.capture(context.Background(), sess.userID, msg, sess.tag)
+	if err != nil {
+		slog.Error("failed to capture emailed links", "from", sess.from, "error", err)
+		return "451 4.3.0 Failed to save links, try again later"
+	}
+
+	slog.Info("captured emailed links", "from", sess.from, "links", len(msg.URLs), "created", created)
+	return fmt.Sprintf("250 2.0.0 Saved %d of %d links", created, len(msg.URLs))
+}
+
+// capture creates an entry for each link the user hasn't captured before, with the
+// subject as notes. Returns how many entries were created.
+func (s *Server) capture(ctx context.Context, userID uuid.UUID, msg *Message, tag *string) (int, error) {
+	var notes *string
+	if msg.Subject != "" {
+		notes = &msg.Subject
+	}
+
+	created := 0
+	for _, link := range msg.URLs {
+		normalizedURL, err := urlutil.NormalizeURL(link)
+		if err != nil {
+			continue
+		}
+
+		count, err := s.store.CountByNormalizedURL(ctx, userID, normalizedURL)
+		if err != nil {
+			return created, err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if _, err := s.store.Create(ctx, &model.CreateEntryInput{
+			UserID:        userID,
+			SourceURL:     link,
+			NormalizedURL: normalizedURL,
+			Tag:           tag,
+			Notes:         notes,
+		}); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// matchRecipient checks an address of the form base+key[+tag]@domain against the
+// allow-list, returning the mail key and the tag when present
+func (s *Server) matchRecipient(addr string) (string, *string, bool) {
+	addr = strings.ToLower(addr)
+	local, domain, ok := strings.Cut(addr, "@")
+	if !ok {
+		return "", nil, false
+	}
+
+	base, suffix, ok := strings.Cut(local, "+")
+	if !ok || !s.recipients[base+"@"+domain] {
+		return "", nil, false
+	}
+	key, tag, hasTag := strings.Cut(suffix, "+")
+	if key == "" {
+		return "", nil, false
+	}
+	if !hasTag || !tagPattern.MatchString(tag) {
+		return key, nil, true
+	}
+	return key, &tag, true
+}
+
+// pathArg parses the address from "FROM:<addr> [params]" or "TO:<addr> [params]"
+func pathArg(arg, prefix string) (string, bool) {
+	arg = strings.TrimSpace(arg)
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	arg = strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(arg, "<") {
+		return "", false
+	}
+	end := strings.IndexByte(arg, '>')
+	if end < 0 {
+		return "", false
+	}
+	addr := arg[1:end]
+	if prefix == "FROM:" && addr == "" {
+		// Null reverse-path, used by bounces
+		return "<>", true
+	}
+	return addr, addr != ""
+}
@@ -0,0 +1,174 @@
+package mailin
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type mockEntryStore struct {
+	mu       sync.Mutex
+	existing map[string]bool
+	created  []model.CreateEntryInput
+}
+
+func (m *mockEntryStore) CountByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.existing[normalizedURL] {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m *mockEntryStore) Create(ctx context.Context, input *model.CreateEntryInput) (*model.Entry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.created = append(m.created, *input)
+	return &model.Entry{ID: uuid.New(), SourceURL: input.SourceURL}, nil
+}
+
+var (
+	testUser  = &model.User{ID: uuid.New(), Email: "me@example.com"}
+	otherUser = &model.User{ID: uuid.New(), Email: "other@example.com"}
+)
+
+type mockUserStore struct{}
+
+func (mockUserStore) GetByMailKey(ctx context.Context, key string) (*model.User, error) {
+	switch key {
+	case "mekey":
+		return testUser, nil
+	case "otherkey":
+		return otherUser, nil
+	}
+	return nil, nil
+}
+
+func startServer(t *testing.T, store EntryStore) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(Config{Recipients: []string{"Save@learnd.test"}}, store, mockUserStore{})
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Close() })
+
+	return ln.Addr().String()
+}
+
+func TestReceiveEmail(t *testing.T) {
+	store := &mockEntryStore{existing: map[string]bool{"https://example.com/seen": true}}
+	addr := startServer(t, store)
+
+	msg := "From: me@example.com\r\n" +
+		"To: save+mekey+go@learnd.test\r\n" +
+		"Subject: =?UTF-8?Q?Weekly_links_=E2=9C=A8?=\r\n" +
+		"\r\n" +
+		"Worth reading: https://go.dev/blog/loopvar-preview.\r\n" +
+		"Also https://example.com/seen and https://go.dev/blog/loopvar-preview again.\r\n" +
+		"Unsubscribe: https://news.example.com/unsubscribe?id=1\r\n"
+
+	// The sender doesn't pick the account, only the key in the recipient address does
+	err := smtp.SendMail(addr, nil, "someone@elsewhere.test", []string{"save+mekey+go@learnd.test"}, []byte(msg))
+	if err != nil {
+		t.Fatalf("SendMail() error = %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.created) != 1 {
+		t.Fatalf("created %d entries, want 1: %+v", len(store.created), store.created)
+	}
+	entry := store.created[0]
+	if entry.UserID != testUser.ID {
+		t.Errorf("UserID = %v, want the mail key's account %v", entry.UserID, testUser.ID)
+	}
+	if entry.SourceURL != "https://go.dev/blog/loopvar-preview" {
+		t.Errorf("SourceURL = %q", entry.SourceURL)
+	}
+	if entry.Tag == nil || *entry.Tag != "go" {
+		t.Errorf("Tag = %v, want go", entry.Tag)
+	}
+	if entry.Notes == nil || *entry.Notes != "Weekly links ✨" {
+		t.Errorf("Notes = %v, want the decoded subject", entry.Notes)
+	}
+}
+
+func TestReceiveEmailRejections(t *testing.T) {
+	store := &mockEntryStore{}
+	addr := startServer(t, store)
+
+	err := smtp.SendMail(addr, nil, "me@example.com", []string{"someone@learnd.test"}, []byte("Subject: hi\r\n\r\nhttps://example.com\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("unknown recipient error = %v, want 550", err)
+	}
+
+	// The shared address alone, or with a key nobody has, isn't enough
+	for _, rcpt := range []string{"save@learnd.test", "save+guess@learnd.test"} {
+		err = smtp.SendMail(addr, nil, "me@example.com", []string{rcpt}, []byte("Subject: hi\r\n\r\nhttps://example.com\r\n"))
+		if err == nil || !strings.Contains(err.Error(), "550") {
+			t.Errorf("%s error = %v, want 550", rcpt, err)
+		}
+	}
+
+	err = smtp.SendMail(addr, nil, "me@example.com", []string{"save+mekey@learnd.test", "save+otherkey@learnd.test"}, []byte("Subject: hi\r\n\r\nhttps://example.com\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "452") {
+		t.Errorf("two users' addresses error = %v, want 452", err)
+	}
+
+	err = smtp.SendMail(addr, nil, "me@example.com", []string{"save+mekey@learnd.test"}, []byte("Subject: hi\r\n\r\nno links here\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "No links") {
+		t.Errorf("no links error = %v, want 554", err)
+	}
+
+	if len(store.created) != 0 {
+		t.Errorf("created %d entries, want 0", len(store.created))
+	}
+}
+
+func TestMatchRecipient(t *testing.T) {
+	srv := New(Config{Recipients: []string{"save@learnd.test"}}, nil, nil)
+
+	tests := []struct {
+		addr    string
+		ok      bool
+		wantKey string
+		wantTag string
+	}{
+		{addr: "save+k3y@learnd.test", ok: true, wantKey: "k3y"},
+		{addr: "SAVE+K3Y+Rust@Learnd.Test", ok: true, wantKey: "k3y", wantTag: "rust"},
+		{addr: "save+k3y+bad_tag@learnd.test", ok: true, wantKey: "k3y"},
+		{addr: "save@learnd.test", ok: false},
+		{addr: "save++go@learnd.test", ok: false},
+		{addr: "other+k3y@learnd.test", ok: false},
+		{addr: "save+k3y@other.test", ok: false},
+		{addr: "not-an-address", ok: false},
+	}
+
+	for _, tt := range tests {
+		key, tag, ok := srv.matchRecipient(tt.addr)
+		if ok != tt.ok {
+			t.Errorf("matchRecipient(%q) ok = %v, want %v", tt.addr, ok, tt.ok)
+			continue
+		}
+		if key != tt.wantKey {
+			t.Errorf("matchRecipient(%q) key = %q, want %q", tt.addr, key, tt.wantKey)
+		}
+		got := ""
+		if tag != nil {
+			got = *tag
+		}
+		if got != tt.wantTag {
+			t.Errorf("matchRecipient(%q) tag = %q, want %q", tt.addr, got, tt.wantTag)
+		}
+	}
+}
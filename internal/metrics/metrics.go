@@ -0,0 +1,110 @@
+// Package metrics defines the Prometheus metrics learnd exposes at /metrics.
+//
+// Metrics are registered on Registry rather than the global default registry, so
+// only learnd's own metrics, plus the Go runtime and process collectors, are served.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "learnd"
+
+// Registry holds every learnd metric
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+var (
+	// HTTPRequests counts requests by method, chi route pattern and status code
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes request latency by method and chi route pattern
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method and route pattern.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// Enrichments counts enrichment attempts by enricher name and outcome
+	Enrichments = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "enrichments_total",
+		Help:      "Enrichment attempts by enricher and outcome (success or error).",
+	}, []string{"enricher", "outcome"})
+
+	// EnrichmentDuration observes enrichment latency by enricher name
+	EnrichmentDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "enrichment_duration_seconds",
+		Help:      "Enrichment latency by enricher.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"enricher"})
+
+	// SummarizerDuration observes summarizer call latency by provider
+	SummarizerDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "summarizer_duration_seconds",
+		Help:      "Summarizer call latency by provider.",
+		Buckets:   []float64{0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"provider"})
+
+	// SummarizerTokens counts tokens reported by providers, by direction (input or output)
+	SummarizerTokens = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "summarizer_tokens_total",
+		Help:      "Tokens used by provider and direction (input or output).",
+	}, []string{"provider", "direction"})
+
+	// SummarizerErrors counts failed summarizer calls by provider and whether they're retryable
+	SummarizerErrors = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "summarizer_errors_total",
+		Help:      "Failed summarizer calls by provider and retryable (true or false).",
+	}, []string{"provider", "retryable"})
+
+	// SummaryCacheLookups counts summary cache lookups by result (hit, miss or bypass)
+	SummaryCacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "summary_cache_lookups_total",
+		Help:      "Summary cache lookups by result (hit, miss or bypass).",
+	}, []string{"result"})
+)
+
+// Handler serves the registry in the Prometheus text format. A non-empty token is
+// required as a bearer token, for when /metrics shares the public listener.
+func Handler(token string) http.Handler {
+	handler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+	if token == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHandlerToken(t *testing.T) {
+	SummaryCacheLookups.WithLabelValues("hit").Inc()
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "no token configured", wantStatus: http.StatusOK},
+		{name: "valid token", token: "secret", authorization: "Bearer secret", wantStatus: http.StatusOK},
+		{name: "wrong token", token: "secret", authorization: "Bearer nope", wantStatus: http.StatusUnauthorized},
+		{name: "missing token", token: "secret", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			Handler(tt.token).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && !strings.Contains(rec.Body.String(), `learnd_summary_cache_lookups_total{result="hit"}`) {
+				t.Error("expected learnd metrics in the response")
+			}
+		})
+	}
+}
+
+type mockQueueCounter struct {
+	calls int
+}
+
+func (m *mockQueueCounter) CountByStatus(ctx context.Context) ([]repository.StatusCount, error) {
+	m.calls++
+	return []repository.StatusCount{
+		{Stage: "enrichment", Status: model.StatusPending, Count: 3},
+		{Stage: "summary", Status: model.StatusFailed, Count: 1},
+	}, nil
+}
+
+func TestQueueCollector(t *testing.T) {
+	counter := &mockQueueCounter{}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(&queueCollector{
+		counter: counter,
+		depth:   prometheus.NewDesc("learnd_worker_queue_depth", "Entries by worker stage (enrichment or summary) and status.", []string{"stage", "status"}, nil),
+	})
+
+	want := `
+# HELP learnd_worker_queue_depth Entries by worker stage (enrichment or summary) and status.
+# TYPE learnd_worker_queue_depth gauge
+learnd_worker_queue_depth{stage="enrichment",status="pending"} 3
+learnd_worker_queue_depth{stage="summary",status="failed"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+	// Counted once per scrape, not in the background
+	if counter.calls != 1 {
+		t.Errorf("CountByStatus called %d times, want 1", counter.calls)
+	}
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/drywaters/learnd/internal/repository"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// queueCountTimeout bounds the count query a scrape runs
+const queueCountTimeout = 5 * time.Second
+
+// QueueCounter counts entries by worker stage and status
+type QueueCounter interface {
+	CountByStatus(ctx context.Context) ([]repository.StatusCount, error)
+}
+
+// queueCollector counts the worker queues at scrape time, so the database is only
+// queried as often as metrics are read
+type queueCollector struct {
+	counter QueueCounter
+	depth   *prometheus.Desc
+}
+
+// RegisterQueueDepth exposes the number of entries in each status of each worker stage
+func RegisterQueueDepth(counter QueueCounter) {
+	Registry.MustRegister(&queueCollector{
+		counter: counter,
+		depth: prometheus.NewDesc(prometheus.BuildFQName(namespace, "worker", "queue_depth"),
+			"Entries by worker stage (enrichment or summary) and status.", []string{"stage", "status"}, nil),
+	})
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.depth
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueCountTimeout)
+	defer cancel()
+
+	counts, err := c.counter.CountByStatus(ctx)
+	if err != nil {
+		slog.Warn("failed to count entries by status", "error", err)
+		ch <- prometheus.NewInvalidMetric(c.depth, err)
+		return
+	}
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.depth, prometheus.GaugeValue, float64(count.Count), count.Stage, string(count.Status))
+	}
+}
@@ -1,60 +1,274 @@
 package middleware
 
 import (
+	"context"
 	"crypto/subtle"
+	"log/slog"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
 )
 
-const cookieName = "learnd_session"
+// touchInterval limits how often a session's or token's last-used time is written,
+// so a burst of requests doesn't update the row on every one
+const touchInterval = time.Minute
+
+// UserStore looks up the account a request is authenticated as
+type UserStore interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*model.User, error)
+}
+
+// SessionStore looks up and refreshes browser sessions
+type SessionStore interface {
+	SessionLookup
+	Touch(ctx context.Context, id uuid.UUID, userAgent, ip string, expiresAt time.Time) error
+}
+
+// TokenStore looks up personal access tokens
+type TokenStore interface {
+	GetByHash(ctx context.Context, tokenHash string) (*model.APIToken, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+}
+
+// AuthEventStore records rejected credentials in the authentication audit log
+type AuthEventStore interface {
+	Record(ctx context.Context, event model.AuthEvent) error
+}
 
-// Auth middleware validates requests using either Bearer token or cookie.
-// Programmatic clients (iOS Shortcuts, CLI) use Authorization: Bearer <token>.
-// Browser clients use a cookie set during login.
-func Auth(apiToken string, secureCookies bool) func(http.Handler) http.Handler {
+// AuthConfig holds authentication settings
+type AuthConfig struct {
+	// APIToken is the shared legacy token, accepted only when LegacyToken is set
+	APIToken      string
+	LegacyToken   bool
+	SecureCookies bool
+	// SessionTTL is how far each use slides a browser session's expiry
+	SessionTTL time.Duration
+	// SessionMaxAge is how long a browser session lasts at most, however often it's used
+	SessionMaxAge time.Duration
+}
+
+// Auth middleware validates requests using either Bearer token or session cookie and
+// stores the signed-in user and the scopes their credentials grant in the request context.
+// Programmatic clients (iOS Shortcuts, CLI) use Authorization: Bearer <token> with a
+// personal access token, or the legacy shared token, which acts as the default user.
+// Browser clients use the session cookie set during login, whose expiry slides
+// forward by SessionTTL as it's used, up to SessionMaxAge after sign-in. Rejected bearer tokens are recorded to events
+// and counted by limiter, and a locked out client's bearer requests get 429 without
+// their tokens being checked.
+func Auth(cfg AuthConfig, users UserStore, sessions SessionStore, tokens TokenStore, events AuthEventStore, limiter *auth.Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Check Authorization header first (for programmatic access)
 			if authHeader := r.Header.Get("Authorization"); authHeader != "" {
-				if token, ok := strings.CutPrefix(authHeader, "Bearer "); ok {
-					if constantTimeEqual(token, apiToken) {
-						next.ServeHTTP(w, r)
-						return
-					}
+				if retryAfter := limiter.RetryAfter(auth.ClientIP(r)); retryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+					http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+					return
 				}
-				// Invalid bearer token
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				token, ok := strings.CutPrefix(authHeader, "Bearer ")
+				if !ok || token == "" {
+					rejectBearer(w, r, events, limiter)
+					return
+				}
+				user, scopes, ok := bearerUser(w, r, cfg, users, tokens, token)
+				if !ok {
+					return
+				}
+				if user == nil {
+					// Unknown, expired or revoked token
+					rejectBearer(w, r, events, limiter)
+					return
+				}
+				ctx := auth.WithScopes(auth.WithUser(r.Context(), user), scopes)
+				next.ServeHTTP(w, r.WithContext(ctx))
 				return
 			}
 
 			// Fall back to cookie check (for browser access)
-			cookie, err := r.Cookie(cookieName)
+			cookie, err := r.Cookie(auth.SessionCookieName)
+			if err != nil {
+				redirectToLogin(w, r)
+				return
+			}
+
+			session, err := sessions.GetByTokenHash(r.Context(), auth.HashToken(cookie.Value))
 			if err != nil {
+				slog.Error("failed to load session", "error", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			if session == nil || (cfg.SessionMaxAge > 0 && time.Since(session.CreatedAt) >= cfg.SessionMaxAge) {
+				// Unknown, expired, too old or revoked session, clear it and redirect
+				auth.ClearSessionCookie(w, cfg.SecureCookies)
 				redirectToLogin(w, r)
 				return
 			}
 
-			if !constantTimeEqual(cookie.Value, apiToken) {
-				// Invalid cookie, clear it and redirect
-				http.SetCookie(w, &http.Cookie{
-					Name:     cookieName,
-					Value:    "",
-					Path:     "/",
-					MaxAge:   -1,
-					HttpOnly: true,
-					Secure:   secureCookies,
-					SameSite: http.SameSiteLaxMode,
-				})
+			user, ok := loadUser(w, r, users, session.UserID)
+			if !ok {
+				return
+			}
+			if user == nil {
+				auth.ClearSessionCookie(w, cfg.SecureCookies)
 				redirectToLogin(w, r)
 				return
 			}
 
+			if time.Since(session.LastSeenAt) >= touchInterval {
+				userAgent, ip := r.UserAgent(), auth.ClientIP(r)
+				now := time.Now()
+				expiresAt := auth.SessionExpiry(session.CreatedAt, now, cfg.SessionTTL, cfg.SessionMaxAge)
+				if err := sessions.Touch(r.Context(), session.ID, userAgent, ip, expiresAt); err != nil {
+					slog.Warn("failed to touch session", "error", err, "session_id", session.ID)
+				} else {
+					session.UserAgent, session.IP = userAgent, ip
+					session.LastSeenAt, session.ExpiresAt = now, expiresAt
+					auth.SetSessionCookie(w, cookie.Value, expiresAt.Sub(now), cfg.SecureCookies)
+				}
+			}
+
+			ctx := auth.WithSession(auth.WithUser(r.Context(), user), session)
+			ctx = auth.WithScopes(ctx, model.UserScopes(user))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// bearerUser resolves a bearer token to its user and scopes. The user is nil when the
+// token isn't valid; ok is false when a response has already been written.
+func bearerUser(w http.ResponseWriter, r *http.Request, cfg AuthConfig, users UserStore, tokens TokenStore, token string) (*model.User, []model.Scope, bool) {
+	if cfg.LegacyToken && constantTimeEqual(token, cfg.APIToken) {
+		user, ok := loadUser(w, r, users, model.DefaultUserID)
+		if !ok || user == nil {
+			return user, nil, ok
+		}
+		return user, model.Scopes, true
+	}
+
+	apiToken, err := tokens.GetByHash(r.Context(), auth.HashToken(token))
+	if err != nil {
+		slog.Error("failed to load api token", "error", err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if apiToken == nil || apiToken.Expired(time.Now()) {
+		return nil, nil, true
+	}
+
+	user, ok := loadUser(w, r, users, apiToken.UserID)
+	if !ok || user == nil {
+		return nil, nil, ok
+	}
+
+	if apiToken.LastUsedAt == nil || time.Since(*apiToken.LastUsedAt) >= touchInterval {
+		if err := tokens.TouchLastUsed(r.Context(), apiToken.ID); err != nil {
+			slog.Warn("failed to touch api token", "error", err, "token_id", apiToken.ID)
+		}
+	}
+
+	// A token can't grant more than its owner has, e.g. admin after a demotion
+	var scopes []model.Scope
+	for _, scope := range apiToken.Scopes {
+		if scope != model.ScopeAdmin || user.IsAdmin {
+			scopes = append(scopes, scope)
+		}
+	}
+	return user, scopes, true
+}
+
+// rejectBearer answers a request with an invalid bearer token with 401, recording it
+// and counting it towards locking out the client
+func rejectBearer(w http.ResponseWriter, r *http.Request, events AuthEventStore, limiter *auth.Limiter) {
+	ip := auth.ClientIP(r)
+	recordEvent(r, events, model.AuthEvent{Kind: model.AuthEventTokenRejected, Method: model.AuthMethodBearer})
+
+	if lockout := limiter.Fail(ip); lockout != auth.NoLockout {
+		slog.Warn("too many rejected bearer tokens", "lockout", lockout, "ip", ip)
+		recordEvent(r, events, model.AuthEvent{Kind: model.AuthEventLockedOut, Method: lockout.String()})
+	}
+	limiter.Wait(r.Context())
+	http.Error(w, "Unauthorized", http.StatusUnauthorized)
+}
+
+// recordEvent adds event to the audit log with the request's client address and user
+// agent, only logging failures to write it
+func recordEvent(r *http.Request, events AuthEventStore, event model.AuthEvent) {
+	event.IP, event.UserAgent = auth.ClientIP(r), r.UserAgent()
+	if err := events.Record(r.Context(), event); err != nil {
+		slog.Warn("failed to record auth event", "error", err, "kind", event.Kind)
+	}
+}
+
+// RequireScope rejects requests without a signed-in user with a 401, and those whose
+// credentials don't grant scope with a 403
+func RequireScope(scope model.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if auth.UserFromContext(r.Context()) == nil {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !auth.HasScope(r.Context(), scope) {
+				http.Error(w, "Forbidden: requires "+string(scope)+" scope", http.StatusForbidden)
+				return
+			}
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
+// RequireSession rejects bearer token requests with a 403, for routes that manage
+// credentials and must only be reachable from a signed-in browser
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.SessionFromContext(r.Context()) == nil {
+			http.Error(w, "Forbidden: sign in with a browser to manage credentials", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TOTPEnrolmentPath is where users who must use two-factor sign-in set it up
+const TOTPEnrolmentPath = "/settings/security"
+
+// RequireTOTPEnrolment sends browser users whose account requires two-factor sign-in,
+// but who haven't set it up yet, to the enrolment page. Bearer requests are unaffected.
+func RequireTOTPEnrolment(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := auth.UserFromContext(r.Context())
+		if auth.SessionFromContext(r.Context()) == nil || user == nil || !user.TOTPRequired || user.TOTPEnabled() {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if r.Header.Get("HX-Request") == "true" {
+			w.Header().Set("HX-Redirect", TOTPEnrolmentPath)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		http.Redirect(w, r, TOTPEnrolmentPath, http.StatusSeeOther)
+	})
+}
+
+// loadUser looks up the signed-in user, writing a 500 and returning false on failure
+func loadUser(w http.ResponseWriter, r *http.Request, users UserStore, userID uuid.UUID) (*model.User, bool) {
+	user, err := users.GetByID(r.Context(), userID)
+	if err != nil {
+		slog.Error("failed to load user", "error", err, "user_id", userID)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return nil, false
+	}
+	return user, true
+}
+
 // constantTimeEqual performs a constant-time comparison to prevent timing attacks.
 func constantTimeEqual(a, b string) bool {
 	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
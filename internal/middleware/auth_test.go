@@ -0,0 +1,302 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+type mockUsers map[uuid.UUID]*model.User
+
+func (m mockUsers) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	return m[id], nil
+}
+
+type mockSessions struct {
+	sessions map[string]*model.Session
+	touched  []uuid.UUID
+}
+
+func (m *mockSessions) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	return m.sessions[tokenHash], nil
+}
+
+func (m *mockSessions) Touch(ctx context.Context, id uuid.UUID, userAgent, ip string, expiresAt time.Time) error {
+	m.touched = append(m.touched, id)
+	return nil
+}
+
+type mockTokens struct {
+	tokens  map[string]*model.APIToken
+	touched []uuid.UUID
+}
+
+func (m *mockTokens) GetByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	return m.tokens[tokenHash], nil
+}
+
+func (m *mockTokens) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	m.touched = append(m.touched, id)
+	return nil
+}
+
+type mockEvents struct {
+	events []model.AuthEvent
+}
+
+func (m *mockEvents) Record(ctx context.Context, event model.AuthEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func TestAuth(t *testing.T) {
+	admin := &model.User{ID: model.DefaultUserID}
+	alice := &model.User{ID: uuid.New()}
+	users := mockUsers{admin.ID: admin, alice.ID: alice}
+
+	signedIn := time.Now().Add(-24 * time.Hour)
+	fresh := &model.Session{ID: uuid.New(), UserID: alice.ID, CreatedAt: signedIn, LastSeenAt: time.Now()}
+	stale := &model.Session{ID: uuid.New(), UserID: alice.ID, CreatedAt: signedIn, LastSeenAt: time.Now().Add(-time.Hour)}
+	tooOld := &model.Session{ID: uuid.New(), UserID: alice.ID, CreatedAt: time.Now().AddDate(0, 0, -31), LastSeenAt: time.Now()}
+	sessions := &mockSessions{sessions: map[string]*model.Session{
+		auth.HashToken("fresh-token"):   fresh,
+		auth.HashToken("stale-token"):   stale,
+		auth.HashToken("too-old-token"): tooOld,
+	}}
+
+	past := time.Now().Add(-time.Hour)
+	readToken := &model.APIToken{ID: uuid.New(), UserID: alice.ID, Scopes: []model.Scope{model.ScopeEntriesRead}}
+	expiredToken := &model.APIToken{ID: uuid.New(), UserID: alice.ID, Scopes: model.Scopes, ExpiresAt: &past}
+	tokens := &mockTokens{tokens: map[string]*model.APIToken{
+		auth.HashToken("lnd_read"):    readToken,
+		auth.HashToken("lnd_expired"): expiredToken,
+	}}
+
+	var gotUser uuid.UUID
+	handler := Auth(AuthConfig{
+		APIToken:      "api-token",
+		LegacyToken:   true,
+		SessionTTL:    24 * time.Hour,
+		SessionMaxAge: 30 * 24 * time.Hour,
+	}, users, sessions, tokens, &mockEvents{}, auth.NewLimiter(auth.LimiterConfig{}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser = auth.UserID(r.Context())
+	}))
+
+	tests := []struct {
+		name       string
+		bearer     string
+		cookie     string
+		wantStatus int
+		wantUser   uuid.UUID
+	}{
+		{name: "bearer token", bearer: "api-token", wantStatus: http.StatusOK, wantUser: admin.ID},
+		{name: "wrong bearer token", bearer: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "personal access token", bearer: "lnd_read", wantStatus: http.StatusOK, wantUser: alice.ID},
+		{name: "expired personal access token", bearer: "lnd_expired", wantStatus: http.StatusUnauthorized},
+		{name: "session cookie", cookie: "fresh-token", wantStatus: http.StatusOK, wantUser: alice.ID},
+		{name: "stale session cookie", cookie: "stale-token", wantStatus: http.StatusOK, wantUser: alice.ID},
+		{name: "session past its max age", cookie: "too-old-token", wantStatus: http.StatusSeeOther},
+		{name: "revoked session", cookie: "revoked-token", wantStatus: http.StatusSeeOther},
+		{name: "api token as cookie", cookie: "api-token", wantStatus: http.StatusSeeOther},
+		{name: "no credentials", wantStatus: http.StatusSeeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUser = uuid.Nil
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: tt.cookie})
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if gotUser != tt.wantUser {
+				t.Errorf("user = %v, want %v", gotUser, tt.wantUser)
+			}
+		})
+	}
+
+	// Only the stale session is written back, sliding its expiry
+	if len(sessions.touched) != 1 || sessions.touched[0] != stale.ID {
+		t.Errorf("touched = %v, want only the stale session", sessions.touched)
+	}
+}
+
+func TestAuthLegacyTokenDisabled(t *testing.T) {
+	users := mockUsers{model.DefaultUserID: {ID: model.DefaultUserID, IsAdmin: true}}
+	handler := Auth(AuthConfig{APIToken: "api-token"}, users, &mockSessions{}, &mockTokens{}, &mockEvents{}, auth.NewLimiter(auth.LimiterConfig{}))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, bearer := range []string{"api-token", ""} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("bearer %q: status = %d, want 401", bearer, rec.Code)
+		}
+	}
+}
+
+func TestAuthBearerLockout(t *testing.T) {
+	events := &mockEvents{}
+	limiter := auth.NewLimiter(auth.LimiterConfig{MaxFailures: 2})
+	alice := &model.User{ID: uuid.New()}
+	tokens := &mockTokens{tokens: map[string]*model.APIToken{
+		auth.HashToken("lnd_alice"): {ID: uuid.New(), UserID: alice.ID, Scopes: model.Scopes},
+	}}
+	handler := Auth(AuthConfig{}, mockUsers{alice.ID: alice}, &mockSessions{}, tokens, events, limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(header string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, header := range []string{"Bearer guess-1", "Basic guess-2"} {
+		if rec := request(header); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("%s status = %d, want 401", header, rec.Code)
+		}
+	}
+
+	// Every token from a locked out address is refused unchecked, even a valid one
+	for _, header := range []string{"Bearer guess-3", "Bearer lnd_alice"} {
+		rec := request(header)
+		if rec.Code != http.StatusTooManyRequests {
+			t.Fatalf("%s status while locked out = %d, want 429", header, rec.Code)
+		}
+		if got := rec.Header().Get("Retry-After"); got != "60" {
+			t.Errorf("Retry-After = %q, want 60", got)
+		}
+	}
+	if len(tokens.touched) != 0 {
+		t.Error("expected the valid token not to be looked up while locked out")
+	}
+
+	var kinds []model.AuthEventKind
+	for _, event := range events.events {
+		kinds = append(kinds, event.Kind)
+		if event.IP != "192.0.2.1" {
+			t.Errorf("event IP = %q, want the client's", event.IP)
+		}
+	}
+	want := []model.AuthEventKind{model.AuthEventTokenRejected, model.AuthEventTokenRejected, model.AuthEventLockedOut}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("events = %v, want %v", kinds, want)
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	alice := &model.User{ID: uuid.New()}
+	admin := &model.User{ID: model.DefaultUserID, IsAdmin: true}
+	tokens := &mockTokens{tokens: map[string]*model.APIToken{
+		// Alice isn't an admin, so her token's admin scope grants nothing
+		auth.HashToken("lnd_alice"): {ID: uuid.New(), UserID: alice.ID, Scopes: []model.Scope{model.ScopeEntriesWrite, model.ScopeAdmin}},
+		auth.HashToken("lnd_admin"): {ID: uuid.New(), UserID: admin.ID, Scopes: []model.Scope{model.ScopeAdmin}},
+	}}
+	authMW := Auth(AuthConfig{}, mockUsers{alice.ID: alice, admin.ID: admin}, &mockSessions{}, tokens, &mockEvents{}, auth.NewLimiter(auth.LimiterConfig{}))
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		bearer     string
+		scope      model.Scope
+		wantStatus int
+	}{
+		{"lnd_alice", model.ScopeEntriesWrite, http.StatusOK},
+		{"lnd_alice", model.ScopeEntriesRead, http.StatusForbidden},
+		{"lnd_alice", model.ScopeAdmin, http.StatusForbidden},
+		{"lnd_admin", model.ScopeReportsRead, http.StatusOK},
+		{"lnd_admin", model.ScopeAdmin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+tt.bearer)
+		rec := httptest.NewRecorder()
+		authMW(RequireScope(tt.scope)(ok)).ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s with %s: status = %d, want %d", tt.bearer, tt.scope, rec.Code, tt.wantStatus)
+		}
+	}
+
+	// Scopes without a user, e.g. a route mounted outside Auth, grant nothing
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := auth.WithScopes(req.Context(), []model.Scope{model.ScopeEntriesRead})
+	rec := httptest.NewRecorder()
+	RequireScope(model.ScopeEntriesRead)(ok).ServeHTTP(rec, req.WithContext(ctx))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("RequireScope without a user: status = %d, want 401", rec.Code)
+	}
+
+	// Credential management is browser-only
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer lnd_admin")
+	rec = httptest.NewRecorder()
+	authMW(RequireSession(ok)).ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("RequireSession with a token: status = %d, want 403", rec.Code)
+	}
+}
+
+func TestRequireTOTPEnrolment(t *testing.T) {
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	session := &model.Session{ID: uuid.New()}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name         string
+		user         *model.User
+		session      *model.Session
+		htmx         bool
+		wantStatus   int
+		wantRedirect string
+	}{
+		{name: "not required", user: &model.User{}, session: session, wantStatus: http.StatusOK},
+		{name: "required and enrolled", user: &model.User{TOTPRequired: true, TOTPSecret: &secret}, session: session, wantStatus: http.StatusOK},
+		{name: "required with a token", user: &model.User{TOTPRequired: true}, wantStatus: http.StatusOK},
+		{name: "required", user: &model.User{TOTPRequired: true}, session: session, wantStatus: http.StatusSeeOther},
+		{name: "required over htmx", user: &model.User{TOTPRequired: true}, session: session, htmx: true, wantStatus: http.StatusNoContent, wantRedirect: TOTPEnrolmentPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/reports", nil)
+			ctx := auth.WithUser(req.Context(), tt.user)
+			if tt.session != nil {
+				ctx = auth.WithSession(ctx, tt.session)
+			}
+			if tt.htmx {
+				req.Header.Set("HX-Request", "true")
+			}
+			rec := httptest.NewRecorder()
+			RequireTOTPEnrolment(ok).ServeHTTP(rec, req.WithContext(ctx))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusSeeOther && rec.Header().Get("Location") != TOTPEnrolmentPath {
+				t.Errorf("Location = %q, want %s", rec.Header().Get("Location"), TOTPEnrolmentPath)
+			}
+			if got := rec.Header().Get("HX-Redirect"); got != tt.wantRedirect {
+				t.Errorf("HX-Redirect = %q, want %q", got, tt.wantRedirect)
+			}
+		})
+	}
+}
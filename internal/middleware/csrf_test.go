@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/auth"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestCSRF(t *testing.T) {
+	token := auth.CSRFToken("session-token")
+	sessions := &mockSessions{sessions: map[string]*model.Session{
+		auth.HashToken("session-token"): {ID: uuid.New()},
+	}}
+
+	tests := []struct {
+		name       string
+		method     string
+		cookie     bool
+		deadCookie bool
+		header     string
+		form       url.Values
+		headers    map[string]string
+		wantStatus int
+	}{
+		{name: "get without token", method: http.MethodGet, cookie: true, wantStatus: http.StatusOK},
+		{name: "post with header token", method: http.MethodPost, cookie: true, header: token, wantStatus: http.StatusOK},
+		{name: "delete with header token", method: http.MethodDelete, cookie: true, header: token, wantStatus: http.StatusOK},
+		{name: "form post with field token", method: http.MethodPost, cookie: true, form: url.Values{auth.CSRFField: {token}}, wantStatus: http.StatusOK},
+		{name: "post without token", method: http.MethodPost, cookie: true, wantStatus: http.StatusForbidden},
+		{name: "post with wrong token", method: http.MethodPost, cookie: true, header: auth.CSRFToken("other-session"), wantStatus: http.StatusForbidden},
+		{name: "put with form token only", method: http.MethodPut, cookie: true, form: url.Values{"other": {token}}, wantStatus: http.StatusForbidden},
+		{name: "post without session", method: http.MethodPost, wantStatus: http.StatusOK},
+		{name: "sign-in with an expired session's cookie", method: http.MethodPost, deadCookie: true, wantStatus: http.StatusOK},
+		{
+			name: "bearer request without token", method: http.MethodPost, cookie: true,
+			headers:    map[string]string{"Authorization": "Bearer lnd_token"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "same-origin fetch", method: http.MethodPost, cookie: true, header: token,
+			headers:    map[string]string{"Sec-Fetch-Site": "same-origin", "Origin": "http://learnd.test"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "cross-site fetch with token", method: http.MethodPost, cookie: true, header: token,
+			headers:    map[string]string{"Sec-Fetch-Site": "cross-site"},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "foreign origin sign-in", method: http.MethodPost,
+			headers:    map[string]string{"Origin": "https://evil.example"},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotToken string
+			handler := CSRF(sessions)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotToken = auth.CSRFTokenFromContext(r.Context())
+			}))
+
+			var req *http.Request
+			if tt.form != nil {
+				req = httptest.NewRequest(tt.method, "http://learnd.test/api/entries", strings.NewReader(tt.form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				req = httptest.NewRequest(tt.method, "http://learnd.test/api/entries", nil)
+			}
+			if tt.cookie {
+				req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "session-token"})
+			}
+			if tt.deadCookie {
+				req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "expired-token"})
+			}
+			if tt.header != "" {
+				req.Header.Set(auth.CSRFHeader, tt.header)
+			}
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if rec.Code == http.StatusOK && tt.cookie && gotToken != token {
+				t.Errorf("context token = %q, want the session's", gotToken)
+			}
+		})
+	}
+}
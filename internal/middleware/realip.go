@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP sets RemoteAddr to the client's address when the request came through one of
+// the trusted proxies. Anyone can send X-Forwarded-For, so it's ignored from other
+// peers, and the client is the rightmost address in it that isn't a trusted proxy.
+// X-Real-IP is used when the proxy doesn't send X-Forwarded-For.
+func RealIP(trusted []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if client := forwardedClient(r, trusted); client != "" {
+				r.RemoteAddr = client
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedClient returns the client address a trusted proxy forwarded the request
+// for, or "" if the peer isn't trusted or didn't say
+func forwardedClient(r *http.Request, trusted []netip.Prefix) string {
+	peer, ok := parseIP(r.RemoteAddr)
+	if !ok || !isTrusted(peer, trusted) {
+		return ""
+	}
+
+	if header := r.Header.Values("X-Forwarded-For"); len(header) > 0 {
+		hops := strings.Split(strings.Join(header, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, ok := parseIP(strings.TrimSpace(hops[i]))
+			if !ok {
+				return ""
+			}
+			if !isTrusted(addr, trusted) {
+				return addr.String()
+			}
+		}
+		return ""
+	}
+
+	if addr, ok := parseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ok {
+		return addr.String()
+	}
+	return ""
+}
+
+// parseIP parses an address with or without a port
+func parseIP(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func isTrusted(addr netip.Addr, trusted []netip.Prefix) bool {
+	for _, prefix := range trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	chimw "github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span for each request. learnd is a public endpoint, so a
+// traceparent sent by the caller starts a new trace linked to it rather than being
+// continued, keeping clients from choosing trace IDs or sampling. The route pattern
+// is only known once chi has routed the request, so the span is renamed after it,
+// e.g. "GET /api/entries/{id}".
+func Tracing(next http.Handler) http.Handler {
+	routed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+		span.SetAttributes(attribute.String("request_id", chimw.GetReqID(r.Context())))
+
+		next.ServeHTTP(w, r)
+
+		if pattern := routePattern(r); pattern != "" {
+			span.SetName(r.Method + " " + pattern)
+			span.SetAttributes(semconv.HTTPRoute(pattern))
+		}
+	})
+
+	// otelhttp also renames the span with this once chi has set r.Pattern
+	return otelhttp.NewHandler(routed, "http.request",
+		otelhttp.WithPublicEndpoint(),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if pattern := routePattern(r); pattern != "" {
+				return r.Method + " " + pattern
+			}
+			return r.Method
+		}),
+	)
+}
+
+func routePattern(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		return rctx.RoutePattern()
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope is a permission granted to an API token
+type Scope string
+
+const (
+	ScopeEntriesRead  Scope = "entries:read"
+	ScopeEntriesWrite Scope = "entries:write"
+	ScopeReportsRead  Scope = "reports:read"
+	// ScopeAdmin covers the shared settings and implies every other scope
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes lists every scope in the order they're shown
+var Scopes = []Scope{ScopeEntriesRead, ScopeEntriesWrite, ScopeReportsRead, ScopeAdmin}
+
+// UserScopes returns the scopes a signed-in browser has: everything, with admin
+// only for admins
+func UserScopes(user *User) []Scope {
+	if user.IsAdmin {
+		return Scopes
+	}
+	return []Scope{ScopeEntriesRead, ScopeEntriesWrite, ScopeReportsRead}
+}
+
+// HasScope reports whether the granted scopes include scope, directly or through admin
+func HasScope(granted []Scope, scope Scope) bool {
+	return slices.Contains(granted, scope) || slices.Contains(granted, ScopeAdmin)
+}
+
+// APIToken is a named personal access token. The token itself is only shown once;
+// Prefix keeps its first characters so it can be recognized later.
+type APIToken struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []Scope    `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Expired reports whether the token has passed its expiry
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
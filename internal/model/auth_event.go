@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthEventKind is what happened in an authentication attempt
+type AuthEventKind string
+
+const (
+	AuthEventLoginSucceeded  AuthEventKind = "login_succeeded"
+	AuthEventLoginFailed     AuthEventKind = "login_failed"
+	AuthEventTwoFactorFailed AuthEventKind = "two_factor_failed"
+	AuthEventTokenRejected   AuthEventKind = "token_rejected"
+	// AuthEventLockedOut records a lockout starting, not each attempt it blocks. Its
+	// method says whether the client's address was locked out or everyone slowed down.
+	AuthEventLockedOut AuthEventKind = "locked_out"
+)
+
+// Ways of authenticating recorded with an AuthEvent
+const (
+	AuthMethodPassword    = "password"
+	AuthMethodLegacyToken = "legacy_token"
+	AuthMethodSSO         = "sso"
+	AuthMethodTwoFactor   = "two_factor"
+	AuthMethodBearer      = "bearer"
+)
+
+// AuthEvent is an entry in the authentication audit log
+type AuthEvent struct {
+	ID uuid.UUID `json:"id"`
+	// UserID is nil when the attempt didn't match an account
+	UserID *uuid.UUID    `json:"user_id,omitempty"`
+	Kind   AuthEventKind `json:"kind"`
+	Method string        `json:"method,omitempty"`
+	// Email is the address a sign-in was attempted for, as entered
+	Email     string    `json:"email,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Failed reports whether the event records a rejected attempt or a lockout
+func (e AuthEvent) Failed() bool {
+	return e.Kind != AuthEventLoginSucceeded
+}
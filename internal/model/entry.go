@@ -28,11 +28,21 @@ const (
 	StatusSkipped    ProcessingStatus = "skipped"
 )
 
+// TagStatus records whether an entry's tag was chosen by the user or proposed by auto-tagging
+type TagStatus string
+
+const (
+	TagStatusSuggested TagStatus = "suggested"
+	TagStatusConfirmed TagStatus = "confirmed"
+)
+
 // Entry represents a learning log entry
 type Entry struct {
 	ID        uuid.UUID `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// UserID is the owner; left out of exports so backups restore into any account
+	UserID uuid.UUID `json:"-"`
 
 	// User input
 	SourceURL        string   `json:"source_url"`
@@ -65,16 +75,32 @@ type Entry struct {
 	SummaryModel       *string          `json:"summary_model,omitempty"`
 	SummaryVersion     *string          `json:"summary_version,omitempty"`
 	SummaryGeneratedAt *time.Time       `json:"summary_generated_at,omitempty"`
+	SummaryInsights    *SummaryInsights `json:"summary_insights,omitempty"`
+	// SummaryBypassCache makes the next summarization skip the summary cache
+	SummaryBypassCache bool `json:"-"`
+
+	// Tag provenance
+	TagStatus TagStatus `json:"tag_status"`
+	TagSource *string   `json:"tag_source,omitempty"`
+
+	// TraceParent links the worker's spans to the request that captured the entry;
+	// only loaded for entries waiting on the worker
+	TraceParent string `json:"-"`
 }
 
 // CreateEntryInput represents input for creating a new entry
 type CreateEntryInput struct {
+	UserID           uuid.UUID
 	SourceURL        string
 	NormalizedURL    string
 	Tag              *string
 	TimeSpentSeconds *int
 	Quantity         *int
 	Notes            *string
+	// Title is the imported bookmark's title, kept unless enrichment finds one
+	Title *string
+	// CreatedAt backdates imported entries to when they were saved; nil means now
+	CreatedAt *time.Time
 }
 
 // UpdateEntryInput represents input for updating an entry
@@ -90,6 +116,20 @@ type UpdateEntryInput struct {
 	SourceType  *SourceType
 }
 
+// Difficulty levels for structured summaries
+const (
+	DifficultyBeginner     = "beginner"
+	DifficultyIntermediate = "intermediate"
+	DifficultyAdvanced     = "advanced"
+)
+
+// SummaryInsights is the structured part of an AI summary, stored alongside the summary text
+type SummaryInsights struct {
+	Takeaways     []string `json:"takeaways"`
+	Difficulty    string   `json:"difficulty,omitempty"`
+	SuggestedTags []string `json:"suggested_tags,omitempty"`
+}
+
 // SummaryCache represents a cached summary for a URL
 type SummaryCache struct {
 	ID           uuid.UUID `json:"id"`
@@ -100,4 +140,23 @@ type SummaryCache struct {
 	Model        string    `json:"model"`
 	Version      string    `json:"version"`
 	CreatedAt    time.Time `json:"created_at"`
+
+	// CacheKey identifies the URL, content, provider, model and version the summary was generated for
+	CacheKey    string     `json:"cache_key"`
+	ContentHash string     `json:"content_hash"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	HitCount    int        `json:"hit_count"`
+	LastHitAt   *time.Time `json:"last_hit_at,omitempty"`
+
+	Insights *SummaryInsights `json:"insights,omitempty"`
+}
+
+// PromptTemplate is a user-editable summary prompt for a source type.
+// Version increments on every save and is recorded in each summary's version.
+type PromptTemplate struct {
+	SourceType SourceType `json:"source_type"`
+	Template   string     `json:"template"`
+	Version    int        `json:"version"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
 }
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportJobStatus tracks a bookmark import from upload to completion
+type ImportJobStatus string
+
+const (
+	ImportJobRunning ImportJobStatus = "running"
+	ImportJobDone    ImportJobStatus = "done"
+)
+
+// ImportItemStatus records what happened to one imported link
+type ImportItemStatus string
+
+const (
+	ImportItemPending   ImportItemStatus = "pending"
+	ImportItemImported  ImportItemStatus = "imported"
+	ImportItemDuplicate ImportItemStatus = "duplicate"
+	ImportItemInvalid   ImportItemStatus = "invalid"
+	// ImportItemFailed means creating the entry kept failing and was given up on
+	ImportItemFailed ImportItemStatus = "failed"
+)
+
+// ImportJob is an uploaded bookmark export being turned into entries
+type ImportJob struct {
+	ID         uuid.UUID       `json:"id"`
+	UserID     uuid.UUID       `json:"user_id"`
+	Format     string          `json:"format"`
+	Filename   string          `json:"filename"`
+	Status     ImportJobStatus `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+
+	// Item counts by status
+	Total      int `json:"total"`
+	Pending    int `json:"pending"`
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+	Invalid    int `json:"invalid"`
+	Failed     int `json:"failed"`
+}
+
+// Processed returns how many items have been handled so far
+func (j *ImportJob) Processed() int {
+	return j.Total - j.Pending
+}
+
+// Percent returns import progress from 0 to 100
+func (j *ImportJob) Percent() int {
+	if j.Total == 0 {
+		return 100
+	}
+	return j.Processed() * 100 / j.Total
+}
+
+// ImportItem is one link from an import job
+type ImportItem struct {
+	ID       uuid.UUID        `json:"id"`
+	JobID    uuid.UUID        `json:"job_id"`
+	UserID   uuid.UUID        `json:"user_id"`
+	Position int              `json:"position"`
+	URL      string           `json:"url"`
+	Title    *string          `json:"title,omitempty"`
+	Tag      *string          `json:"tag,omitempty"`
+	Notes    *string          `json:"notes,omitempty"`
+	SavedAt  *time.Time       `json:"saved_at,omitempty"`
+	Status   ImportItemStatus `json:"status"`
+	EntryID  *uuid.UUID       `json:"entry_id,omitempty"`
+	Error    *string          `json:"error,omitempty"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CardKind distinguishes cards for a whole entry from generated question/answer cards
+type CardKind string
+
+const (
+	CardKindEntry CardKind = "entry"
+	CardKindQA    CardKind = "qa"
+)
+
+// ReviewRating is the recall grade given when reviewing a card
+type ReviewRating string
+
+const (
+	RatingAgain ReviewRating = "again"
+	RatingHard  ReviewRating = "hard"
+	RatingGood  ReviewRating = "good"
+	RatingEasy  ReviewRating = "easy"
+)
+
+// ReviewRatings lists the ratings in the order they are offered
+var ReviewRatings = []ReviewRating{RatingAgain, RatingHard, RatingGood, RatingEasy}
+
+// ReviewCard is a spaced-repetition card derived from an entry
+type ReviewCard struct {
+	ID      uuid.UUID `json:"id"`
+	EntryID uuid.UUID `json:"entry_id"`
+	Kind    CardKind  `json:"kind"`
+	Front   string    `json:"front"`
+	Back    string    `json:"back"`
+
+	// SM-2 schedule
+	Ease           float64    `json:"ease"`
+	IntervalDays   int        `json:"interval_days"`
+	Repetitions    int        `json:"repetitions"`
+	Lapses         int        `json:"lapses"`
+	DueAt          time.Time  `json:"due_at"`
+	LastReviewedAt *time.Time `json:"last_reviewed_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Source entry, filled in when listing cards for review
+	EntryTitle *string `json:"entry_title,omitempty"`
+	EntryURL   string  `json:"entry_url,omitempty"`
+}
+
+// ReviewLog records a single review of a card
+type ReviewLog struct {
+	ID                   uuid.UUID    `json:"id"`
+	CardID               uuid.UUID    `json:"card_id"`
+	Rating               ReviewRating `json:"rating"`
+	ReviewedAt           time.Time    `json:"reviewed_at"`
+	PreviousIntervalDays int          `json:"previous_interval_days"`
+	IntervalDays         int          `json:"interval_days"`
+	Ease                 float64      `json:"ease"`
+}
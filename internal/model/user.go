@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultUserID is the admin created by the users migration. It owns entries captured
+// before accounts existed and is the user the legacy API token signs in as.
+var DefaultUserID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+// User is an account with its own journal of entries
+type User struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+	// PasswordHash is an argon2id hash; nil means password login is disabled
+	PasswordHash *string `json:"-"`
+	IsAdmin      bool    `json:"is_admin"`
+	// TOTPSecret is the base32 authenticator secret; nil until two-factor is enabled
+	TOTPSecret    *string    `json:"-"`
+	TOTPEnabledAt *time.Time `json:"totp_enabled_at,omitempty"`
+	// TOTPLastStep is the time step of the last accepted code, to stop replays
+	TOTPLastStep int64 `json:"-"`
+	// TOTPRequired forces the user to enrol before using the app
+	TOTPRequired bool `json:"totp_required"`
+	// MailKey is the secret in the user's address for emailing links; nil until one is made
+	MailKey   *string   `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TOTPEnabled reports whether sign-in needs an authenticator code
+func (u *User) TOTPEnabled() bool {
+	return u.TOTPSecret != nil
+}
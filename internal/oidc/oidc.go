@@ -0,0 +1,156 @@
+// Package oidc signs users in with an external OpenID Connect identity provider
+// using the authorization code flow with PKCE.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// ErrEmailNotVerified is returned when the provider doesn't vouch for the user's email
+var ErrEmailNotVerified = errors.New("email not verified by identity provider")
+
+// ErrDomainNotAllowed is returned when the user's email isn't in an allowed domain
+var ErrDomainNotAllowed = errors.New("email domain not allowed")
+
+// Config holds the identity provider settings
+type Config struct {
+	// Issuer is the provider's URL; its discovery document is at
+	// Issuer/.well-known/openid-configuration
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is learnd's callback, registered with the provider
+	RedirectURL string
+	// AllowedDomains limits sign-in to these email domains; empty allows any
+	AllowedDomains []string
+}
+
+// Identity is a user as vouched for by the provider
+type Identity struct {
+	Issuer  string
+	Subject string
+	Email   string
+}
+
+// Provider runs the sign-in flow against one identity provider. Discovery happens on
+// first use, so learnd still starts while the provider is unreachable.
+type Provider struct {
+	cfg Config
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *gooidc.IDTokenVerifier
+}
+
+// New creates a Provider for cfg
+func New(cfg Config) *Provider {
+	for i, domain := range cfg.AllowedDomains {
+		cfg.AllowedDomains[i] = strings.ToLower(strings.TrimPrefix(domain, "@"))
+	}
+	return &Provider{cfg: cfg}
+}
+
+// CreatesUsers reports whether unknown users get an account on first sign-in. Accounts
+// are only created when sign-in is limited to allowed domains.
+func (p *Provider) CreatesUsers() bool {
+	return len(p.cfg.AllowedDomains) > 0
+}
+
+// discover fetches the provider's metadata and signing keys once it's reachable
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	provider, err := gooidc.NewProvider(ctx, p.cfg.Issuer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to discover identity provider: %w", err)
+	}
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{gooidc.ScopeOpenID, "email", "profile"},
+	}
+	p.verifier = provider.Verifier(&gooidc.Config{ClientID: p.cfg.ClientID})
+	return p.oauth, p.verifier, nil
+}
+
+// AuthCodeURL returns the provider's sign-in page for a new attempt. state and nonce
+// tie the callback and ID token to this attempt; verifier is its PKCE secret.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return oauth.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange redeems the callback's code and validates the ID token's signature, issuer,
+// audience, expiry and nonce, returning who signed in
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	oauth, idVerifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	idToken, err := idVerifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, errors.New("id token nonce doesn't match")
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to parse id token claims: %w", err)
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrEmailNotVerified
+	}
+
+	email := strings.ToLower(claims.Email)
+	if !p.allowsEmail(email) {
+		return nil, ErrDomainNotAllowed
+	}
+
+	return &Identity{
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   email,
+	}, nil
+}
+
+// allowsEmail checks email against the allowed domains
+func (p *Provider) allowsEmail(email string) bool {
+	if len(p.cfg.AllowedDomains) == 0 {
+		return true
+	}
+	_, domain, ok := strings.Cut(email, "@")
+	return ok && slices.Contains(p.cfg.AllowedDomains, domain)
+}
@@ -0,0 +1,115 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/oidc/oidctest"
+	"golang.org/x/oauth2"
+)
+
+const (
+	clientID    = "learnd"
+	redirectURL = "http://learnd.test/login/oidc/callback"
+)
+
+// signIn runs the flow up to the callback and returns the code it received
+func signIn(t *testing.T, idp *oidctest.Server, p *Provider, verifier, nonce string) string {
+	t.Helper()
+	authURL, err := p.AuthCodeURL(context.Background(), "state-1", nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	callback := idp.Authorize(t, authURL)
+	if got := callback.Query().Get("state"); got != "state-1" {
+		t.Fatalf("state = %q, want state-1", got)
+	}
+	return callback.Query().Get("code")
+}
+
+func TestExchange(t *testing.T) {
+	idp := oidctest.NewServer(t, clientID)
+	idp.Email = "Alice@Example.com"
+	p := New(Config{Issuer: idp.URL, ClientID: clientID, RedirectURL: redirectURL})
+
+	verifier := oauth2.GenerateVerifier()
+	code := signIn(t, idp, p, verifier, "nonce-1")
+
+	identity, err := p.Exchange(context.Background(), code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Identity{Issuer: idp.URL, Subject: "user-1", Email: "alice@example.com"}
+	if *identity != want {
+		t.Errorf("identity = %+v, want %+v", *identity, want)
+	}
+
+	// Codes can't be redeemed twice
+	if _, err := p.Exchange(context.Background(), code, verifier, "nonce-1"); err == nil {
+		t.Error("expected a reused code to fail")
+	}
+}
+
+func TestExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name           string
+		allowedDomains []string
+		emailVerified  bool
+		wrongVerifier  bool
+		wrongNonce     bool
+		wantErr        error
+	}{
+		{name: "wrong PKCE verifier", emailVerified: true, wrongVerifier: true},
+		{name: "wrong nonce", emailVerified: true, wrongNonce: true},
+		{name: "unverified email", wantErr: ErrEmailNotVerified},
+		{name: "domain not allowed", allowedDomains: []string{"corp.example"}, emailVerified: true, wantErr: ErrDomainNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := oidctest.NewServer(t, clientID)
+			idp.EmailVerified = tt.emailVerified
+			p := New(Config{
+				Issuer:         idp.URL,
+				ClientID:       clientID,
+				RedirectURL:    redirectURL,
+				AllowedDomains: tt.allowedDomains,
+			})
+
+			verifier := oauth2.GenerateVerifier()
+			code := signIn(t, idp, p, verifier, "nonce-1")
+
+			nonce := "nonce-1"
+			if tt.wrongVerifier {
+				verifier = oauth2.GenerateVerifier()
+			}
+			if tt.wrongNonce {
+				nonce = "nonce-2"
+			}
+
+			identity, err := p.Exchange(context.Background(), code, verifier, nonce)
+			if err == nil {
+				t.Fatalf("Exchange = %+v, want an error", identity)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllowedDomains(t *testing.T) {
+	idp := oidctest.NewServer(t, clientID)
+	idp.Email = "bob@corp.example"
+	p := New(Config{Issuer: idp.URL, ClientID: clientID, RedirectURL: redirectURL, AllowedDomains: []string{"@Corp.Example"}})
+	if !p.CreatesUsers() {
+		t.Error("expected users to be created when domains are limited")
+	}
+
+	verifier := oauth2.GenerateVerifier()
+	code := signIn(t, idp, p, verifier, "n")
+	if _, err := p.Exchange(context.Background(), code, verifier, "n"); err != nil {
+		t.Errorf("Exchange: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+// Package oidctest runs a mock OpenID Connect provider for tests. It implements
+// discovery, the authorization endpoint and the token endpoint with PKCE, and signs
+// ID tokens for whichever user is configured.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	gooidctest "github.com/coreos/go-oidc/v3/oidc/oidctest"
+)
+
+const keyID = "test-key"
+
+// Server is a mock identity provider. Set Subject, Email and EmailVerified before a
+// sign-in to choose who the provider vouches for.
+type Server struct {
+	*httptest.Server
+	ClientID string
+
+	Subject       string
+	Email         string
+	EmailVerified bool
+
+	key       *rsa.PrivateKey
+	discovery *gooidctest.Server
+
+	mu    sync.Mutex
+	codes map[string]authRequest
+}
+
+// authRequest is a pending authorization code
+type authRequest struct {
+	redirectURI string
+	nonce       string
+	challenge   string
+}
+
+// NewServer starts a provider that accepts clientID, stopped when the test ends
+func NewServer(t testing.TB, clientID string) *Server {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("oidctest: generating key: %v", err)
+	}
+
+	s := &Server{
+		ClientID:      clientID,
+		Subject:       "user-1",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		key:           key,
+		discovery: &gooidctest.Server{
+			PublicKeys: []gooidctest.PublicKey{{PublicKey: key.Public(), KeyID: keyID, Algorithm: gooidc.RS256}},
+		},
+		codes: make(map[string]authRequest),
+	}
+	s.Server = httptest.NewServer(s)
+	s.discovery.SetIssuer(s.URL)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// ServeHTTP implements the authorization and token endpoints, leaving discovery and
+// keys to go-oidc's test server
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/auth":
+		s.authorize(w, r)
+	case "/token":
+		s.token(w, r)
+	default:
+		s.discovery.ServeHTTP(w, r)
+	}
+}
+
+// Authorize follows the sign-in page URL learnd redirected to, as if the user signed
+// in, and returns the callback URL the provider sends the browser back to
+func (s *Server) Authorize(t testing.TB, authURL string) *url.URL {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("oidctest: authorize: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("oidctest: authorize status = %d", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("oidctest: invalid callback: %v", err)
+	}
+	return callback
+}
+
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	switch {
+	case q.Get("client_id") != s.ClientID:
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	case q.Get("response_type") != "code":
+		http.Error(w, "unsupported response type", http.StatusBadRequest)
+		return
+	case q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "":
+		http.Error(w, "PKCE required", http.StatusBadRequest)
+		return
+	}
+
+	code := rand.Text()
+	s.mu.Lock()
+	s.codes[code] = authRequest{
+		redirectURI: q.Get("redirect_uri"),
+		nonce:       q.Get("nonce"),
+		challenge:   q.Get("code_challenge"),
+	}
+	s.mu.Unlock()
+
+	callback, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	params := callback.Query()
+	params.Set("code", code)
+	params.Set("state", q.Get("state"))
+	callback.RawQuery = params.Encode()
+	http.Redirect(w, r, callback.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.FormValue("grant_type") != "authorization_code" {
+		tokenError(w, "invalid_request")
+		return
+	}
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.FormValue("client_id")
+	}
+	if clientID != s.ClientID {
+		tokenError(w, "invalid_client")
+		return
+	}
+
+	// Codes are single use
+	s.mu.Lock()
+	req, ok := s.codes[r.FormValue("code")]
+	delete(s.codes, r.FormValue("code"))
+	s.mu.Unlock()
+	if !ok || req.redirectURI != r.FormValue("redirect_uri") {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.FormValue("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+		tokenError(w, "invalid_grant")
+		return
+	}
+
+	now := time.Now()
+	claims, _ := json.Marshal(map[string]any{
+		"iss":            s.URL,
+		"aud":            s.ClientID,
+		"sub":            s.Subject,
+		"email":          s.Email,
+		"email_verified": s.EmailVerified,
+		"nonce":          req.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+	})
+	idToken := gooidctest.SignIDToken(s.key, keyID, gooidc.RS256, string(claims))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func tokenError(w http.ResponseWriter, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APITokenRepository handles database operations for personal access tokens
+type APITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPITokenRepository creates a new APITokenRepository
+func NewAPITokenRepository(pool *pgxpool.Pool) *APITokenRepository {
+	return &APITokenRepository{pool: pool}
+}
+
+const apiTokenColumns = `id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at`
+
+func scanAPIToken(row pgx.Row) (*model.APIToken, error) {
+	var token model.APIToken
+	var scopes []string
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.Prefix, &scopes,
+		&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		token.Scopes = append(token.Scopes, model.Scope(scope))
+	}
+	return &token, nil
+}
+
+// Create stores a new token by its hash
+func (r *APITokenRepository) Create(ctx context.Context, token *model.APIToken, tokenHash string) (*model.APIToken, error) {
+	scopes := make([]string, len(token.Scopes))
+	for i, scope := range token.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + apiTokenColumns
+
+	created, err := scanAPIToken(r.pool.QueryRow(ctx, query,
+		token.UserID, token.Name, tokenHash, token.Prefix, scopes, token.ExpiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api token: %w", err)
+	}
+	return created, nil
+}
+
+// GetByHash retrieves a token by its hash, including expired tokens
+func (r *APITokenRepository) GetByHash(ctx context.Context, tokenHash string) (*model.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = $1`
+
+	token, err := scanAPIToken(r.pool.QueryRow(ctx, query, tokenHash))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api token: %w", err)
+	}
+	return token, nil
+}
+
+// ListByUser retrieves a user's tokens, newest first
+func (r *APITokenRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.APIToken, error) {
+	query := `
+		SELECT ` + apiTokenColumns + `
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []model.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// TouchLastUsed records that a token was just used
+func (r *APITokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE api_tokens SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to touch api token: %w", err)
+	}
+	return nil
+}
+
+// Delete revokes one of a user's tokens, returning false if it doesn't exist
+func (r *APITokenRepository) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM api_tokens WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete api token: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
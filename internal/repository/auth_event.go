@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// authEventPruneInterval is how often recording an event also deletes expired ones
+const authEventPruneInterval = time.Hour
+
+// AuthEventRepository handles database operations for the authentication audit log
+type AuthEventRepository struct {
+	pool      *pgxpool.Pool
+	retention time.Duration
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewAuthEventRepository creates a new AuthEventRepository that keeps events for retention
+func NewAuthEventRepository(pool *pgxpool.Pool, retention time.Duration) *AuthEventRepository {
+	return &AuthEventRepository{pool: pool, retention: retention}
+}
+
+const authEventColumns = `id, user_id, kind, method, email, ip, user_agent, created_at`
+
+func scanAuthEvent(row pgx.Row) (*model.AuthEvent, error) {
+	var event model.AuthEvent
+	err := row.Scan(
+		&event.ID, &event.UserID, &event.Kind, &event.Method,
+		&event.Email, &event.IP, &event.UserAgent, &event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Record adds an event to the audit log, deleting events past the retention period at
+// most once an hour so a flood of failures can't grow the table without bound
+func (r *AuthEventRepository) Record(ctx context.Context, event model.AuthEvent) error {
+	if r.duePrune() {
+		if _, err := r.DeleteBefore(ctx, time.Now().Add(-r.retention)); err != nil {
+			slog.Warn("failed to prune auth events", "error", err)
+		}
+	}
+
+	query := `
+		INSERT INTO auth_events (user_id, kind, method, email, ip, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.pool.Exec(ctx, query, event.UserID, event.Kind, event.Method, event.Email, event.IP, event.UserAgent)
+	if err != nil {
+		return fmt.Errorf("failed to record auth event: %w", err)
+	}
+	return nil
+}
+
+// ListRecent retrieves the newest events, most recent first. A nil userID lists every
+// user's events, including failures that matched no account.
+func (r *AuthEventRepository) ListRecent(ctx context.Context, userID *uuid.UUID, limit int) ([]model.AuthEvent, error) {
+	query := `
+		SELECT ` + authEventColumns + `
+		FROM auth_events
+		WHERE $1::uuid IS NULL OR user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auth events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []model.AuthEvent
+	for rows.Next() {
+		event, err := scanAuthEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan auth event: %w", err)
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return events, nil
+}
+
+// DeleteBefore deletes events older than before, returning how many were removed
+func (r *AuthEventRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM auth_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete auth events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// duePrune reports whether expired events should be deleted now, claiming the prune if so
+func (r *AuthEventRepository) duePrune() bool {
+	if r.retention <= 0 {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.lastPruned) < authEventPruneInterval {
+		return false
+	}
+	r.lastPruned = time.Now()
+	return true
+}
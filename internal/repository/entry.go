@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/tracing"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -25,12 +26,13 @@ func NewEntryRepository(pool *pgxpool.Pool) *EntryRepository {
 // Create inserts a new entry
 func (r *EntryRepository) Create(ctx context.Context, input *model.CreateEntryInput) (*model.Entry, error) {
 	query := `
-		INSERT INTO entries (source_url, normalized_url, tag, time_spent_seconds, quantity, notes)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO entries (source_url, normalized_url, tag, time_spent_seconds, quantity, notes, created_at, user_id, trace_parent, title)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, NOW()), $8, $9, $10)
 		RETURNING id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
 		          canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
 		          enrichment_status, enrichment_error, enriched_at,
-		          summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at
+		          summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		          summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
 	`
 
 	var entry model.Entry
@@ -41,15 +43,11 @@ func (r *EntryRepository) Create(ctx context.Context, input *model.CreateEntryIn
 		input.TimeSpentSeconds,
 		input.Quantity,
 		input.Notes,
-	).Scan(
-		&entry.ID, &entry.CreatedAt, &entry.UpdatedAt, &entry.SourceURL, &entry.NormalizedURL, &entry.Tag,
-		&entry.TimeSpentSeconds, &entry.Quantity, &entry.Notes,
-		&entry.CanonicalURL, &entry.Domain, &entry.SourceType, &entry.Title, &entry.Description,
-		&entry.PublishedAt, &entry.RuntimeSeconds, &entry.MetadataJSON,
-		&entry.EnrichmentStatus, &entry.EnrichmentError, &entry.EnrichedAt,
-		&entry.SummaryText, &entry.SummaryStatus, &entry.SummaryError,
-		&entry.SummaryProvider, &entry.SummaryModel, &entry.SummaryVersion, &entry.SummaryGeneratedAt,
-	)
+		input.CreatedAt,
+		input.UserID,
+		tracing.TraceParent(ctx),
+		input.Title,
+	).Scan(entryScanDest(&entry)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create entry: %w", err)
 	}
@@ -57,27 +55,20 @@ func (r *EntryRepository) Create(ctx context.Context, input *model.CreateEntryIn
 	return &entry, nil
 }
 
-// GetByID retrieves an entry by ID
-func (r *EntryRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Entry, error) {
+// GetByID retrieves one of a user's entries by ID
+func (r *EntryRepository) GetByID(ctx context.Context, userID, id uuid.UUID) (*model.Entry, error) {
 	query := `
 		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
 		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
 		       enrichment_status, enrichment_error, enriched_at,
-		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
 		FROM entries
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $2
 	`
 
 	var entry model.Entry
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&entry.ID, &entry.CreatedAt, &entry.UpdatedAt, &entry.SourceURL, &entry.NormalizedURL, &entry.Tag,
-		&entry.TimeSpentSeconds, &entry.Quantity, &entry.Notes,
-		&entry.CanonicalURL, &entry.Domain, &entry.SourceType, &entry.Title, &entry.Description,
-		&entry.PublishedAt, &entry.RuntimeSeconds, &entry.MetadataJSON,
-		&entry.EnrichmentStatus, &entry.EnrichmentError, &entry.EnrichedAt,
-		&entry.SummaryText, &entry.SummaryStatus, &entry.SummaryError,
-		&entry.SummaryProvider, &entry.SummaryModel, &entry.SummaryVersion, &entry.SummaryGeneratedAt,
-	)
+	err := r.pool.QueryRow(ctx, query, id, userID).Scan(entryScanDest(&entry)...)
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
@@ -96,8 +87,8 @@ type ListOptions struct {
 	End    *time.Time
 }
 
-// List retrieves entries with pagination
-func (r *EntryRepository) List(ctx context.Context, opts ListOptions) ([]model.Entry, error) {
+// List retrieves a user's entries with pagination
+func (r *EntryRepository) List(ctx context.Context, userID uuid.UUID, opts ListOptions) ([]model.Entry, error) {
 	if opts.Limit <= 0 {
 		opts.Limit = 50
 	}
@@ -106,13 +97,14 @@ func (r *EntryRepository) List(ctx context.Context, opts ListOptions) ([]model.E
 		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
 		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
 		       enrichment_status, enrichment_error, enriched_at,
-		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
 		FROM entries
 	`
 
-	var where []string
-	var args []interface{}
-	argPos := 1
+	where := []string{"user_id = $1"}
+	args := []interface{}{userID}
+	argPos := 2
 
 	if opts.Start != nil {
 		where = append(where, fmt.Sprintf("created_at >= $%d", argPos))
@@ -124,9 +116,7 @@ func (r *EntryRepository) List(ctx context.Context, opts ListOptions) ([]model.E
 		args = append(args, *opts.End)
 		argPos++
 	}
-	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ")
-	}
+	query += " WHERE " + strings.Join(where, " AND ")
 
 	limitPos := argPos
 	offsetPos := argPos + 1
@@ -143,30 +133,24 @@ func (r *EntryRepository) List(ctx context.Context, opts ListOptions) ([]model.E
 }
 
 // Update updates an entry's user-editable fields
-func (r *EntryRepository) Update(ctx context.Context, id uuid.UUID, input *model.UpdateEntryInput) (*model.Entry, error) {
+func (r *EntryRepository) Update(ctx context.Context, userID, id uuid.UUID, input *model.UpdateEntryInput) (*model.Entry, error) {
 	query := `
 		UPDATE entries
 		SET tag = $2, time_spent_seconds = $3, quantity = $4, notes = $5,
 		    title = $6, description = $7, summary_text = $8, source_type = $9,
+		    tag_status = 'confirmed', tag_source = CASE WHEN tag IS NOT DISTINCT FROM $2 THEN tag_source END,
 		    updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $10
 		RETURNING id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
 		          canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
 		          enrichment_status, enrichment_error, enriched_at,
-		          summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at
+		          summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		          summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
 	`
 
 	var entry model.Entry
 	err := r.pool.QueryRow(ctx, query, id, input.Tag, input.TimeSpentSeconds, input.Quantity, input.Notes,
-		input.Title, input.Description, input.SummaryText, input.SourceType).Scan(
-		&entry.ID, &entry.CreatedAt, &entry.UpdatedAt, &entry.SourceURL, &entry.NormalizedURL, &entry.Tag,
-		&entry.TimeSpentSeconds, &entry.Quantity, &entry.Notes,
-		&entry.CanonicalURL, &entry.Domain, &entry.SourceType, &entry.Title, &entry.Description,
-		&entry.PublishedAt, &entry.RuntimeSeconds, &entry.MetadataJSON,
-		&entry.EnrichmentStatus, &entry.EnrichmentError, &entry.EnrichedAt,
-		&entry.SummaryText, &entry.SummaryStatus, &entry.SummaryError,
-		&entry.SummaryProvider, &entry.SummaryModel, &entry.SummaryVersion, &entry.SummaryGeneratedAt,
-	)
+		input.Title, input.Description, input.SummaryText, input.SourceType, userID).Scan(entryScanDest(&entry)...)
 	if err == pgx.ErrNoRows {
 		return nil, nil
 	}
@@ -177,20 +161,35 @@ func (r *EntryRepository) Update(ctx context.Context, id uuid.UUID, input *model
 	return &entry, nil
 }
 
-// Delete removes an entry
-func (r *EntryRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM entries WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, id)
+// UpdateTag sets and confirms an entry's tag, returning false if the entry doesn't exist
+func (r *EntryRepository) UpdateTag(ctx context.Context, userID, id uuid.UUID, tag *string) (bool, error) {
+	query := `
+		UPDATE entries
+		SET tag = $2, tag_status = 'confirmed', tag_source = CASE WHEN tag IS NOT DISTINCT FROM $2 THEN tag_source END,
+		    updated_at = NOW()
+		WHERE id = $1 AND user_id = $3
+	`
+	result, err := r.pool.Exec(ctx, query, id, tag, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update tag: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Delete removes one of a user's entries
+func (r *EntryRepository) Delete(ctx context.Context, userID, id uuid.UUID) error {
+	query := `DELETE FROM entries WHERE id = $1 AND user_id = $2`
+	_, err := r.pool.Exec(ctx, query, id, userID)
 	if err != nil {
 		return fmt.Errorf("failed to delete entry: %w", err)
 	}
 	return nil
 }
 
-// Count returns the total number of entries
-func (r *EntryRepository) Count(ctx context.Context) (int, error) {
+// Count returns the total number of a user's entries
+func (r *EntryRepository) Count(ctx context.Context, userID uuid.UUID) (int, error) {
 	var count int
-	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM entries`).Scan(&count)
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM entries WHERE user_id = $1`, userID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count entries: %w", err)
 	}
@@ -205,18 +204,18 @@ type DuplicateEntry struct {
 	Title     *string
 }
 
-// GetLatestByNormalizedURL returns the most recent entry matching a normalized URL.
-func (r *EntryRepository) GetLatestByNormalizedURL(ctx context.Context, normalizedURL string) (*DuplicateEntry, error) {
+// GetLatestByNormalizedURL returns the user's most recent entry matching a normalized URL.
+func (r *EntryRepository) GetLatestByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (*DuplicateEntry, error) {
 	query := `
 		SELECT id, created_at, source_url, title
 		FROM entries
-		WHERE normalized_url = $1
+		WHERE user_id = $1 AND normalized_url = $2
 		ORDER BY created_at DESC
 		LIMIT 1
 	`
 
 	var entry DuplicateEntry
-	err := r.pool.QueryRow(ctx, query, normalizedURL).Scan(
+	err := r.pool.QueryRow(ctx, query, userID, normalizedURL).Scan(
 		&entry.ID, &entry.CreatedAt, &entry.SourceURL, &entry.Title,
 	)
 	if err == pgx.ErrNoRows {
@@ -229,18 +228,18 @@ func (r *EntryRepository) GetLatestByNormalizedURL(ctx context.Context, normaliz
 	return &entry, nil
 }
 
-// CountByNormalizedURL returns how many entries share the normalized URL.
-func (r *EntryRepository) CountByNormalizedURL(ctx context.Context, normalizedURL string) (int, error) {
+// CountByNormalizedURL returns how many of the user's entries share the normalized URL.
+func (r *EntryRepository) CountByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) (int, error) {
 	var count int
-	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM entries WHERE normalized_url = $1`, normalizedURL).Scan(&count)
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM entries WHERE user_id = $1 AND normalized_url = $2`, userID, normalizedURL).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count duplicates: %w", err)
 	}
 	return count, nil
 }
 
-// GetDuplicateCountsByNormalizedURL returns counts for a list of normalized URLs.
-func (r *EntryRepository) GetDuplicateCountsByNormalizedURL(ctx context.Context, normalizedURLs []string) (map[string]int, error) {
+// GetDuplicateCountsByNormalizedURL returns the user's counts for a list of normalized URLs.
+func (r *EntryRepository) GetDuplicateCountsByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURLs []string) (map[string]int, error) {
 	counts := make(map[string]int)
 	if len(normalizedURLs) == 0 {
 		return counts, nil
@@ -249,11 +248,11 @@ func (r *EntryRepository) GetDuplicateCountsByNormalizedURL(ctx context.Context,
 	query := `
 		SELECT normalized_url, COUNT(*)
 		FROM entries
-		WHERE normalized_url = ANY($1)
+		WHERE user_id = $1 AND normalized_url = ANY($2)
 		GROUP BY normalized_url
 	`
 
-	rows, err := r.pool.Query(ctx, query, normalizedURLs)
+	rows, err := r.pool.Query(ctx, query, userID, normalizedURLs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to count duplicates: %w", err)
 	}
@@ -280,7 +279,8 @@ func (r *EntryRepository) GetPendingEnrichment(ctx context.Context, limit int) (
 		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
 		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
 		       enrichment_status, enrichment_error, enriched_at,
-		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id, trace_parent
 		FROM entries
 		WHERE enrichment_status = 'pending'
 		ORDER BY created_at ASC
@@ -293,7 +293,48 @@ func (r *EntryRepository) GetPendingEnrichment(ctx context.Context, limit int) (
 	}
 	defer rows.Close()
 
-	return scanEntries(rows)
+	return scanPendingEntries(rows)
+}
+
+// CountPendingEnrichment returns how many entries are waiting to be enriched
+func (r *EntryRepository) CountPendingEnrichment(ctx context.Context) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM entries WHERE enrichment_status = 'pending'`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending enrichment: %w", err)
+	}
+	return count, nil
+}
+
+// StatusCount is the number of entries in one status of a worker stage
+type StatusCount struct {
+	Stage  string
+	Status model.ProcessingStatus
+	Count  int
+}
+
+// CountByStatus counts entries by enrichment status and by summary status
+func (r *EntryRepository) CountByStatus(ctx context.Context) ([]StatusCount, error) {
+	query := `
+		SELECT 'enrichment', enrichment_status, COUNT(*) FROM entries GROUP BY enrichment_status
+		UNION ALL
+		SELECT 'summary', summary_status, COUNT(*) FROM entries GROUP BY summary_status
+	`
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entries by status: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []StatusCount
+	for rows.Next() {
+		var c StatusCount
+		if err := rows.Scan(&c.Stage, &c.Status, &c.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan status count: %w", err)
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
 }
 
 // GetPendingSummary retrieves entries pending summarization (enrichment must be complete)
@@ -302,7 +343,8 @@ func (r *EntryRepository) GetPendingSummary(ctx context.Context, limit int) ([]m
 		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
 		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
 		       enrichment_status, enrichment_error, enriched_at,
-		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id, trace_parent
 		FROM entries
 		WHERE summary_status = 'pending' AND enrichment_status = 'ok'
 		ORDER BY created_at ASC
@@ -315,22 +357,23 @@ func (r *EntryRepository) GetPendingSummary(ctx context.Context, limit int) ([]m
 	}
 	defer rows.Close()
 
-	return scanEntries(rows)
+	return scanPendingEntries(rows)
 }
 
-// ListByNormalizedURL retrieves entries matching the normalized URL.
-func (r *EntryRepository) ListByNormalizedURL(ctx context.Context, normalizedURL string) ([]model.Entry, error) {
+// ListByNormalizedURL retrieves the user's entries matching the normalized URL.
+func (r *EntryRepository) ListByNormalizedURL(ctx context.Context, userID uuid.UUID, normalizedURL string) ([]model.Entry, error) {
 	query := `
 		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
 		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
 		       enrichment_status, enrichment_error, enriched_at,
-		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
 		FROM entries
-		WHERE normalized_url = $1
+		WHERE user_id = $1 AND normalized_url = $2
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, normalizedURL)
+	rows, err := r.pool.Query(ctx, query, userID, normalizedURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list duplicates: %w", err)
 	}
@@ -360,11 +403,12 @@ func (r *EntryRepository) UpdateEnrichmentStatus(ctx context.Context, id uuid.UU
 	return nil
 }
 
-// UpdateEnrichmentResult updates enrichment result fields
+// UpdateEnrichmentResult updates enrichment result fields. An entry keeps its title,
+// such as an imported bookmark's, when enrichment didn't find one.
 func (r *EntryRepository) UpdateEnrichmentResult(ctx context.Context, id uuid.UUID, result *EnrichmentResult) error {
 	query := `
 		UPDATE entries
-		SET canonical_url = $2, domain = $3, source_type = $4, title = $5, description = $6,
+		SET canonical_url = $2, domain = $3, source_type = $4, title = COALESCE(NULLIF($5, ''), title), description = $6,
 		    published_at = $7, runtime_seconds = $8, metadata_json = $9,
 		    enrichment_status = 'ok', enrichment_error = NULL, enriched_at = NOW(), updated_at = NOW()
 		WHERE id = $1
@@ -407,17 +451,38 @@ func (r *EntryRepository) UpdateSummaryStatus(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
+// DeferSummary records a summary attempt that failed with a transient error. The
+// entry stays pending until it has been attempted maxAttempts times, then it's marked
+// failed. Reports whether it was marked failed.
+func (r *EntryRepository) DeferSummary(ctx context.Context, id uuid.UUID, errMsg string, maxAttempts int) (bool, error) {
+	query := `
+		UPDATE entries
+		SET summary_attempts = summary_attempts + 1,
+		    summary_status = CASE WHEN summary_attempts + 1 >= $3 THEN 'failed' ELSE 'pending' END,
+		    summary_error = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING summary_status
+	`
+
+	var status model.ProcessingStatus
+	if err := r.pool.QueryRow(ctx, query, id, errMsg, maxAttempts).Scan(&status); err != nil {
+		return false, fmt.Errorf("failed to defer summary: %w", err)
+	}
+	return status == model.StatusFailed, nil
+}
+
 // UpdateSummaryResult updates summary result fields
 func (r *EntryRepository) UpdateSummaryResult(ctx context.Context, id uuid.UUID, result *SummaryResult) error {
 	query := `
 		UPDATE entries
 		SET summary_text = $2, summary_provider = $3, summary_model = $4, summary_version = $5,
-		    summary_status = 'ok', summary_error = NULL, summary_generated_at = $6, updated_at = NOW()
+		    summary_status = 'ok', summary_error = NULL, summary_generated_at = $6, summary_insights = $7,
+		    summary_bypass_cache = FALSE, summary_attempts = 0, updated_at = NOW()
 		WHERE id = $1
 	`
 
 	_, err := r.pool.Exec(ctx, query, id,
-		result.Text, result.Provider, result.Model, result.Version, result.GeneratedAt,
+		result.Text, result.Provider, result.Model, result.Version, result.GeneratedAt, result.Insights,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update summary result: %w", err)
@@ -432,36 +497,285 @@ type SummaryResult struct {
 	Model       string
 	Version     string
 	GeneratedAt time.Time
+	Insights    *model.SummaryInsights
 }
 
 // ResetEnrichment resets enrichment status to pending
-func (r *EntryRepository) ResetEnrichment(ctx context.Context, id uuid.UUID) error {
+func (r *EntryRepository) ResetEnrichment(ctx context.Context, userID, id uuid.UUID) error {
 	query := `
 		UPDATE entries
 		SET enrichment_status = 'pending', enrichment_error = NULL, enriched_at = NULL, updated_at = NOW()
-		WHERE id = $1
+		WHERE id = $1 AND user_id = $2
 	`
-	_, err := r.pool.Exec(ctx, query, id)
+	_, err := r.pool.Exec(ctx, query, id, userID)
 	if err != nil {
 		return fmt.Errorf("failed to reset enrichment: %w", err)
 	}
 	return nil
 }
 
-// ResetSummary resets summary status to pending
-func (r *EntryRepository) ResetSummary(ctx context.Context, id uuid.UUID) error {
+// ResetSummary resets summary status to pending. With bypassCache set, the worker
+// regenerates the summary instead of reusing a cached one.
+func (r *EntryRepository) ResetSummary(ctx context.Context, userID, id uuid.UUID, bypassCache bool) error {
 	query := `
 		UPDATE entries
-		SET summary_status = 'pending', summary_error = NULL, summary_generated_at = NULL, updated_at = NOW()
-		WHERE id = $1
+		SET summary_status = 'pending', summary_error = NULL, summary_generated_at = NULL,
+		    summary_bypass_cache = $2, summary_attempts = 0, updated_at = NOW()
+		WHERE id = $1 AND user_id = $3
 	`
-	_, err := r.pool.Exec(ctx, query, id)
+	_, err := r.pool.Exec(ctx, query, id, bypassCache, userID)
 	if err != nil {
 		return fmt.Errorf("failed to reset summary: %w", err)
 	}
 	return nil
 }
 
+// RequeueSummariesByProvider resets completed summaries from the given provider to pending
+// so they are regenerated by the current summarizer. Returns the number of entries requeued.
+func (r *EntryRepository) RequeueSummariesByProvider(ctx context.Context, provider string) (int64, error) {
+	query := `
+		UPDATE entries
+		SET summary_status = 'pending', summary_error = NULL, updated_at = NOW()
+		WHERE summary_provider = $1 AND summary_status = 'ok'
+	`
+	tag, err := r.pool.Exec(ctx, query, provider)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue summaries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// outdatedPromptFilter matches completed LLM summaries of a source type whose
+// "+prompt.N" version suffix differs from the current template version
+const outdatedPromptFilter = `
+		summary_status = 'ok' AND source_type = $1 AND summary_provider <> 'local'
+		AND COALESCE(substring(summary_version from '\+prompt\.(\d+)$')::int, 0) <> $2
+	`
+
+// CountOutdatedPromptSummaries counts summaries of a source type generated with
+// a prompt template version other than the given one (0 is the built-in default)
+func (r *EntryRepository) CountOutdatedPromptSummaries(ctx context.Context, sourceType model.SourceType, version int) (int, error) {
+	query := `SELECT COUNT(*) FROM entries WHERE` + outdatedPromptFilter
+
+	var count int
+	err := r.pool.QueryRow(ctx, query, sourceType, version).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count outdated summaries: %w", err)
+	}
+	return count, nil
+}
+
+// RequeueOutdatedPromptSummaries resets summaries generated with an outdated prompt
+// template version to pending. Returns the number of entries requeued.
+func (r *EntryRepository) RequeueOutdatedPromptSummaries(ctx context.Context, sourceType model.SourceType, version int) (int64, error) {
+	query := `
+		UPDATE entries
+		SET summary_status = 'pending', summary_error = NULL, updated_at = NOW()
+		WHERE` + outdatedPromptFilter
+
+	tag, err := r.pool.Exec(ctx, query, sourceType, version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue outdated summaries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListTags returns the distinct tags a user has in use, most used first
+func (r *EntryRepository) ListTags(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `
+		SELECT tag
+		FROM entries
+		WHERE user_id = $1 AND tag IS NOT NULL AND tag <> ''
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetPendingAutoTag retrieves enriched, untagged entries that auto-tagging hasn't looked at yet
+func (r *EntryRepository) GetPendingAutoTag(ctx context.Context, limit int) ([]model.Entry, error) {
+	query := `
+		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
+		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
+		       enrichment_status, enrichment_error, enriched_at,
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
+		FROM entries
+		WHERE tag IS NULL AND tag_checked_at IS NULL AND enrichment_status = 'ok'
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending auto-tag: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// SaveTagSuggestion marks an entry as checked by auto-tagging and stores the
+// suggested tag, if any. Entries the user tagged in the meantime keep their tag.
+func (r *EntryRepository) SaveTagSuggestion(ctx context.Context, id uuid.UUID, tag *string, source string) error {
+	if tag == nil {
+		query := `UPDATE entries SET tag_checked_at = NOW() WHERE id = $1`
+		if _, err := r.pool.Exec(ctx, query, id); err != nil {
+			return fmt.Errorf("failed to mark tag checked: %w", err)
+		}
+		return nil
+	}
+
+	query := `
+		UPDATE entries
+		SET tag = $2, tag_status = 'suggested', tag_source = $3, tag_checked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND tag IS NULL
+	`
+	if _, err := r.pool.Exec(ctx, query, id, *tag, source); err != nil {
+		return fmt.Errorf("failed to save tag suggestion: %w", err)
+	}
+	return nil
+}
+
+// ListSuggestedTags retrieves a user's entries whose tag is awaiting review, newest first
+func (r *EntryRepository) ListSuggestedTags(ctx context.Context, userID uuid.UUID, limit int) ([]model.Entry, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
+		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
+		       enrichment_status, enrichment_error, enriched_at,
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
+		FROM entries
+		WHERE user_id = $1 AND tag_status = 'suggested'
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suggested tags: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// ConfirmTags confirms suggested tags, applying any edits made during review.
+// Returns the number of entries confirmed.
+func (r *EntryRepository) ConfirmTags(ctx context.Context, userID uuid.UUID, tags map[uuid.UUID]string) (int64, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(tags))
+	values := make([]string, 0, len(tags))
+	for id, tag := range tags {
+		ids = append(ids, id)
+		values = append(values, tag)
+	}
+
+	query := `
+		UPDATE entries e
+		SET tag = v.tag, tag_status = 'confirmed',
+		    tag_source = CASE WHEN e.tag = v.tag THEN e.tag_source END,
+		    updated_at = NOW()
+		FROM unnest($1::uuid[], $2::text[]) AS v(id, tag)
+		WHERE e.id = v.id AND e.user_id = $3 AND e.tag_status = 'suggested'
+	`
+	result, err := r.pool.Exec(ctx, query, ids, values, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to confirm tags: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// RejectTags clears suggested tags. The entries stay checked so they aren't suggested again.
+// Returns the number of entries cleared.
+func (r *EntryRepository) RejectTags(ctx context.Context, userID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		UPDATE entries
+		SET tag = NULL, tag_status = 'confirmed', tag_source = NULL, updated_at = NOW()
+		WHERE id = ANY($1) AND user_id = $2 AND tag_status = 'suggested'
+	`
+	result, err := r.pool.Exec(ctx, query, ids, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reject tags: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// DomainTag is the most common confirmed tag for a domain
+type DomainTag struct {
+	Domain string
+	Tag    string
+	Count  int
+	Total  int
+}
+
+// TagsByDomain returns each domain's most common confirmed tag in a user's entries,
+// with how many of the domain's tagged entries use it
+func (r *EntryRepository) TagsByDomain(ctx context.Context, userID uuid.UUID) ([]DomainTag, error) {
+	query := `
+		SELECT domain, tag, count, total
+		FROM (
+			SELECT domain, tag, COUNT(*)::int AS count,
+			       SUM(COUNT(*)) OVER (PARTITION BY domain)::int AS total,
+			       ROW_NUMBER() OVER (PARTITION BY domain ORDER BY COUNT(*) DESC, tag) AS rank
+			FROM entries
+			WHERE user_id = $1 AND domain IS NOT NULL AND tag IS NOT NULL AND tag <> '' AND tag_status = 'confirmed'
+			GROUP BY domain, tag
+		) ranked
+		WHERE rank = 1
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags by domain: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []DomainTag
+	for rows.Next() {
+		var dt DomainTag
+		if err := rows.Scan(&dt.Domain, &dt.Tag, &dt.Count, &dt.Total); err != nil {
+			return nil, fmt.Errorf("failed to scan domain tag: %w", err)
+		}
+		tags = append(tags, dt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return tags, nil
+}
+
 // TagAggregation represents aggregated stats for a tag
 type TagAggregation struct {
 	Tag         string
@@ -482,17 +796,19 @@ type ReportTotals struct {
 	TotalTimeSeconds int
 }
 
-// AggregateByTag returns entry counts and time aggregated by tag for a date range
-func (r *EntryRepository) AggregateByTag(ctx context.Context, start, end time.Time) ([]TagAggregation, error) {
+// AggregateByTag returns a user's entry counts and time aggregated by tag for a date
+// range. Suggested tags count only once they're accepted.
+func (r *EntryRepository) AggregateByTag(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]TagAggregation, error) {
 	query := `
 		SELECT tag, COUNT(*), COALESCE(SUM(COALESCE(time_spent_seconds, runtime_seconds, 0)), 0)::int
 		FROM entries
-		WHERE created_at >= $1 AND created_at <= $2 AND tag IS NOT NULL AND tag != ''
+		WHERE user_id = $1 AND created_at >= $2 AND created_at <= $3 AND tag IS NOT NULL AND tag != ''
+		  AND tag_status = 'confirmed'
 		GROUP BY tag
 		ORDER BY COUNT(*) DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, start, end)
+	rows, err := r.pool.Query(ctx, query, userID, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to aggregate by tag: %w", err)
 	}
@@ -513,17 +829,17 @@ func (r *EntryRepository) AggregateByTag(ctx context.Context, start, end time.Ti
 	return results, nil
 }
 
-// AggregateByType returns entry counts and time aggregated by source type for a date range
-func (r *EntryRepository) AggregateByType(ctx context.Context, start, end time.Time) ([]TypeAggregation, error) {
+// AggregateByType returns a user's entry counts and time aggregated by source type for a date range
+func (r *EntryRepository) AggregateByType(ctx context.Context, userID uuid.UUID, start, end time.Time) ([]TypeAggregation, error) {
 	query := `
 		SELECT source_type, COUNT(*), COALESCE(SUM(COALESCE(time_spent_seconds, runtime_seconds, 0)), 0)::int
 		FROM entries
-		WHERE created_at >= $1 AND created_at <= $2
+		WHERE user_id = $1 AND created_at >= $2 AND created_at <= $3
 		GROUP BY source_type
 		ORDER BY COUNT(*) DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, start, end)
+	rows, err := r.pool.Query(ctx, query, userID, start, end)
 	if err != nil {
 		return nil, fmt.Errorf("failed to aggregate by type: %w", err)
 	}
@@ -544,16 +860,16 @@ func (r *EntryRepository) AggregateByType(ctx context.Context, start, end time.T
 	return results, nil
 }
 
-// GetReportTotals returns total entry count and time for a date range
-func (r *EntryRepository) GetReportTotals(ctx context.Context, start, end time.Time) (*ReportTotals, error) {
+// GetReportTotals returns a user's total entry count and time for a date range
+func (r *EntryRepository) GetReportTotals(ctx context.Context, userID uuid.UUID, start, end time.Time) (*ReportTotals, error) {
 	query := `
 		SELECT COUNT(*), COALESCE(SUM(COALESCE(time_spent_seconds, runtime_seconds, 0)), 0)::int
 		FROM entries
-		WHERE created_at >= $1 AND created_at <= $2
+		WHERE user_id = $1 AND created_at >= $2 AND created_at <= $3
 	`
 
 	var totals ReportTotals
-	err := r.pool.QueryRow(ctx, query, start, end).Scan(&totals.TotalEntries, &totals.TotalTimeSeconds)
+	err := r.pool.QueryRow(ctx, query, userID, start, end).Scan(&totals.TotalEntries, &totals.TotalTimeSeconds)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get report totals: %w", err)
 	}
@@ -566,19 +882,37 @@ func scanEntries(rows pgx.Rows) ([]model.Entry, error) {
 	var entries []model.Entry
 	for rows.Next() {
 		var entry model.Entry
-		err := rows.Scan(
-			&entry.ID, &entry.CreatedAt, &entry.UpdatedAt, &entry.SourceURL, &entry.NormalizedURL, &entry.Tag,
-			&entry.TimeSpentSeconds, &entry.Quantity, &entry.Notes,
-			&entry.CanonicalURL, &entry.Domain, &entry.SourceType, &entry.Title, &entry.Description,
-			&entry.PublishedAt, &entry.RuntimeSeconds, &entry.MetadataJSON,
-			&entry.EnrichmentStatus, &entry.EnrichmentError, &entry.EnrichedAt,
-			&entry.SummaryText, &entry.SummaryStatus, &entry.SummaryError,
-			&entry.SummaryProvider, &entry.SummaryModel, &entry.SummaryVersion, &entry.SummaryGeneratedAt,
-		)
-		if err != nil {
+		if err := rows.Scan(entryScanDest(&entry)...); err != nil {
+			return nil, fmt.Errorf("failed to scan entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// scanPendingEntries scans rows of entry columns followed by trace_parent
+func scanPendingEntries(rows pgx.Rows) ([]model.Entry, error) {
+	var entries []model.Entry
+	for rows.Next() {
+		var entry model.Entry
+		if err := rows.Scan(append(entryScanDest(&entry), &entry.TraceParent)...); err != nil {
 			return nil, fmt.Errorf("failed to scan entry: %w", err)
 		}
 		entries = append(entries, entry)
 	}
 	return entries, nil
 }
+
+// entryScanDest lists the scan destinations for the entry columns, in the order queries select them
+func entryScanDest(entry *model.Entry) []any {
+	return []any{
+		&entry.ID, &entry.CreatedAt, &entry.UpdatedAt, &entry.SourceURL, &entry.NormalizedURL, &entry.Tag,
+		&entry.TimeSpentSeconds, &entry.Quantity, &entry.Notes,
+		&entry.CanonicalURL, &entry.Domain, &entry.SourceType, &entry.Title, &entry.Description,
+		&entry.PublishedAt, &entry.RuntimeSeconds, &entry.MetadataJSON,
+		&entry.EnrichmentStatus, &entry.EnrichmentError, &entry.EnrichedAt,
+		&entry.SummaryText, &entry.SummaryStatus, &entry.SummaryError,
+		&entry.SummaryProvider, &entry.SummaryModel, &entry.SummaryVersion, &entry.SummaryGeneratedAt,
+		&entry.SummaryInsights, &entry.SummaryBypassCache, &entry.TagStatus, &entry.TagSource, &entry.UserID,
+	}
+}
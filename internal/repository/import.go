@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Import actions reported per entry
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+)
+
+// ImportChange describes what an import does to one entry
+type ImportChange struct {
+	ID     uuid.UUID `json:"id"`
+	Action string    `json:"action"`
+	Fields []string  `json:"fields,omitempty"`
+}
+
+// ErrEntryOwnedByOtherUser is returned when an imported entry's ID belongs to another user's journal
+var ErrEntryOwnedByOtherUser = errors.New("entry belongs to another user")
+
+// ImportResult summarizes an import
+type ImportResult struct {
+	DryRun    bool           `json:"dry_run"`
+	Created   int            `json:"created"`
+	Updated   int            `json:"updated"`
+	Unchanged int            `json:"unchanged"`
+	Changes   []ImportChange `json:"changes"`
+}
+
+// ImportEntries restores exported entries into a user's journal by ID in a single transaction,
+// inserting missing entries and overwriting changed ones with every exported field, including
+// timestamps. With dryRun the transaction is rolled back after computing the changes.
+func (r *EntryRepository) ImportEntries(ctx context.Context, userID uuid.UUID, entries []model.Entry, dryRun bool) (*ImportResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result := &ImportResult{DryRun: dryRun, Changes: []ImportChange{}}
+	for i := range entries {
+		entry := &entries[i]
+		entry.UserID = userID
+
+		existing, err := getEntryForUpdate(ctx, tx, entry.ID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.UserID != userID {
+			return nil, fmt.Errorf("failed to import entry %s: %w", entry.ID, ErrEntryOwnedByOtherUser)
+		}
+
+		change := ImportChange{ID: entry.ID, Action: ImportCreate}
+		if existing != nil {
+			change.Fields, err = changedEntryFields(existing, entry)
+			if err != nil {
+				return nil, err
+			}
+			if len(change.Fields) == 0 {
+				result.Unchanged++
+				continue
+			}
+			change.Action = ImportUpdate
+		}
+
+		if err := upsertEntry(ctx, tx, entry); err != nil {
+			return nil, fmt.Errorf("failed to import entry %s: %w", entry.ID, err)
+		}
+
+		if change.Action == ImportCreate {
+			result.Created++
+		} else {
+			result.Updated++
+		}
+		result.Changes = append(result.Changes, change)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return result, nil
+}
+
+func getEntryForUpdate(ctx context.Context, tx pgx.Tx, id uuid.UUID) (*model.Entry, error) {
+	query := `
+		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
+		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
+		       enrichment_status, enrichment_error, enriched_at,
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
+		FROM entries
+		WHERE id = $1
+		FOR UPDATE
+	`
+
+	var entry model.Entry
+	err := tx.QueryRow(ctx, query, id).Scan(entryScanDest(&entry)...)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func upsertEntry(ctx context.Context, tx pgx.Tx, entry *model.Entry) error {
+	query := `
+		INSERT INTO entries (id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
+		                     canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
+		                     enrichment_status, enrichment_error, enriched_at,
+		                     summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		                     summary_insights, tag_status, tag_source, user_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+		        $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31)
+		ON CONFLICT (id) DO UPDATE SET
+			created_at = EXCLUDED.created_at, updated_at = EXCLUDED.updated_at,
+			source_url = EXCLUDED.source_url, normalized_url = EXCLUDED.normalized_url, tag = EXCLUDED.tag,
+			time_spent_seconds = EXCLUDED.time_spent_seconds, quantity = EXCLUDED.quantity, notes = EXCLUDED.notes,
+			canonical_url = EXCLUDED.canonical_url, domain = EXCLUDED.domain, source_type = EXCLUDED.source_type,
+			title = EXCLUDED.title, description = EXCLUDED.description, published_at = EXCLUDED.published_at,
+			runtime_seconds = EXCLUDED.runtime_seconds, metadata_json = EXCLUDED.metadata_json,
+			enrichment_status = EXCLUDED.enrichment_status, enrichment_error = EXCLUDED.enrichment_error,
+			enriched_at = EXCLUDED.enriched_at,
+			summary_text = EXCLUDED.summary_text, summary_status = EXCLUDED.summary_status,
+			summary_error = EXCLUDED.summary_error, summary_provider = EXCLUDED.summary_provider,
+			summary_model = EXCLUDED.summary_model, summary_version = EXCLUDED.summary_version,
+			summary_generated_at = EXCLUDED.summary_generated_at, summary_insights = EXCLUDED.summary_insights,
+			tag_status = EXCLUDED.tag_status, tag_source = EXCLUDED.tag_source
+		WHERE entries.user_id = EXCLUDED.user_id
+	`
+
+	_, err := tx.Exec(ctx, query,
+		entry.ID, entry.CreatedAt, entry.UpdatedAt, entry.SourceURL, entry.NormalizedURL, entry.Tag,
+		entry.TimeSpentSeconds, entry.Quantity, entry.Notes,
+		entry.CanonicalURL, entry.Domain, entry.SourceType, entry.Title, entry.Description,
+		entry.PublishedAt, entry.RuntimeSeconds, entry.MetadataJSON,
+		entry.EnrichmentStatus, entry.EnrichmentError, entry.EnrichedAt,
+		entry.SummaryText, entry.SummaryStatus, entry.SummaryError,
+		entry.SummaryProvider, entry.SummaryModel, entry.SummaryVersion, entry.SummaryGeneratedAt,
+		entry.SummaryInsights, entry.TagStatus, entry.TagSource, entry.UserID,
+	)
+	return err
+}
+
+// changedEntryFields lists the exported JSON fields that differ between two entries
+func changedEntryFields(existing, incoming *model.Entry) ([]string, error) {
+	before, err := entryFields(existing)
+	if err != nil {
+		return nil, err
+	}
+	after, err := entryFields(incoming)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for name, value := range after {
+		if string(before[name]) != string(value) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func entryFields(entry *model.Entry) (map[string]json.RawMessage, error) {
+	// Compare instants rather than how the time zone was rendered
+	e := *entry
+	e.CreatedAt = e.CreatedAt.UTC()
+	e.UpdatedAt = e.UpdatedAt.UTC()
+	for _, t := range []**time.Time{&e.PublishedAt, &e.EnrichedAt, &e.SummaryGeneratedAt} {
+		if *t != nil {
+			utc := (*t).UTC()
+			*t = &utc
+		}
+	}
+
+	data, err := json.Marshal(&e)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entry: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode entry: %w", err)
+	}
+	return fields, nil
+}
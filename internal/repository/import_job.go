@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ImportJobRepository handles database operations for bookmark import jobs
+type ImportJobRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewImportJobRepository creates a new ImportJobRepository
+func NewImportJobRepository(pool *pgxpool.Pool) *ImportJobRepository {
+	return &ImportJobRepository{pool: pool}
+}
+
+const importJobColumns = `j.id, j.user_id, j.format, j.filename, j.status, j.created_at, j.finished_at,
+		       COUNT(i.id),
+		       COUNT(i.id) FILTER (WHERE i.status = 'pending'),
+		       COUNT(i.id) FILTER (WHERE i.status = 'imported'),
+		       COUNT(i.id) FILTER (WHERE i.status = 'duplicate'),
+		       COUNT(i.id) FILTER (WHERE i.status = 'invalid'),
+		       COUNT(i.id) FILTER (WHERE i.status = 'failed')`
+
+func scanImportJob(row pgx.Row) (*model.ImportJob, error) {
+	var job model.ImportJob
+	err := row.Scan(
+		&job.ID, &job.UserID, &job.Format, &job.Filename, &job.Status, &job.CreatedAt, &job.FinishedAt,
+		&job.Total, &job.Pending, &job.Imported, &job.Duplicates, &job.Invalid, &job.Failed,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CreateJob stores an import job with one pending item per link, in file order
+func (r *ImportJobRepository) CreateJob(ctx context.Context, job *model.ImportJob, items []model.ImportItem) (*model.ImportJob, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	created := *job
+	err = tx.QueryRow(ctx, `
+		INSERT INTO import_jobs (user_id, format, filename)
+		VALUES ($1, $2, $3)
+		RETURNING id, status, created_at
+	`, job.UserID, job.Format, job.Filename).Scan(&created.ID, &created.Status, &created.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	rows := make([][]any, len(items))
+	for i, item := range items {
+		rows[i] = []any{created.ID, i, item.URL, item.Title, item.Tag, item.Notes, item.SavedAt}
+	}
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"import_items"},
+		[]string{"job_id", "position", "url", "title", "tag", "notes", "saved_at"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create import items: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	created.Total = len(items)
+	created.Pending = len(items)
+	return &created, nil
+}
+
+// GetJob retrieves one of a user's import jobs with its item counts
+func (r *ImportJobRepository) GetJob(ctx context.Context, userID, id uuid.UUID) (*model.ImportJob, error) {
+	query := `
+		SELECT ` + importJobColumns + `
+		FROM import_jobs j
+		LEFT JOIN import_items i ON i.job_id = j.id
+		WHERE j.id = $1 AND j.user_id = $2
+		GROUP BY j.id
+	`
+
+	job, err := scanImportJob(r.pool.QueryRow(ctx, query, id, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobs retrieves a user's most recent import jobs with their item counts
+func (r *ImportJobRepository) ListJobs(ctx context.Context, userID uuid.UUID, limit int) ([]model.ImportJob, error) {
+	query := `
+		SELECT ` + importJobColumns + `
+		FROM import_jobs j
+		LEFT JOIN import_items i ON i.job_id = j.id
+		WHERE j.user_id = $1
+		GROUP BY j.id
+		ORDER BY j.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []model.ImportJob
+	for rows.Next() {
+		job, err := scanImportJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan import job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return jobs, nil
+}
+
+// GetPendingItems retrieves unprocessed items from running jobs, oldest job first and in file order
+func (r *ImportJobRepository) GetPendingItems(ctx context.Context, limit int) ([]model.ImportItem, error) {
+	query := `
+		SELECT i.id, i.job_id, j.user_id, i.position, i.url, i.title, i.tag, i.notes, i.saved_at, i.status, i.entry_id, i.error
+		FROM import_items i
+		JOIN import_jobs j ON j.id = i.job_id
+		WHERE i.status = 'pending' AND j.status = 'running'
+		ORDER BY j.created_at ASC, i.position ASC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending import items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.ImportItem
+	for rows.Next() {
+		var item model.ImportItem
+		if err := rows.Scan(
+			&item.ID, &item.JobID, &item.UserID, &item.Position, &item.URL, &item.Title, &item.Tag, &item.Notes, &item.SavedAt,
+			&item.Status, &item.EntryID, &item.Error,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan import item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+	return items, nil
+}
+
+// MarkItem records the outcome of importing one item
+func (r *ImportJobRepository) MarkItem(ctx context.Context, id uuid.UUID, status model.ImportItemStatus, entryID *uuid.UUID, errMsg *string) error {
+	query := `UPDATE import_items SET status = $2, entry_id = $3, error = $4 WHERE id = $1`
+
+	if _, err := r.pool.Exec(ctx, query, id, status, entryID, errMsg); err != nil {
+		return fmt.Errorf("failed to mark import item: %w", err)
+	}
+	return nil
+}
+
+// RetryItem records a failed attempt to import an item. It stays pending until it has
+// been attempted maxAttempts times, then it's marked failed. Reports whether it was.
+func (r *ImportJobRepository) RetryItem(ctx context.Context, id uuid.UUID, errMsg string, maxAttempts int) (bool, error) {
+	query := `
+		UPDATE import_items
+		SET attempts = attempts + 1,
+		    status = CASE WHEN attempts + 1 >= $3 THEN 'failed' ELSE 'pending' END,
+		    error = $2
+		WHERE id = $1
+		RETURNING status
+	`
+
+	var status model.ImportItemStatus
+	if err := r.pool.QueryRow(ctx, query, id, errMsg, maxAttempts).Scan(&status); err != nil {
+		return false, fmt.Errorf("failed to retry import item: %w", err)
+	}
+	return status == model.ImportItemFailed, nil
+}
+
+// FinishCompletedJobs marks running jobs with no pending items as done
+func (r *ImportJobRepository) FinishCompletedJobs(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE import_jobs j
+		SET status = 'done', finished_at = NOW()
+		WHERE j.status = 'running'
+		  AND NOT EXISTS (SELECT 1 FROM import_items i WHERE i.job_id = j.id AND i.status = 'pending')
+	`
+
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to finish import jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+)
+
+func TestChangedEntryFields(t *testing.T) {
+	title := "Go generics"
+	newTitle := "Go generics, revisited"
+	created := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	base := model.Entry{
+		ID:            uuid.MustParse("550e8400-e29b-41d4-a716-446655440000"),
+		CreatedAt:     created,
+		UpdatedAt:     created,
+		SourceURL:     "https://go.dev/blog/intro-generics",
+		Title:         &title,
+		SummaryStatus: model.StatusOK,
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		incoming := base
+		// Same instant rendered in another time zone
+		incoming.CreatedAt = created.In(time.FixedZone("CET", 3600))
+		fields, err := changedEntryFields(&base, &incoming)
+		if err != nil {
+			t.Fatalf("changedEntryFields() error = %v", err)
+		}
+		if len(fields) != 0 {
+			t.Errorf("changedEntryFields() = %v, want none", fields)
+		}
+	})
+
+	t.Run("changed and removed fields", func(t *testing.T) {
+		incoming := base
+		incoming.Title = &newTitle
+		incoming.SummaryStatus = model.StatusPending
+		existing := base
+		notes := "old notes"
+		existing.Notes = &notes
+
+		fields, err := changedEntryFields(&existing, &incoming)
+		if err != nil {
+			t.Fatalf("changedEntryFields() error = %v", err)
+		}
+		want := []string{"notes", "summary_status", "title"}
+		if !reflect.DeepEqual(fields, want) {
+			t.Errorf("changedEntryFields() = %v, want %v", fields, want)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PromptTemplateRepository handles database operations for prompt templates
+type PromptTemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPromptTemplateRepository creates a new PromptTemplateRepository
+func NewPromptTemplateRepository(pool *pgxpool.Pool) *PromptTemplateRepository {
+	return &PromptTemplateRepository{pool: pool}
+}
+
+// List retrieves all saved prompt templates keyed by source type
+func (r *PromptTemplateRepository) List(ctx context.Context) (map[model.SourceType]*model.PromptTemplate, error) {
+	query := `
+		SELECT source_type, template, version, created_at, updated_at
+		FROM prompt_templates
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make(map[model.SourceType]*model.PromptTemplate)
+	for rows.Next() {
+		var t model.PromptTemplate
+		if err := rows.Scan(&t.SourceType, &t.Template, &t.Version, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan prompt template: %w", err)
+		}
+		templates[t.SourceType] = &t
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate prompt templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Get retrieves the prompt template for a source type
+func (r *PromptTemplateRepository) Get(ctx context.Context, sourceType model.SourceType) (*model.PromptTemplate, error) {
+	query := `
+		SELECT source_type, template, version, created_at, updated_at
+		FROM prompt_templates
+		WHERE source_type = $1
+	`
+
+	var t model.PromptTemplate
+	err := r.pool.QueryRow(ctx, query, sourceType).Scan(&t.SourceType, &t.Template, &t.Version, &t.CreatedAt, &t.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt template: %w", err)
+	}
+
+	return &t, nil
+}
+
+// Save stores the template for a source type, incrementing its version
+func (r *PromptTemplateRepository) Save(ctx context.Context, sourceType model.SourceType, template string) (*model.PromptTemplate, error) {
+	query := `
+		INSERT INTO prompt_templates (source_type, template)
+		VALUES ($1, $2)
+		ON CONFLICT (source_type) DO UPDATE SET
+			template = EXCLUDED.template,
+			version = prompt_templates.version + 1,
+			updated_at = NOW()
+		RETURNING source_type, template, version, created_at, updated_at
+	`
+
+	var t model.PromptTemplate
+	err := r.pool.QueryRow(ctx, query, sourceType, template).Scan(&t.SourceType, &t.Template, &t.Version, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save prompt template: %w", err)
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReviewRepository handles database operations for review cards and logs
+type ReviewRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewReviewRepository creates a new ReviewRepository
+func NewReviewRepository(pool *pgxpool.Pool) *ReviewRepository {
+	return &ReviewRepository{pool: pool}
+}
+
+// ReviewTotals counts reviews in a date range
+type ReviewTotals struct {
+	Reviews  int
+	Retained int
+}
+
+// Retention returns the fraction of reviews that weren't forgotten
+func (t *ReviewTotals) Retention() float64 {
+	if t.Reviews == 0 {
+		return 0
+	}
+	return float64(t.Retained) / float64(t.Reviews)
+}
+
+const reviewCardColumns = `c.id, c.entry_id, c.kind, c.front, c.back,
+		       c.ease, c.interval_days, c.repetitions, c.lapses, c.due_at, c.last_reviewed_at,
+		       c.created_at, c.updated_at, e.title, e.source_url`
+
+func scanReviewCard(row pgx.Row) (*model.ReviewCard, error) {
+	var card model.ReviewCard
+	err := row.Scan(
+		&card.ID, &card.EntryID, &card.Kind, &card.Front, &card.Back,
+		&card.Ease, &card.IntervalDays, &card.Repetitions, &card.Lapses, &card.DueAt, &card.LastReviewedAt,
+		&card.CreatedAt, &card.UpdatedAt, &card.EntryTitle, &card.EntryURL,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// GetEntriesNeedingCards retrieves summarized entries that have no review cards for
+// their current summary, either because they have none yet or because they've been
+// summarized again since their cards were generated
+func (r *ReviewRepository) GetEntriesNeedingCards(ctx context.Context, limit int) ([]model.Entry, error) {
+	query := `
+		SELECT id, created_at, updated_at, source_url, normalized_url, tag, time_spent_seconds, quantity, notes,
+		       canonical_url, domain, source_type, title, description, published_at, runtime_seconds, metadata_json,
+		       enrichment_status, enrichment_error, enriched_at,
+		       summary_text, summary_status, summary_error, summary_provider, summary_model, summary_version, summary_generated_at,
+		       summary_insights, summary_bypass_cache, tag_status, tag_source, user_id
+		FROM entries e
+		WHERE summary_status = 'ok' AND summary_text IS NOT NULL AND summary_text <> ''
+		  AND NOT EXISTS (
+		      SELECT 1 FROM review_cards c
+		      WHERE c.entry_id = e.id AND c.summary_generated_at IS NOT DISTINCT FROM e.summary_generated_at
+		  )
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries needing cards: %w", err)
+	}
+	defer rows.Close()
+
+	return scanEntries(rows)
+}
+
+// SaveCards replaces an entry's cards with those generated from its current summary.
+// Cards whose front is unchanged keep their schedule and review history; the rest of
+// the entry's old cards are deleted along with their reviews.
+func (r *ReviewRepository) SaveCards(ctx context.Context, entry *model.Entry, cards []model.ReviewCard) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	upsertQuery := `
+		INSERT INTO review_cards (entry_id, kind, front, back, ease, summary_generated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (entry_id, kind, front) DO UPDATE
+		SET back = EXCLUDED.back, summary_generated_at = EXCLUDED.summary_generated_at, updated_at = NOW()
+	`
+	batch := &pgx.Batch{}
+	for _, card := range cards {
+		batch.Queue(upsertQuery, entry.ID, card.Kind, card.Front, card.Back, card.Ease, entry.SummaryGeneratedAt)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save review cards: %w", err)
+	}
+
+	deleteQuery := `
+		DELETE FROM review_cards
+		WHERE entry_id = $1 AND summary_generated_at IS DISTINCT FROM $2
+	`
+	if _, err := tx.Exec(ctx, deleteQuery, entry.ID, entry.SummaryGeneratedAt); err != nil {
+		return fmt.Errorf("failed to delete outdated review cards: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit review cards: %w", err)
+	}
+	return nil
+}
+
+// ListCardsByEntries retrieves the cards of a kind for the given entries of a user, keyed by entry ID
+func (r *ReviewRepository) ListCardsByEntries(ctx context.Context, userID uuid.UUID, entryIDs []uuid.UUID, kind model.CardKind) (map[uuid.UUID][]model.ReviewCard, error) {
+	cards := make(map[uuid.UUID][]model.ReviewCard)
+	if len(entryIDs) == 0 {
+		return cards, nil
+	}
+
+	query := `
+		SELECT ` + reviewCardColumns + `
+		FROM review_cards c
+		JOIN entries e ON e.id = c.entry_id
+		WHERE c.entry_id = ANY($1) AND c.kind = $2 AND e.user_id = $3
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, entryIDs, kind, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review cards: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		card, err := scanReviewCard(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review card: %w", err)
+		}
+		cards[card.EntryID] = append(cards[card.EntryID], *card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return cards, nil
+}
+
+// GetCard retrieves one of a user's cards by ID
+func (r *ReviewRepository) GetCard(ctx context.Context, userID, id uuid.UUID) (*model.ReviewCard, error) {
+	query := `
+		SELECT ` + reviewCardColumns + `
+		FROM review_cards c
+		JOIN entries e ON e.id = c.entry_id
+		WHERE c.id = $1 AND e.user_id = $2
+	`
+
+	card, err := scanReviewCard(r.pool.QueryRow(ctx, query, id, userID))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review card: %w", err)
+	}
+	return card, nil
+}
+
+// NextDue retrieves the user's most overdue card, or nil if nothing is due
+func (r *ReviewRepository) NextDue(ctx context.Context, userID uuid.UUID, now time.Time) (*model.ReviewCard, error) {
+	query := `
+		SELECT ` + reviewCardColumns + `
+		FROM review_cards c
+		JOIN entries e ON e.id = c.entry_id
+		WHERE e.user_id = $1 AND c.due_at <= $2
+		ORDER BY c.due_at ASC, c.created_at ASC
+		LIMIT 1
+	`
+
+	card, err := scanReviewCard(r.pool.QueryRow(ctx, query, userID, now))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next due card: %w", err)
+	}
+	return card, nil
+}
+
+// CountDue returns the number of the user's cards due for review
+func (r *ReviewRepository) CountDue(ctx context.Context, userID uuid.UUID, now time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM review_cards c
+		JOIN entries e ON e.id = c.entry_id
+		WHERE e.user_id = $1 AND c.due_at <= $2
+	`
+	var count int
+	if err := r.pool.QueryRow(ctx, query, userID, now).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count due cards: %w", err)
+	}
+	return count, nil
+}
+
+// RecordReview saves a card's new schedule together with the review log entry
+func (r *ReviewRepository) RecordReview(ctx context.Context, card *model.ReviewCard, log *model.ReviewLog) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	updateQuery := `
+		UPDATE review_cards
+		SET ease = $2, interval_days = $3, repetitions = $4, lapses = $5, due_at = $6, last_reviewed_at = $7,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err = tx.Exec(ctx, updateQuery, card.ID, card.Ease, card.IntervalDays, card.Repetitions, card.Lapses,
+		card.DueAt, card.LastReviewedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update review card: %w", err)
+	}
+
+	logQuery := `
+		INSERT INTO review_logs (card_id, rating, reviewed_at, previous_interval_days, interval_days, ease)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err = tx.Exec(ctx, logQuery, log.CardID, log.Rating, log.ReviewedAt, log.PreviousIntervalDays,
+		log.IntervalDays, log.Ease)
+	if err != nil {
+		return fmt.Errorf("failed to insert review log: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit review: %w", err)
+	}
+	return nil
+}
+
+// ReviewDays returns the distinct days, in loc, the user reviewed at least one card
+// since the given time
+func (r *ReviewRepository) ReviewDays(ctx context.Context, userID uuid.UUID, since time.Time, loc *time.Location) ([]time.Time, error) {
+	query := `
+		SELECT DISTINCT (l.reviewed_at AT TIME ZONE $3)::date
+		FROM review_logs l
+		JOIN review_cards c ON c.id = l.card_id
+		JOIN entries e ON e.id = c.entry_id
+		WHERE e.user_id = $1 AND l.reviewed_at >= $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, since, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review days: %w", err)
+	}
+	defer rows.Close()
+
+	var days []time.Time
+	for rows.Next() {
+		var day time.Time
+		if err := rows.Scan(&day); err != nil {
+			return nil, fmt.Errorf("failed to scan review day: %w", err)
+		}
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return days, nil
+}
+
+// GetReviewTotals counts the user's reviews, and those not rated "again", for a date range
+func (r *ReviewRepository) GetReviewTotals(ctx context.Context, userID uuid.UUID, start, end time.Time) (*ReviewTotals, error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE l.rating <> 'again')
+		FROM review_logs l
+		JOIN review_cards c ON c.id = l.card_id
+		JOIN entries e ON e.id = c.entry_id
+		WHERE e.user_id = $1 AND l.reviewed_at >= $2 AND l.reviewed_at <= $3
+	`
+
+	var totals ReviewTotals
+	if err := r.pool.QueryRow(ctx, query, userID, start, end).Scan(&totals.Reviews, &totals.Retained); err != nil {
+		return nil, fmt.Errorf("failed to get review totals: %w", err)
+	}
+	return &totals, nil
+}
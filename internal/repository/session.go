@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// SessionRepository handles database operations for browser sessions
+type SessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(pool *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{pool: pool}
+}
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expires_at`
+
+func scanSession(row pgx.Row) (*model.Session, error) {
+	var session model.Session
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Create stores a new session by its token hash, clearing out expired sessions first
+func (r *SessionRepository) Create(ctx context.Context, userID uuid.UUID, tokenHash, userAgent, ip string, expiresAt time.Time) (*model.Session, error) {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at < NOW()`); err != nil {
+		return nil, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	query := `
+		INSERT INTO sessions (user_id, token_hash, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + sessionColumns
+
+	session, err := scanSession(r.pool.QueryRow(ctx, query, userID, tokenHash, userAgent, ip, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, nil
+}
+
+// CreatePending stores a session that has passed the password but can't be used
+// until a second factor is verified with CompletePending
+func (r *SessionRepository) CreatePending(ctx context.Context, userID uuid.UUID, tokenHash, userAgent, ip string, expiresAt time.Time) (*model.Session, error) {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE expires_at < NOW()`); err != nil {
+		return nil, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+
+	query := `
+		INSERT INTO sessions (user_id, token_hash, user_agent, ip, expires_at, pending_totp)
+		VALUES ($1, $2, $3, $4, $5, true)
+		RETURNING ` + sessionColumns
+
+	session, err := scanSession(r.pool.QueryRow(ctx, query, userID, tokenHash, userAgent, ip, expiresAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pending session: %w", err)
+	}
+	return session, nil
+}
+
+// GetPendingByTokenHash retrieves an unexpired session still waiting for a second factor
+func (r *SessionRepository) GetPendingByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE token_hash = $1 AND pending_totp AND expires_at > NOW()`
+
+	session, err := scanSession(r.pool.QueryRow(ctx, query, tokenHash))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending session: %w", err)
+	}
+	return session, nil
+}
+
+// CompletePending marks a pending session as verified so it can authenticate requests
+func (r *SessionRepository) CompletePending(ctx context.Context, id uuid.UUID, expiresAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET pending_totp = false, last_seen_at = NOW(), expires_at = $2
+		WHERE id = $1 AND pending_totp
+	`
+
+	if _, err := r.pool.Exec(ctx, query, id, expiresAt); err != nil {
+		return fmt.Errorf("failed to complete session: %w", err)
+	}
+	return nil
+}
+
+// RecordFailedTOTP counts a wrong code against a pending session, returning how many
+// wrong codes it has had
+func (r *SessionRepository) RecordFailedTOTP(ctx context.Context, id uuid.UUID) (int, error) {
+	var attempts int
+	query := `UPDATE sessions SET totp_attempts = totp_attempts + 1 WHERE id = $1 RETURNING totp_attempts`
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&attempts); err != nil {
+		return 0, fmt.Errorf("failed to record totp attempt: %w", err)
+	}
+	return attempts, nil
+}
+
+// GetByTokenHash retrieves an unexpired, fully signed-in session by its token hash
+func (r *SessionRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE token_hash = $1 AND NOT pending_totp AND expires_at > NOW()`
+
+	session, err := scanSession(r.pool.QueryRow(ctx, query, tokenHash))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return session, nil
+}
+
+// Touch records activity on a session, sliding its expiry forward
+func (r *SessionRepository) Touch(ctx context.Context, id uuid.UUID, userAgent, ip string, expiresAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = NOW(), user_agent = $2, ip = $3, expires_at = $4
+		WHERE id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, id, userAgent, ip, expiresAt); err != nil {
+		return fmt.Errorf("failed to touch session: %w", err)
+	}
+	return nil
+}
+
+// ListByUser retrieves a user's unexpired, signed-in sessions, most recently active first
+func (r *SessionRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND NOT pending_totp AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []model.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Delete revokes one of a user's sessions, returning false if it doesn't exist
+func (r *SessionRepository) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete session: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteByTokenHash revokes the session a token belongs to
+func (r *SessionRepository) DeleteByTokenHash(ctx context.Context, tokenHash string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE token_hash = $1`, tokenHash); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteAllForUser revokes every session of a user, returning how many were removed
+func (r *SessionRepository) DeleteAllForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
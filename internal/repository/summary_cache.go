@@ -3,8 +3,10 @@ package repository
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -19,45 +21,97 @@ func NewSummaryCacheRepository(pool *pgxpool.Pool) *SummaryCacheRepository {
 	return &SummaryCacheRepository{pool: pool}
 }
 
-// GetByURLHash retrieves a cached summary by URL hash
-func (r *SummaryCacheRepository) GetByURLHash(ctx context.Context, urlHash string) (*model.SummaryCache, error) {
-	query := `
-		SELECT id, url_hash, canonical_url, summary_text, provider, model, version, created_at
-		FROM summary_cache
-		WHERE url_hash = $1
-	`
+// SummaryCacheStats holds cache usage counters since ResetAt
+type SummaryCacheStats struct {
+	Entries  int
+	Expired  int
+	Hits     int64
+	Misses   int64
+	Bypasses int64
+	ResetAt  time.Time
+}
+
+// HitRate returns the fraction of lookups served from the cache
+func (s *SummaryCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+const summaryCacheColumns = `id, url_hash, canonical_url, summary_text, provider, model, version, created_at,
+		       cache_key, content_hash, expires_at, hit_count, last_hit_at, insights`
 
+func scanSummaryCache(row pgx.Row) (*model.SummaryCache, error) {
 	var cache model.SummaryCache
-	err := r.pool.QueryRow(ctx, query, urlHash).Scan(
+	err := row.Scan(
 		&cache.ID, &cache.URLHash, &cache.CanonicalURL, &cache.SummaryText,
 		&cache.Provider, &cache.Model, &cache.Version, &cache.CreatedAt,
+		&cache.CacheKey, &cache.ContentHash, &cache.ExpiresAt, &cache.HitCount, &cache.LastHitAt,
+		&cache.Insights,
 	)
-	if err == pgx.ErrNoRows {
-		return nil, nil
-	}
 	if err != nil {
+		return nil, err
+	}
+	return &cache, nil
+}
+
+// Lookup retrieves an unexpired cached summary by key and records the hit or miss
+func (r *SummaryCacheRepository) Lookup(ctx context.Context, cacheKey string) (*model.SummaryCache, error) {
+	query := `
+		UPDATE summary_cache
+		SET hit_count = hit_count + 1, last_hit_at = NOW()
+		WHERE cache_key = $1 AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING ` + summaryCacheColumns
+
+	cache, err := scanSummaryCache(r.pool.QueryRow(ctx, query, cacheKey))
+	if err != nil && err != pgx.ErrNoRows {
 		return nil, fmt.Errorf("failed to get summary cache: %w", err)
 	}
 
-	return &cache, nil
+	counter := "hits"
+	if cache == nil {
+		counter = "misses"
+	}
+	if err := r.incrementStat(ctx, counter); err != nil {
+		return nil, err
+	}
+
+	return cache, nil
+}
+
+// RecordBypass counts a summarization that skipped the cache on request
+func (r *SummaryCacheRepository) RecordBypass(ctx context.Context) error {
+	return r.incrementStat(ctx, "bypasses")
+}
+
+func (r *SummaryCacheRepository) incrementStat(ctx context.Context, counter string) error {
+	// counter is one of a fixed set of column names, never user input
+	query := fmt.Sprintf(`UPDATE summary_cache_stats SET %[1]s = %[1]s + 1`, counter)
+	if _, err := r.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to update summary cache stats: %w", err)
+	}
+	return nil
 }
 
-// Store saves a summary to the cache
+// Store saves a summary to the cache, replacing any entry with the same key
 func (r *SummaryCacheRepository) Store(ctx context.Context, cache *model.SummaryCache) error {
 	query := `
-		INSERT INTO summary_cache (url_hash, canonical_url, summary_text, provider, model, version)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		ON CONFLICT (url_hash) DO UPDATE SET
+		INSERT INTO summary_cache (cache_key, url_hash, content_hash, canonical_url, summary_text, provider, model, version, expires_at, insights)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (cache_key) DO UPDATE SET
 			summary_text = EXCLUDED.summary_text,
-			provider = EXCLUDED.provider,
-			model = EXCLUDED.model,
-			version = EXCLUDED.version,
+			insights = EXCLUDED.insights,
+			expires_at = EXCLUDED.expires_at,
+			hit_count = 0,
+			last_hit_at = NULL,
 			created_at = NOW()
 	`
 
 	_, err := r.pool.Exec(ctx, query,
-		cache.URLHash, cache.CanonicalURL, cache.SummaryText,
-		cache.Provider, cache.Model, cache.Version,
+		cache.CacheKey, cache.URLHash, cache.ContentHash, cache.CanonicalURL, cache.SummaryText,
+		cache.Provider, cache.Model, cache.Version, cache.ExpiresAt, cache.Insights,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to store summary cache: %w", err)
@@ -65,3 +119,96 @@ func (r *SummaryCacheRepository) Store(ctx context.Context, cache *model.Summary
 
 	return nil
 }
+
+// List retrieves cached summaries, most recent first
+func (r *SummaryCacheRepository) List(ctx context.Context, limit, offset int) ([]model.SummaryCache, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `
+		SELECT ` + summaryCacheColumns + `
+		FROM summary_cache
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list summary cache: %w", err)
+	}
+	defer rows.Close()
+
+	var caches []model.SummaryCache
+	for rows.Next() {
+		cache, err := scanSummaryCache(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan summary cache: %w", err)
+		}
+		caches = append(caches, *cache)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return caches, nil
+}
+
+// Stats returns cache size and usage counters
+func (r *SummaryCacheRepository) Stats(ctx context.Context) (*SummaryCacheStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM summary_cache),
+			(SELECT COUNT(*) FROM summary_cache WHERE expires_at <= NOW()),
+			hits, misses, bypasses, reset_at
+		FROM summary_cache_stats
+	`
+
+	var stats SummaryCacheStats
+	err := r.pool.QueryRow(ctx, query).Scan(
+		&stats.Entries, &stats.Expired, &stats.Hits, &stats.Misses, &stats.Bypasses, &stats.ResetAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get summary cache stats: %w", err)
+	}
+
+	return &stats, nil
+}
+
+// ResetStats zeroes the usage counters
+func (r *SummaryCacheRepository) ResetStats(ctx context.Context) error {
+	query := `UPDATE summary_cache_stats SET hits = 0, misses = 0, bypasses = 0, reset_at = NOW()`
+	if _, err := r.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("failed to reset summary cache stats: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a cached summary
+func (r *SummaryCacheRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM summary_cache WHERE id = $1`
+	if _, err := r.pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to delete summary cache: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpired removes expired cached summaries. Returns the number removed.
+func (r *SummaryCacheRepository) PurgeExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM summary_cache WHERE expires_at <= NOW()`
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired summary cache: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PurgeAll removes every cached summary. Returns the number removed.
+func (r *SummaryCacheRepository) PurgeAll(ctx context.Context) (int64, error) {
+	query := `DELETE FROM summary_cache`
+	tag, err := r.pool.Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge summary cache: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
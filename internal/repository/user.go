@@ -0,0 +1,337 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrEmailTaken is returned when creating a user with an email that already has an account
+var ErrEmailTaken = errors.New("email already in use")
+
+// UserRepository handles database operations for user accounts
+type UserRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewUserRepository creates a new UserRepository
+func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
+	return &UserRepository{pool: pool}
+}
+
+const userColumns = `id, email, password_hash, is_admin, totp_secret, totp_enabled_at, totp_last_step, totp_required, mail_key, created_at, updated_at`
+
+func scanUser(row pgx.Row) (*model.User, error) {
+	var user model.User
+	err := row.Scan(
+		&user.ID, &user.Email, &user.PasswordHash, &user.IsAdmin,
+		&user.TOTPSecret, &user.TOTPEnabledAt, &user.TOTPLastStep, &user.TOTPRequired,
+		&user.MailKey, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Create inserts a new user. Emails are stored lowercased so sign-in is case-insensitive.
+func (r *UserRepository) Create(ctx context.Context, email string, passwordHash *string, isAdmin bool) (*model.User, error) {
+	query := `
+		INSERT INTO users (email, password_hash, is_admin)
+		VALUES ($1, $2, $3)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.pool.QueryRow(ctx, query, normalizeEmail(email), passwordHash, isAdmin))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	return user, nil
+}
+
+// GetByID retrieves a user by ID
+func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.pool.QueryRow(ctx, query, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email, ignoring case
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+
+	user, err := scanUser(r.pool.QueryRow(ctx, query, normalizeEmail(email)))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+	return user, nil
+}
+
+// GetByIdentity retrieves the user an identity provider's subject is linked to, and
+// records the sign-in
+func (r *UserRepository) GetByIdentity(ctx context.Context, issuer, subject string) (*model.User, error) {
+	query := `
+		WITH identity AS (
+			UPDATE user_identities SET last_login_at = NOW()
+			WHERE issuer = $1 AND subject = $2
+			RETURNING user_id
+		)
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = (SELECT user_id FROM identity)
+	`
+
+	user, err := scanUser(r.pool.QueryRow(ctx, query, issuer, subject))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by identity: %w", err)
+	}
+	return user, nil
+}
+
+// LinkIdentity links an identity provider's subject to a user
+func (r *UserRepository) LinkIdentity(ctx context.Context, userID uuid.UUID, issuer, subject, email string) error {
+	query := `
+		INSERT INTO user_identities (user_id, issuer, subject, email)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, userID, issuer, subject, normalizeEmail(email)); err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// List retrieves all users ordered by email
+func (r *UserRepository) List(ctx context.Context) ([]model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY email ASC`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []model.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetPassword replaces a user's password hash and signs out all of their sessions, so
+// whoever knew the old password loses access. Returns false if the user doesn't exist.
+func (r *UserRepository) SetPassword(ctx context.Context, id uuid.UUID, passwordHash string) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`
+	tag, err := tx.Exec(ctx, query, id, passwordHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to set password: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM sessions WHERE user_id = $1`, id); err != nil {
+		return false, fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit password: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// EnableTOTP turns on two-factor sign-in with the given secret, replacing any previous
+// recovery codes. lastStep is the step of the code that confirmed enrolment.
+func (r *UserRepository) EnableTOTP(ctx context.Context, id uuid.UUID, secret string, lastStep int64, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE users
+		SET totp_secret = $2, totp_enabled_at = NOW(), totp_last_step = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, id, secret, lastStep); err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+	if err := replaceRecoveryCodes(ctx, tx, id, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DisableTOTP turns off two-factor sign-in and deletes the user's recovery codes
+func (r *UserRepository) DisableTOTP(ctx context.Context, id uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = 0, updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to disable totp: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// SetTOTPRequired sets whether the user must enrol in two-factor sign-in, returning
+// false if the user doesn't exist
+func (r *UserRepository) SetTOTPRequired(ctx context.Context, id uuid.UUID, required bool) (bool, error) {
+	query := `UPDATE users SET totp_required = $2, updated_at = NOW() WHERE id = $1`
+
+	tag, err := r.pool.Exec(ctx, query, id, required)
+	if err != nil {
+		return false, fmt.Errorf("failed to set totp required: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetByMailKey retrieves the user whose emailing address contains key
+func (r *UserRepository) GetByMailKey(ctx context.Context, key string) (*model.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE mail_key = $1`
+
+	user, err := scanUser(r.pool.QueryRow(ctx, query, strings.ToLower(key)))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by mail key: %w", err)
+	}
+	return user, nil
+}
+
+// SetMailKey replaces the secret in a user's emailing address, so mail sent to the old
+// address is rejected
+func (r *UserRepository) SetMailKey(ctx context.Context, id uuid.UUID, key string) (bool, error) {
+	query := `UPDATE users SET mail_key = $2, updated_at = NOW() WHERE id = $1`
+
+	tag, err := r.pool.Exec(ctx, query, id, strings.ToLower(key))
+	if err != nil {
+		return false, fmt.Errorf("failed to set mail key: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// AdvanceTOTPStep records that a code for step was accepted. It returns false if a
+// code for that step or a later one was already used, so each code works once.
+func (r *UserRepository) AdvanceTOTPStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	query := `UPDATE users SET totp_last_step = $2 WHERE id = $1 AND totp_last_step < $2`
+
+	tag, err := r.pool.Exec(ctx, query, id, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance totp step: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReplaceRecoveryCodes swaps the user's recovery codes for a new set
+func (r *UserRepository) ReplaceRecoveryCodes(ctx context.Context, id uuid.UUID, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, id, codeHashes); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, id uuid.UUID, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, id, hash); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
+
+// UseRecoveryCode marks an unused recovery code as used, returning false if the user
+// has no unused code with that hash
+func (r *UserRepository) UseRecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error) {
+	query := `
+		UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	tag, err := r.pool.Exec(ctx, query, id, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has left
+func (r *UserRepository) CountRecoveryCodes(ctx context.Context, id uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	if err := r.pool.QueryRow(ctx, query, id).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,156 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/summarizer"
+)
+
+// maxQACards limits the question/answer cards generated per entry
+const maxQACards = 3
+
+// Generator creates review cards for entries: one card per entry, plus
+// question/answer cards written by the LLM when one is configured.
+type Generator struct {
+	summarizer summarizer.Summarizer
+}
+
+// NewGenerator creates a card generator; sum may be nil to only create entry cards
+func NewGenerator(sum summarizer.Summarizer) *Generator {
+	return &Generator{summarizer: sum}
+}
+
+// Generate returns new cards for a summarized entry. Errors are only returned when the
+// LLM is temporarily unavailable, so the entry can be retried later.
+func (g *Generator) Generate(ctx context.Context, entry *model.Entry) ([]model.ReviewCard, error) {
+	card := EntryCard(entry)
+	if card == nil {
+		return nil, nil
+	}
+	cards := []model.ReviewCard{*card}
+
+	if g.summarizer == nil {
+		return cards, nil
+	}
+
+	qa, err := g.generateQA(ctx, entry)
+	if err != nil {
+		if summarizer.IsRetryable(err) {
+			return nil, err
+		}
+		slog.Warn("failed to generate question cards", "id", entry.ID, "error", err)
+		return cards, nil
+	}
+	return append(cards, qa...), nil
+}
+
+// EntryCard builds the card for an entry: its title on the front and summary on the back
+func EntryCard(entry *model.Entry) *model.ReviewCard {
+	back := ""
+	if entry.SummaryText != nil {
+		back = strings.TrimSpace(*entry.SummaryText)
+	}
+	if back == "" && entry.Description != nil {
+		back = strings.TrimSpace(*entry.Description)
+	}
+	if back == "" {
+		return nil
+	}
+	if entry.Notes != nil && strings.TrimSpace(*entry.Notes) != "" {
+		back += "\n\nNotes: " + strings.TrimSpace(*entry.Notes)
+	}
+
+	front := entry.SourceURL
+	if entry.Title != nil && strings.TrimSpace(*entry.Title) != "" {
+		front = strings.TrimSpace(*entry.Title)
+	}
+
+	return &model.ReviewCard{
+		EntryID: entry.ID,
+		Kind:    model.CardKindEntry,
+		Front:   front,
+		Back:    back,
+		Ease:    DefaultEase,
+	}
+}
+
+func (g *Generator) generateQA(ctx context.Context, entry *model.Entry) ([]model.ReviewCard, error) {
+	input := summarizer.InputFromEntry(entry)
+	input.Prompt = qaPrompt(entry)
+
+	result, err := g.summarizer.Summarize(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if result.Provider == summarizer.LocalProvider {
+		// The offline summarizer can't follow the prompt
+		return nil, nil
+	}
+
+	return parseQACards(entry, result.Text)
+}
+
+func qaPrompt(entry *model.Entry) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Write up to %d flashcards that test understanding of the key ideas in this item from a learning log. ", maxQACards)
+	sb.WriteString("Each question should be answerable from the summary in one or two sentences.\n")
+	sb.WriteString(`Respond with only a JSON object: {"cards": [{"question": "...", "answer": "..."}]}` + "\n\n")
+	if entry.Title != nil {
+		fmt.Fprintf(&sb, "Title: %s\n", *entry.Title)
+	}
+	if entry.SummaryText != nil {
+		fmt.Fprintf(&sb, "Summary: %s\n", *entry.SummaryText)
+	}
+	if entry.SummaryInsights != nil && len(entry.SummaryInsights.Takeaways) > 0 {
+		fmt.Fprintf(&sb, "Key takeaways: %s\n", strings.Join(entry.SummaryInsights.Takeaways, "; "))
+	}
+	return sb.String()
+}
+
+type qaOutput struct {
+	Cards []struct {
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+	} `json:"cards"`
+}
+
+// parseQACards extracts question/answer cards from the model's JSON, ignoring incomplete ones
+func parseQACards(entry *model.Entry, raw string) ([]model.ReviewCard, error) {
+	start := strings.Index(raw, "{")
+	end := strings.LastIndex(raw, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no JSON object in response")
+	}
+
+	var out qaOutput
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &out); err != nil {
+		return nil, fmt.Errorf("failed to decode cards: %w", err)
+	}
+
+	var cards []model.ReviewCard
+	seen := make(map[string]bool)
+	for _, c := range out.Cards {
+		question := strings.TrimSpace(c.Question)
+		answer := strings.TrimSpace(c.Answer)
+		if question == "" || answer == "" || seen[question] {
+			continue
+		}
+		seen[question] = true
+		cards = append(cards, model.ReviewCard{
+			EntryID: entry.ID,
+			Kind:    model.CardKindQA,
+			Front:   question,
+			Back:    answer,
+			Ease:    DefaultEase,
+		})
+		if len(cards) == maxQACards {
+			break
+		}
+	}
+	return cards, nil
+}
@@ -0,0 +1,152 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/summarizer"
+	"github.com/google/uuid"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestEntryCard(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name      string
+		entry     model.Entry
+		wantFront string
+		wantBack  string
+	}{
+		{
+			name:      "title and summary",
+			entry:     model.Entry{ID: id, SourceURL: "https://example.com", Title: strPtr("Go generics"), SummaryText: strPtr("How type parameters work.")},
+			wantFront: "Go generics",
+			wantBack:  "How type parameters work.",
+		},
+		{
+			name:      "notes are appended",
+			entry:     model.Entry{ID: id, SourceURL: "https://example.com", Title: strPtr("Go generics"), SummaryText: strPtr("Summary."), Notes: strPtr("Try this at work")},
+			wantFront: "Go generics",
+			wantBack:  "Summary.\n\nNotes: Try this at work",
+		},
+		{
+			name:      "url without title, description without summary",
+			entry:     model.Entry{ID: id, SourceURL: "https://example.com", Description: strPtr("A description")},
+			wantFront: "https://example.com",
+			wantBack:  "A description",
+		},
+		{
+			name:  "no content",
+			entry: model.Entry{ID: id, SourceURL: "https://example.com", Title: strPtr("Title only")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			card := EntryCard(&tt.entry)
+			if tt.wantBack == "" {
+				if card != nil {
+					t.Errorf("EntryCard() = %+v, want nil", card)
+				}
+				return
+			}
+			if card == nil {
+				t.Fatal("EntryCard() = nil")
+			}
+			if card.Front != tt.wantFront || card.Back != tt.wantBack {
+				t.Errorf("EntryCard() = %q / %q, want %q / %q", card.Front, card.Back, tt.wantFront, tt.wantBack)
+			}
+			if card.EntryID != id || card.Kind != model.CardKindEntry || card.Ease != DefaultEase {
+				t.Errorf("EntryCard() = %+v", card)
+			}
+		})
+	}
+}
+
+func TestParseQACards(t *testing.T) {
+	entry := &model.Entry{ID: uuid.New()}
+
+	raw := "```json\n" + `{"cards": [
+		{"question": "What is a type parameter?", "answer": "A placeholder type."},
+		{"question": "", "answer": "Missing question"},
+		{"question": "What is a type parameter?", "answer": "Duplicate"},
+		{"question": "What does any mean?", "answer": "An alias for interface{}."},
+		{"question": "Q3?", "answer": "A3"},
+		{"question": "Q4?", "answer": "A4"}
+	]}` + "\n```"
+
+	cards, err := parseQACards(entry, raw)
+	if err != nil {
+		t.Fatalf("parseQACards() error = %v", err)
+	}
+	if len(cards) != maxQACards {
+		t.Fatalf("parseQACards() returned %d cards, want %d", len(cards), maxQACards)
+	}
+	if cards[0].Front != "What is a type parameter?" || cards[0].Back != "A placeholder type." {
+		t.Errorf("cards[0] = %+v", cards[0])
+	}
+	if cards[1].Front != "What does any mean?" || cards[2].Front != "Q3?" {
+		t.Errorf("cards = %+v", cards)
+	}
+	for _, c := range cards {
+		if c.Kind != model.CardKindQA || c.EntryID != entry.ID {
+			t.Errorf("card = %+v", c)
+		}
+	}
+
+	if _, err := parseQACards(entry, "I can't help with that"); err == nil {
+		t.Error("parseQACards() expected error for non-JSON output")
+	}
+}
+
+type stubSummarizer struct {
+	provider string
+	text     string
+	err      error
+}
+
+func (s *stubSummarizer) Provider() string { return s.provider }
+func (s *stubSummarizer) Model() string    { return "stub" }
+func (s *stubSummarizer) Version() string  { return "1.0.0" }
+
+func (s *stubSummarizer) Summarize(context.Context, summarizer.Input) (*summarizer.Result, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &summarizer.Result{Text: s.text, Provider: s.provider}, nil
+}
+
+func TestGenerate(t *testing.T) {
+	entry := &model.Entry{ID: uuid.New(), SourceURL: "https://example.com", Title: strPtr("Go"), SummaryText: strPtr("About Go.")}
+	qa := `{"cards": [{"question": "What is Go?", "answer": "A language."}]}`
+
+	tests := []struct {
+		name      string
+		sum       summarizer.Summarizer
+		wantCards int
+		wantErr   bool
+	}{
+		{name: "entry card only", wantCards: 1},
+		{name: "with question cards", sum: &stubSummarizer{provider: "openai", text: qa}, wantCards: 2},
+		{name: "local summarizer ignored", sum: &stubSummarizer{provider: summarizer.LocalProvider, text: qa}, wantCards: 1},
+		{name: "bad output keeps entry card", sum: &stubSummarizer{provider: "openai", text: "nope"}, wantCards: 1},
+		{name: "permanent error keeps entry card", sum: &stubSummarizer{provider: "openai", err: errors.New("bad request")}, wantCards: 1},
+		{name: "retryable error", sum: &stubSummarizer{provider: "openai", err: &summarizer.APIError{Provider: "openai", StatusCode: 503}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cards, err := NewGenerator(tt.sum).Generate(context.Background(), entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Generate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(cards) != tt.wantCards {
+				t.Errorf("Generate() returned %d cards, want %d", len(cards), tt.wantCards)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package review schedules spaced-repetition cards and generates them from entries.
+package review
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+const (
+	// DefaultEase is the starting ease factor for new cards
+	DefaultEase = 2.5
+	// MinEase keeps repeatedly failed cards from collapsing to tiny intervals
+	MinEase = 1.3
+
+	// relearnDelay is how soon a forgotten card comes back
+	relearnDelay = 10 * time.Minute
+
+	hardFactor  = 1.2
+	easyBonus   = 1.3
+	graduateDay = 1
+	secondStep  = 6
+	easyFirst   = 4
+)
+
+// easeDelta is how each rating adjusts the ease factor, as in Anki's SM-2 variant
+var easeDelta = map[model.ReviewRating]float64{
+	model.RatingAgain: -0.2,
+	model.RatingHard:  -0.15,
+	model.RatingGood:  0,
+	model.RatingEasy:  0.15,
+}
+
+// ParseRating validates a rating from a form value
+func ParseRating(s string) (model.ReviewRating, error) {
+	rating := model.ReviewRating(s)
+	if _, ok := easeDelta[rating]; !ok {
+		return "", fmt.Errorf("invalid rating %q", s)
+	}
+	return rating, nil
+}
+
+// Schedule returns the card rescheduled after a review with the given rating.
+// Intervals grow by the card's ease; a lapse restarts the card and brings it back shortly.
+func Schedule(card model.ReviewCard, rating model.ReviewRating, now time.Time) model.ReviewCard {
+	if card.Ease == 0 {
+		card.Ease = DefaultEase
+	}
+
+	next := card
+	next.LastReviewedAt = &now
+
+	if rating == model.RatingAgain {
+		next.Repetitions = 0
+		next.Lapses++
+		next.IntervalDays = 0
+		next.Ease = math.Max(MinEase, card.Ease+easeDelta[rating])
+		next.DueAt = now.Add(relearnDelay)
+		return next
+	}
+
+	next.IntervalDays = nextInterval(card, rating)
+	next.Repetitions++
+	next.Ease = math.Max(MinEase, card.Ease+easeDelta[rating])
+	next.DueAt = now.AddDate(0, 0, next.IntervalDays)
+	return next
+}
+
+// nextInterval computes the days until the next review using the ease before this review
+func nextInterval(card model.ReviewCard, rating model.ReviewRating) int {
+	prev := card.IntervalDays
+
+	var days float64
+	switch card.Repetitions {
+	case 0:
+		days = graduateDay
+		if rating == model.RatingEasy {
+			days = easyFirst
+		}
+	case 1:
+		days = secondStep
+		switch rating {
+		case model.RatingHard:
+			days = float64(prev) * hardFactor
+		case model.RatingEasy:
+			days = secondStep * easyBonus
+		}
+	default:
+		days = float64(prev) * card.Ease
+		switch rating {
+		case model.RatingHard:
+			days = float64(prev) * hardFactor
+		case model.RatingEasy:
+			days = float64(prev) * card.Ease * easyBonus
+		}
+	}
+
+	// A successful review always pushes the card further out
+	interval := int(math.Round(days))
+	if interval <= prev {
+		interval = prev + 1
+	}
+	return interval
+}
+
+// IntervalLabel describes when a card would next be due, e.g. "10m", "6d" or "3mo"
+func IntervalLabel(card model.ReviewCard, rating model.ReviewRating, now time.Time) string {
+	next := Schedule(card, rating, now)
+	if next.IntervalDays == 0 {
+		return fmt.Sprintf("%dm", int(next.DueAt.Sub(now).Minutes()))
+	}
+
+	switch days := next.IntervalDays; {
+	case days < 30:
+		return fmt.Sprintf("%dd", days)
+	case days < 365:
+		return fmt.Sprintf("%dmo", int(math.Round(float64(days)/30)))
+	default:
+		return fmt.Sprintf("%.1fy", float64(days)/365)
+	}
+}
+
+// Streak counts consecutive days with at least one review, ending today.
+// A streak that ended yesterday still counts since today's reviews may be pending.
+func Streak(reviewDays []time.Time, today time.Time) int {
+	seen := make(map[string]bool, len(reviewDays))
+	for _, d := range reviewDays {
+		seen[d.Format("2006-01-02")] = true
+	}
+
+	day := today
+	if !seen[day.Format("2006-01-02")] {
+		day = day.AddDate(0, 0, -1)
+	}
+
+	streak := 0
+	for seen[day.Format("2006-01-02")] {
+		streak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return streak
+}
@@ -0,0 +1,120 @@
+package review
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		card         model.ReviewCard
+		rating       model.ReviewRating
+		wantInterval int
+		wantReps     int
+		wantLapses   int
+		wantEase     float64
+	}{
+		{name: "new card good", card: model.ReviewCard{Ease: 2.5}, rating: model.RatingGood, wantInterval: 1, wantReps: 1, wantEase: 2.5},
+		{name: "new card easy", card: model.ReviewCard{Ease: 2.5}, rating: model.RatingEasy, wantInterval: 4, wantReps: 1, wantEase: 2.65},
+		{name: "new card without ease", card: model.ReviewCard{}, rating: model.RatingGood, wantInterval: 1, wantReps: 1, wantEase: 2.5},
+		{name: "second review good", card: model.ReviewCard{Ease: 2.5, IntervalDays: 1, Repetitions: 1}, rating: model.RatingGood, wantInterval: 6, wantReps: 2, wantEase: 2.5},
+		{name: "second review hard", card: model.ReviewCard{Ease: 2.5, IntervalDays: 1, Repetitions: 1}, rating: model.RatingHard, wantInterval: 2, wantReps: 2, wantEase: 2.35},
+		{name: "mature good", card: model.ReviewCard{Ease: 2.5, IntervalDays: 6, Repetitions: 2}, rating: model.RatingGood, wantInterval: 15, wantReps: 3, wantEase: 2.5},
+		{name: "mature hard", card: model.ReviewCard{Ease: 2.5, IntervalDays: 10, Repetitions: 3}, rating: model.RatingHard, wantInterval: 12, wantReps: 4, wantEase: 2.35},
+		{name: "mature easy", card: model.ReviewCard{Ease: 2.5, IntervalDays: 6, Repetitions: 2}, rating: model.RatingEasy, wantInterval: 20, wantReps: 3, wantEase: 2.65},
+		{name: "lapse", card: model.ReviewCard{Ease: 2.5, IntervalDays: 15, Repetitions: 3}, rating: model.RatingAgain, wantInterval: 0, wantReps: 0, wantLapses: 1, wantEase: 2.3},
+		{name: "ease floor", card: model.ReviewCard{Ease: 1.35, IntervalDays: 3, Repetitions: 2, Lapses: 4}, rating: model.RatingAgain, wantInterval: 0, wantReps: 0, wantLapses: 5, wantEase: MinEase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Schedule(tt.card, tt.rating, now)
+			if got.IntervalDays != tt.wantInterval {
+				t.Errorf("IntervalDays = %d, want %d", got.IntervalDays, tt.wantInterval)
+			}
+			if got.Repetitions != tt.wantReps {
+				t.Errorf("Repetitions = %d, want %d", got.Repetitions, tt.wantReps)
+			}
+			if got.Lapses != tt.wantLapses {
+				t.Errorf("Lapses = %d, want %d", got.Lapses, tt.wantLapses)
+			}
+			if diff := got.Ease - tt.wantEase; diff > 0.001 || diff < -0.001 {
+				t.Errorf("Ease = %v, want %v", got.Ease, tt.wantEase)
+			}
+			if got.LastReviewedAt == nil || !got.LastReviewedAt.Equal(now) {
+				t.Errorf("LastReviewedAt = %v, want %v", got.LastReviewedAt, now)
+			}
+
+			wantDue := now.AddDate(0, 0, tt.wantInterval)
+			if tt.rating == model.RatingAgain {
+				wantDue = now.Add(relearnDelay)
+			}
+			if !got.DueAt.Equal(wantDue) {
+				t.Errorf("DueAt = %v, want %v", got.DueAt, wantDue)
+			}
+		})
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	for _, rating := range model.ReviewRatings {
+		if got, err := ParseRating(string(rating)); err != nil || got != rating {
+			t.Errorf("ParseRating(%q) = %q, %v", rating, got, err)
+		}
+	}
+	if _, err := ParseRating("perfect"); err == nil {
+		t.Error("ParseRating(\"perfect\") expected error")
+	}
+}
+
+func TestIntervalLabel(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		card   model.ReviewCard
+		rating model.ReviewRating
+		want   string
+	}{
+		{card: model.ReviewCard{Ease: 2.5}, rating: model.RatingAgain, want: "10m"},
+		{card: model.ReviewCard{Ease: 2.5}, rating: model.RatingGood, want: "1d"},
+		{card: model.ReviewCard{Ease: 2.5, IntervalDays: 30, Repetitions: 4}, rating: model.RatingGood, want: "3mo"},
+		{card: model.ReviewCard{Ease: 2.5, IntervalDays: 200, Repetitions: 6}, rating: model.RatingGood, want: "1.4y"},
+	}
+
+	for _, tt := range tests {
+		if got := IntervalLabel(tt.card, tt.rating, now); got != tt.want {
+			t.Errorf("IntervalLabel(%+v, %q) = %q, want %q", tt.card, tt.rating, got, tt.want)
+		}
+	}
+}
+
+func TestStreak(t *testing.T) {
+	today := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name string
+		days []time.Time
+		want int
+	}{
+		{name: "no reviews", want: 0},
+		{name: "today only", days: []time.Time{day(10)}, want: 1},
+		{name: "run through today", days: []time.Time{day(8), day(10), day(9)}, want: 3},
+		{name: "run ending yesterday", days: []time.Time{day(7), day(8), day(9)}, want: 3},
+		{name: "gap breaks the run", days: []time.Time{day(5), day(6), day(8), day(9), day(10)}, want: 3},
+		{name: "last review two days ago", days: []time.Time{day(7), day(8)}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Streak(tt.days, today); got != tt.want {
+				t.Errorf("Streak() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,13 @@ package server
 import (
 	"net/http"
 
+	"github.com/drywaters/learnd/internal/auth"
 	"github.com/drywaters/learnd/internal/config"
 	"github.com/drywaters/learnd/internal/handler"
+	"github.com/drywaters/learnd/internal/metrics"
 	"github.com/drywaters/learnd/internal/middleware"
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/oidc"
 	"github.com/drywaters/learnd/internal/repository"
 	"github.com/go-chi/chi/v5"
 	chimw "github.com/go-chi/chi/v5/middleware"
@@ -16,14 +20,39 @@ type Server struct {
 	cfg              *config.Config
 	entryRepo        *repository.EntryRepository
 	summaryCacheRepo *repository.SummaryCacheRepository
+	promptRepo       *repository.PromptTemplateRepository
+	reviewRepo       *repository.ReviewRepository
+	importRepo       *repository.ImportJobRepository
+	userRepo         *repository.UserRepository
+	sessionRepo      *repository.SessionRepository
+	apiTokenRepo     *repository.APITokenRepository
+	authEventRepo    *repository.AuthEventRepository
 }
 
 // New creates a new Server
-func New(cfg *config.Config, entryRepo *repository.EntryRepository, summaryCacheRepo *repository.SummaryCacheRepository) *Server {
+func New(
+	cfg *config.Config,
+	entryRepo *repository.EntryRepository,
+	summaryCacheRepo *repository.SummaryCacheRepository,
+	promptRepo *repository.PromptTemplateRepository,
+	reviewRepo *repository.ReviewRepository,
+	importRepo *repository.ImportJobRepository,
+	userRepo *repository.UserRepository,
+	sessionRepo *repository.SessionRepository,
+	apiTokenRepo *repository.APITokenRepository,
+	authEventRepo *repository.AuthEventRepository,
+) *Server {
 	return &Server{
 		cfg:              cfg,
 		entryRepo:        entryRepo,
 		summaryCacheRepo: summaryCacheRepo,
+		promptRepo:       promptRepo,
+		reviewRepo:       reviewRepo,
+		importRepo:       importRepo,
+		userRepo:         userRepo,
+		sessionRepo:      sessionRepo,
+		apiTokenRepo:     apiTokenRepo,
+		authEventRepo:    authEventRepo,
 	}
 }
 
@@ -33,9 +62,13 @@ func (s *Server) Router() http.Handler {
 
 	// Middleware
 	r.Use(chimw.RequestID)
-	r.Use(chimw.RealIP)
+	r.Use(middleware.RealIP(s.cfg.TrustedProxies))
+	r.Use(middleware.Tracing)
 	r.Use(middleware.Logger)
+	r.Use(middleware.Metrics)
 	r.Use(chimw.Recoverer)
+	r.Use(middleware.SecurityHeaders(s.cfg.SecureCookies))
+	r.Use(middleware.CSRF(s.sessionRepo))
 
 	// Static files
 	const staticCacheControl = "public, max-age=86400"
@@ -61,42 +94,181 @@ func (s *Server) Router() http.Handler {
 		_, _ = w.Write([]byte("ok"))
 	})
 
-	// Auth handlers
-	authHandler := handler.NewAuthHandler(s.cfg.APIToken, s.cfg.SecureCookies)
+	// Metrics share this listener only behind their token; METRICS_ADDR serves them separately
+	if s.cfg.MetricsToken != "" && s.cfg.MetricsAddr == "" {
+		r.Handle("/metrics", metrics.Handler(s.cfg.MetricsToken))
+	}
+
+	// Auth handlers; the legacy token only signs in when enabled
+	legacyToken := ""
+	if s.cfg.LegacyAPIToken {
+		legacyToken = s.cfg.APIToken
+	}
+	// Single sign-on is offered alongside password sign-in when an issuer is configured
+	var sso handler.OIDCProvider
+	if s.cfg.OIDCIssuer != "" {
+		sso = oidc.New(oidc.Config{
+			Issuer:         s.cfg.OIDCIssuer,
+			ClientID:       s.cfg.OIDCClientID,
+			ClientSecret:   s.cfg.OIDCClientSecret,
+			RedirectURL:    s.cfg.OIDCRedirectURL,
+			AllowedDomains: s.cfg.OIDCAllowedDomains,
+		})
+	}
+	// Failed sign-ins and rejected bearer tokens share one limiter, so guesses count
+	// towards the same lockout or slowdown whichever way they're made
+	limiter := auth.NewLimiter(auth.LimiterConfig{
+		MaxFailures:       s.cfg.LoginMaxFailures,
+		GlobalMaxFailures: s.cfg.LoginGlobalMaxFailures,
+		Lockout:           s.cfg.LoginLockout,
+	})
+	authHandler := handler.NewAuthHandler(legacyToken, s.cfg.SecureCookies, s.cfg.SessionTTL, s.userRepo, s.sessionRepo, s.authEventRepo, limiter, sso)
 	r.Get("/login", authHandler.LoginPage)
 	r.Post("/login", authHandler.Login)
+	r.Get("/login/2fa", authHandler.TwoFactorPage)
+	r.Post("/login/2fa", authHandler.VerifyTwoFactor)
+	r.Get("/login/oidc", authHandler.OIDCLogin)
+	r.Get("/login/oidc/callback", authHandler.OIDCCallback)
 	r.Post("/logout", authHandler.Logout)
 
-	// Protected routes
+	// Protected routes, grouped by the scope a personal access token needs.
+	// Browser sessions have every scope except admin, which only admins get.
 	r.Group(func(r chi.Router) {
-		r.Use(middleware.Auth(s.cfg.APIToken, s.cfg.SecureCookies))
+		r.Use(middleware.Auth(middleware.AuthConfig{
+			APIToken:      s.cfg.APIToken,
+			LegacyToken:   s.cfg.LegacyAPIToken,
+			SecureCookies: s.cfg.SecureCookies,
+			SessionTTL:    s.cfg.SessionTTL,
+			SessionMaxAge: s.cfg.SessionMaxAge,
+		}, s.userRepo, s.sessionRepo, s.apiTokenRepo, s.authEventRepo, limiter))
 
-		// Capture handler
 		captureHandler := handler.NewCaptureHandler(s.entryRepo)
-		r.Get("/", captureHandler.CapturePage)
-
-		// Entry API
+		shareHandler := handler.NewShareHandler(s.userRepo, s.mailRecipient())
 		entryHandler := handler.NewEntryHandler(s.entryRepo)
-		r.Post("/api/entries", entryHandler.Create)
-		r.Get("/api/entries", entryHandler.List)
-		r.Get("/api/entries/{id}", entryHandler.Get)
-		r.Put("/api/entries/{id}", entryHandler.Update)
-		r.Delete("/api/entries/{id}", entryHandler.Delete)
-		r.Post("/api/entries/{id}/refresh-enrichment", entryHandler.RefreshEnrichment)
-		r.Post("/api/entries/{id}/refresh-summary", entryHandler.RefreshSummary)
-		r.Get("/entries/{id}/status", entryHandler.Status)
-		r.Get("/entries/{id}/edit", entryHandler.EditPage)
-
-		// Report handler
-		reportHandler := handler.NewReportHandler(s.entryRepo)
-		r.Get("/reports", reportHandler.ReportsPage)
-		r.Get("/api/reports", reportHandler.GetReport)
-		r.Get("/api/reports/export", reportHandler.ExportCSV)
+		reportHandler := handler.NewReportHandler(s.entryRepo, s.reviewRepo)
+		exportHandler := handler.NewExportHandler(s.entryRepo, s.reviewRepo)
+		reviewHandler := handler.NewReviewHandler(s.reviewRepo)
+		settingsHandler := handler.NewSettingsHandler(s.entryRepo, s.promptRepo)
+		cacheHandler := handler.NewCacheHandler(s.summaryCacheRepo)
+		tagReviewHandler := handler.NewTagReviewHandler(s.entryRepo)
+		bookmarkImportHandler := handler.NewBookmarkImportHandler(s.importRepo)
+		sessionHandler := handler.NewSessionHandler(s.sessionRepo, s.cfg.SecureCookies)
+		apiTokenHandler := handler.NewAPITokenHandler(s.apiTokenRepo)
+		totpHandler := handler.NewTOTPHandler(s.userRepo)
+		activityHandler := handler.NewActivityHandler(s.authEventRepo)
+
+		// Two-factor settings stay reachable while enrolment is still required
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireSession)
+
+			r.Get(middleware.TOTPEnrolmentPath, totpHandler.SecurityPage)
+			r.Post("/api/totp", totpHandler.Enable)
+			r.Post("/api/totp/disable", totpHandler.Disable)
+			r.Post("/api/totp/recovery-codes", totpHandler.RegenerateRecoveryCodes)
+		})
+
+		// Everything else waits until a user who must use two-factor has set it up
+		r.Group(func(r chi.Router) {
+			r.Use(middleware.RequireTOTPEnrolment)
+
+			// Reading entries, review cards and exports
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireScope(model.ScopeEntriesRead))
+
+				r.Get("/", captureHandler.CapturePage)
+				r.Get("/capture-tools", shareHandler.BookmarkletPage)
+				r.Get("/api/bookmarklet", shareHandler.Bookmarklet)
+
+				r.Get("/api/entries", entryHandler.List)
+				r.Get("/api/entries/{id}", entryHandler.Get)
+				r.Get("/entries/{id}/status", entryHandler.Status)
+				r.Get("/entries/{id}/edit", entryHandler.EditPage)
+
+				r.Get("/api/export/anki", exportHandler.ExportAnki)
+				r.Get("/api/export/markdown", exportHandler.ExportMarkdown)
+				r.Get("/api/export", exportHandler.Export)
+
+				r.Get("/review", reviewHandler.ReviewPage)
+				r.Get("/tags/review", tagReviewHandler.ReviewPage)
+				r.Get("/import", bookmarkImportHandler.ImportPage)
+				r.Get("/import/jobs", bookmarkImportHandler.Jobs)
+			})
+
+			// Capturing and changing entries
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireScope(model.ScopeEntriesWrite))
+
+				// Share sheet capture
+				r.Get("/share", shareHandler.Share)
+
+				r.Post("/api/entries", entryHandler.Create)
+				r.Put("/api/entries/{id}", entryHandler.Update)
+				r.Delete("/api/entries/{id}", entryHandler.Delete)
+				r.Post("/api/entries/{id}/refresh-enrichment", entryHandler.RefreshEnrichment)
+				r.Post("/api/entries/{id}/refresh-summary", entryHandler.RefreshSummary)
+				r.Post("/api/entries/{id}/tag", entryHandler.AcceptTag)
+
+				r.Post("/api/import", exportHandler.Import)
+				r.Post("/api/review/{id}", reviewHandler.Rate)
+				r.Post("/api/tags/review", tagReviewHandler.Review)
+				r.Post("/api/import/bookmarks", bookmarkImportHandler.Upload)
+			})
+
+			// Reports
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireScope(model.ScopeReportsRead))
+
+				r.Get("/reports", reportHandler.ReportsPage)
+				r.Get("/api/reports", reportHandler.GetReport)
+				r.Get("/api/reports/export", reportHandler.ExportCSV)
+			})
+
+			// Prompt templates and the summary cache are shared by every user
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireScope(model.ScopeAdmin))
+
+				r.Get("/settings/prompts", settingsHandler.PromptsPage)
+				r.Put("/api/settings/prompts/{type}", settingsHandler.SavePrompt)
+				r.Post("/api/settings/prompts/{type}/reset", settingsHandler.ResetPrompt)
+				r.Post("/api/settings/prompts/{type}/preview", settingsHandler.PreviewPrompt)
+				r.Post("/api/settings/prompts/{type}/resummarize", settingsHandler.ResummarizePrompt)
+
+				r.Get("/settings/cache", cacheHandler.CachePage)
+				r.Post("/api/settings/cache/purge", cacheHandler.Purge)
+				r.Post("/api/settings/cache/stats/reset", cacheHandler.ResetStats)
+				r.Delete("/api/settings/cache/{id}", cacheHandler.Delete)
+			})
+
+			// Credentials can only be managed from a signed-in browser, never with a token
+			r.Group(func(r chi.Router) {
+				r.Use(middleware.RequireSession)
+
+				r.Get("/settings/sessions", sessionHandler.SessionsPage)
+				r.Post("/api/sessions/revoke-all", sessionHandler.RevokeAll)
+				r.Delete("/api/sessions/{id}", sessionHandler.Revoke)
+
+				r.Get("/settings/tokens", apiTokenHandler.TokensPage)
+				r.Post("/api/tokens", apiTokenHandler.Create)
+				r.Delete("/api/tokens/{id}", apiTokenHandler.Revoke)
+
+				r.Get("/settings/activity", activityHandler.ActivityPage)
+
+				r.Post("/api/mail-address", shareHandler.NewMailAddress)
+			})
+		})
 	})
 
 	return r
 }
 
+// mailRecipient returns the address users email links to, or "" when email-in is off
+func (s *Server) mailRecipient() string {
+	if s.cfg.SMTPAddr == "" || len(s.cfg.SMTPRecipients) == 0 {
+		return ""
+	}
+	return s.cfg.SMTPRecipients[0]
+}
+
 func serveStaticFile(path string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, path)
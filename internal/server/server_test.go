@@ -0,0 +1,109 @@
+package server
+
+import (
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/config"
+	"github.com/go-chi/chi/v5"
+)
+
+var urlParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// TestSecurityHeadersOnEveryRoute requests every registered route without credentials.
+// None of these requests reach the database, so the repositories can stay nil.
+func TestSecurityHeadersOnEveryRoute(t *testing.T) {
+	router := New(&config.Config{SecureCookies: true}, nil, nil, nil, nil, nil, nil, nil, nil, nil).Router()
+
+	var routes int
+	err := chi.Walk(router.(chi.Routes), func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes++
+		path := urlParam.ReplaceAllString(route, "x")
+		path = strings.ReplaceAll(path, "*", "styles.css")
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+		h := rec.Header()
+		csp := h.Get("Content-Security-Policy")
+		if !strings.Contains(csp, "script-src 'self' 'nonce-") || !strings.Contains(csp, "frame-ancestors 'none'") {
+			t.Errorf("%s %s: Content-Security-Policy = %q", method, route, csp)
+		}
+		for name, want := range map[string]string{
+			"X-Content-Type-Options":    "nosniff",
+			"Referrer-Policy":           "strict-origin-when-cross-origin",
+			"X-Frame-Options":           "DENY",
+			"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+		} {
+			if got := h.Get(name); got != want {
+				t.Errorf("%s %s: %s = %q, want %q", method, route, name, got, want)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if routes == 0 {
+		t.Fatal("walked no routes")
+	}
+}
+
+func TestSwappedContentGetsNoNonce(t *testing.T) {
+	router := New(&config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil).Router()
+
+	// A boosted navigation swaps in the new page's body
+	req := httptest.NewRequest(http.MethodGet, "/login", nil)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Boosted", "true")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	page := html.UnescapeString(rec.Body.String())
+	if strings.Contains(page, "inlineScriptNonce") {
+		t.Error("expected htmx not to give swapped-in scripts the page's nonce")
+	}
+	_, body, ok := strings.Cut(page, "<body")
+	if !ok {
+		t.Fatal("no body in the page")
+	}
+	if strings.Contains(body, "<script") || strings.Contains(body, "nonce") {
+		t.Error("expected no scripts or nonce in the swapped-in body")
+	}
+}
+
+func TestPageScriptsCarryNonce(t *testing.T) {
+	router := New(&config.Config{}, nil, nil, nil, nil, nil, nil, nil, nil, nil).Router()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+	match := regexp.MustCompile(`'nonce-([^']+)'`).FindStringSubmatch(rec.Header().Get("Content-Security-Policy"))
+	if match == nil {
+		t.Fatalf("no nonce in %q", rec.Header().Get("Content-Security-Policy"))
+	}
+	nonce := match[1]
+
+	body := rec.Body.String()
+	scripts := strings.Count(body, "<script")
+	if scripts == 0 {
+		t.Fatal("expected the login page to load scripts")
+	}
+	if got := strings.Count(body, `<script nonce="`+nonce+`"`) + strings.Count(body, `nonce="`+nonce+`"></script>`); got != scripts {
+		t.Errorf("%d of %d scripts carry the request's nonce", got, scripts)
+	}
+	if rec.Header().Get("Strict-Transport-Security") != "" {
+		t.Error("expected no HSTS without secure cookies")
+	}
+
+	// Every response gets its own nonce
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if strings.Contains(rec.Header().Get("Content-Security-Policy"), nonce) {
+		t.Error("expected a fresh nonce per request")
+	}
+}
@@ -0,0 +1,198 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/drywaters/learnd/internal/metrics"
+	"github.com/drywaters/learnd/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultBreakerThreshold = 3
+	defaultBreakerCooldown  = 5 * time.Minute
+)
+
+// BreakerConfig configures the circuit breaker kept for each provider in a Chain
+type BreakerConfig struct {
+	// Threshold is the number of consecutive retryable failures that opens the circuit
+	Threshold int
+	// Cooldown is how long the circuit stays open before a single probe is allowed
+	Cooldown time.Duration
+}
+
+// Chain implements Summarizer by trying providers in order until one succeeds.
+// Each provider has a circuit breaker so an outage or rate limit stops being
+// hit for the rest of the batch. The Result records the provider that produced it.
+type Chain struct {
+	links []chainLink
+}
+
+type chainLink struct {
+	summarizer Summarizer
+	breaker    *breaker
+}
+
+// NewChain creates a fallback chain; the first provider is the primary
+func NewChain(cfg BreakerConfig, providers ...Summarizer) *Chain {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = defaultBreakerThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultBreakerCooldown
+	}
+
+	links := make([]chainLink, 0, len(providers))
+	for _, p := range providers {
+		links = append(links, chainLink{summarizer: p, breaker: newBreaker(cfg, time.Now)})
+	}
+	return &Chain{links: links}
+}
+
+// Provider, Model and Version describe the primary provider. They don't follow
+// fallbacks, so a summary cache keyed on them finds the primary's summaries however
+// the last one was produced; each Result says which provider actually produced it.
+func (c *Chain) Provider() string { return c.links[0].summarizer.Provider() }
+func (c *Chain) Model() string    { return c.links[0].summarizer.Model() }
+func (c *Chain) Version() string  { return c.links[0].summarizer.Version() }
+
+// Summarize tries each provider whose circuit is closed or ready for a probe.
+// If every attempt failed with a retryable error or was skipped, the returned
+// error wraps ErrUnavailable, and ErrCircuitOpen when no provider was tried at all.
+func (c *Chain) Summarize(ctx context.Context, input Input) (*Result, error) {
+	var errs []error
+	allRetryable := true
+	attempted := false
+
+	for i, link := range c.links {
+		name := link.summarizer.Provider()
+		if !link.breaker.allow() {
+			errs = append(errs, fmt.Errorf("%s: circuit open", name))
+			continue
+		}
+
+		attempted = true
+		result, err := link.summarize(ctx, input)
+		if err == nil {
+			link.breaker.record(false)
+			if i > 0 {
+				slog.Info("summarizer fallback used", "provider", name, "primary", c.links[0].summarizer.Provider())
+			}
+			return result, nil
+		}
+
+		retryable := IsRetryable(err)
+		metrics.SummarizerErrors.WithLabelValues(name, strconv.FormatBool(retryable)).Inc()
+		// Permanent errors mean the provider is reachable, so they don't trip the breaker
+		if link.breaker.record(retryable) {
+			slog.Warn("summarizer circuit opened", "provider", name, "error", err)
+		}
+		allRetryable = allRetryable && retryable
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if !attempted {
+		return nil, fmt.Errorf("%w: %w", ErrCircuitOpen, errors.Join(errs...))
+	}
+	if allRetryable {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, errors.Join(errs...))
+	}
+	return nil, errors.Join(errs...)
+}
+
+// summarize calls the link's provider in its own span, recording its latency and usage
+func (l chainLink) summarize(ctx context.Context, input Input) (*Result, error) {
+	name := l.summarizer.Provider()
+	ctx, span := tracing.Start(ctx, "summarize "+name, trace.WithAttributes(
+		attribute.String("summarizer.provider", name),
+		attribute.String("summarizer.model", l.summarizer.Model()),
+	))
+	start := time.Now()
+	result, err := l.summarizer.Summarize(ctx, input)
+	metrics.SummarizerDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+	if err == nil {
+		metrics.SummarizerTokens.WithLabelValues(name, "input").Add(float64(result.InputTokens))
+		metrics.SummarizerTokens.WithLabelValues(name, "output").Add(float64(result.OutputTokens))
+		span.SetAttributes(
+			attribute.Int("summarizer.input_tokens", result.InputTokens),
+			attribute.Int("summarizer.output_tokens", result.OutputTokens),
+		)
+	}
+	tracing.End(span, err)
+	return result, err
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breaker is a consecutive-failure circuit breaker with a single half-open probe
+type breaker struct {
+	mu       sync.Mutex
+	cfg      BreakerConfig
+	now      func() time.Time
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+func newBreaker(cfg BreakerConfig, now func() time.Time) *breaker {
+	return &breaker{cfg: cfg, now: now}
+}
+
+// allow reports whether a request may be attempted. Once the cooldown has
+// elapsed an open breaker lets exactly one probe through.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.Cooldown {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of an attempt and reports
+// whether this failure opened the circuit.
+func (b *breaker) record(failed bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.state = breakerClosed
+		b.failures = 0
+		return false
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.cfg.Threshold {
+		opened := b.state != breakerOpen
+		b.state = breakerOpen
+		b.openedAt = b.now()
+		return opened
+	}
+	return false
+}
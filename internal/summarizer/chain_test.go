@@ -0,0 +1,162 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type fakeSummarizer struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (f *fakeSummarizer) Provider() string { return f.name }
+func (f *fakeSummarizer) Model() string    { return f.name + "-model" }
+func (f *fakeSummarizer) Version() string  { return "test" }
+func (f *fakeSummarizer) Summarize(_ context.Context, _ Input) (*Result, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &Result{Text: "summary from " + f.name, Provider: f.name, Model: f.Model()}, nil
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &APIError{Provider: "openai", StatusCode: http.StatusTooManyRequests}, true},
+		{"server error", fmt.Errorf("wrapped: %w", &APIError{Provider: "ollama", StatusCode: http.StatusBadGateway}), true},
+		{"bad request", &APIError{Provider: "openai", StatusCode: http.StatusBadRequest}, false},
+		{"unauthorized", &APIError{Provider: "openai", StatusCode: http.StatusUnauthorized}, false},
+		{"timeout", fmt.Errorf("request failed: %w", context.DeadlineExceeded), true},
+		{"unavailable", ErrUnavailable, true},
+		{"no text", errors.New("no text generated"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainFallsBackAndRecordsProvider(t *testing.T) {
+	primary := &fakeSummarizer{name: "gemini", err: &APIError{Provider: "gemini", StatusCode: http.StatusServiceUnavailable}}
+	fallback := &fakeSummarizer{name: "ollama"}
+
+	chain := NewChain(BreakerConfig{}, primary, fallback)
+	result, err := chain.Summarize(context.Background(), Input{Title: "t"})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if result.Provider != "ollama" || result.Model != "ollama-model" {
+		t.Errorf("result provider/model = %s/%s, want ollama/ollama-model", result.Provider, result.Model)
+	}
+	// The chain keeps describing the primary, so cache keys don't follow a fallback
+	if chain.Provider() != "gemini" || chain.Model() != "gemini-model" {
+		t.Errorf("chain provider/model = %s/%s, want the primary's", chain.Provider(), chain.Model())
+	}
+}
+
+func TestChainOpensCircuitAfterThreshold(t *testing.T) {
+	primary := &fakeSummarizer{name: "openai", err: &APIError{Provider: "openai", StatusCode: http.StatusTooManyRequests}}
+	fallback := &fakeSummarizer{name: "local"}
+
+	chain := NewChain(BreakerConfig{Threshold: 2, Cooldown: time.Hour}, primary, fallback)
+	for i := 0; i < 5; i++ {
+		if _, err := chain.Summarize(context.Background(), Input{Title: "t"}); err != nil {
+			t.Fatalf("Summarize() #%d error = %v", i, err)
+		}
+	}
+
+	if primary.calls != 2 {
+		t.Errorf("primary called %d times, want 2 before the circuit opened", primary.calls)
+	}
+	if fallback.calls != 5 {
+		t.Errorf("fallback called %d times, want 5", fallback.calls)
+	}
+}
+
+func TestChainPermanentErrorsDoNotOpenCircuit(t *testing.T) {
+	primary := &fakeSummarizer{name: "openai", err: &APIError{Provider: "openai", StatusCode: http.StatusBadRequest}}
+
+	chain := NewChain(BreakerConfig{Threshold: 1, Cooldown: time.Hour}, primary)
+	for i := 0; i < 3; i++ {
+		_, err := chain.Summarize(context.Background(), Input{Title: "t"})
+		if err == nil || IsRetryable(err) {
+			t.Fatalf("Summarize() #%d error = %v, want permanent error", i, err)
+		}
+	}
+	if primary.calls != 3 {
+		t.Errorf("primary called %d times, want 3", primary.calls)
+	}
+}
+
+func TestChainAllUnavailableIsRetryable(t *testing.T) {
+	primary := &fakeSummarizer{name: "openai", err: &APIError{Provider: "openai", StatusCode: http.StatusInternalServerError}}
+
+	chain := NewChain(BreakerConfig{Threshold: 1, Cooldown: time.Hour}, primary)
+	for i := 0; i < 2; i++ {
+		_, err := chain.Summarize(context.Background(), Input{Title: "t"})
+		if !errors.Is(err, ErrUnavailable) {
+			t.Fatalf("Summarize() #%d error = %v, want ErrUnavailable", i, err)
+		}
+		// Only the second call skipped every provider
+		if got := errors.Is(err, ErrCircuitOpen); got != (i == 1) {
+			t.Errorf("Summarize() #%d errors.Is(ErrCircuitOpen) = %v", i, got)
+		}
+	}
+	if primary.calls != 1 {
+		t.Errorf("primary called %d times, want 1 with the circuit open", primary.calls)
+	}
+}
+
+func TestBreakerHalfOpenProbe(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := newBreaker(BreakerConfig{Threshold: 2, Cooldown: time.Minute}, func() time.Time { return now })
+
+	b.record(true)
+	if !b.allow() {
+		t.Fatal("breaker opened before reaching the threshold")
+	}
+	if opened := b.record(true); !opened {
+		t.Fatal("record() did not report the circuit opening")
+	}
+	if b.allow() {
+		t.Fatal("open breaker allowed a request during cooldown")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("breaker did not allow a probe after cooldown")
+	}
+	if b.allow() {
+		t.Fatal("half-open breaker allowed a second concurrent probe")
+	}
+
+	// A failed probe re-opens the circuit for another cooldown
+	b.record(true)
+	if b.allow() {
+		t.Fatal("breaker allowed a request after a failed probe")
+	}
+
+	now = now.Add(time.Minute)
+	if !b.allow() {
+		t.Fatal("breaker did not allow a second probe")
+	}
+	b.record(false)
+	if !b.allow() || !b.allow() {
+		t.Fatal("breaker did not close after a successful probe")
+	}
+}
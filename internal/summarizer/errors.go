@@ -0,0 +1,60 @@
+package summarizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// ErrUnavailable is returned when no provider could produce a summary because
+// of transient failures or open circuit breakers. Callers should retry later.
+var ErrUnavailable = errors.New("summarizer unavailable")
+
+// ErrCircuitOpen is returned when every provider was skipped because its circuit
+// breaker is open, so nothing was attempted. It wraps ErrUnavailable.
+var ErrCircuitOpen = fmt.Errorf("%w: all circuits open", ErrUnavailable)
+
+// APIError reports an error response from a provider's HTTP API
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s API error: %d", e.Provider, e.StatusCode)
+	}
+	return fmt.Sprintf("%s API error: %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// IsRetryable reports whether err is transient (rate limiting, a server error
+// or a timeout) rather than a permanent failure for this input or configuration.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		return isRetryableStatus(googleErr.Code)
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
@@ -0,0 +1,250 @@
+package summarizer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// LocalProvider identifies summaries produced offline, without an LLM.
+	// Entries summarized by it are re-queued when a model becomes available.
+	LocalProvider = "local"
+
+	extractiveModel        = "textrank"
+	extractiveVersion      = "1.0.0"
+	extractiveMaxSentences = 2
+	extractiveMaxLength    = 400
+
+	textRankDamping    = 0.85
+	textRankIterations = 50
+	textRankTolerance  = 1e-4
+)
+
+var sentenceBoundary = regexp.MustCompile(`([.!?]["')\]]?)\s+`)
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true, "you": true,
+	"all": true, "any": true, "can": true, "had": true, "her": true, "was": true, "one": true,
+	"our": true, "out": true, "has": true, "have": true, "his": true, "how": true, "its": true,
+	"may": true, "new": true, "now": true, "see": true, "who": true, "did": true, "get": true,
+	"this": true, "that": true, "with": true, "from": true, "they": true, "will": true,
+	"what": true, "when": true, "your": true, "about": true, "which": true, "their": true,
+	"there": true, "these": true, "those": true, "into": true, "than": true, "then": true,
+	"them": true, "been": true, "were": true, "also": true, "more": true, "most": true,
+	"some": true, "such": true, "only": true, "over": true, "just": true, "very": true,
+	"here": true, "where": true, "while": true, "would": true, "could": true, "should": true,
+}
+
+// ExtractiveSummarizer implements Summarizer without an LLM by ranking the
+// sentences of the enriched description with TextRank and keeping the best ones.
+type ExtractiveSummarizer struct{}
+
+// NewExtractiveSummarizer creates a new offline extractive summarizer
+func NewExtractiveSummarizer() *ExtractiveSummarizer {
+	return &ExtractiveSummarizer{}
+}
+
+func (e *ExtractiveSummarizer) Provider() string { return LocalProvider }
+func (e *ExtractiveSummarizer) Model() string    { return extractiveModel }
+func (e *ExtractiveSummarizer) Version() string  { return extractiveVersion }
+
+func (e *ExtractiveSummarizer) Summarize(_ context.Context, input Input) (*Result, error) {
+	text := extractiveSummary(input.Title, input.Description)
+	if text == "" {
+		return nil, fmt.Errorf("no text to summarize")
+	}
+
+	return &Result{
+		Text:        text,
+		Provider:    e.Provider(),
+		Model:       e.Model(),
+		Version:     e.Version(),
+		GeneratedAt: time.Now().UTC(),
+	}, nil
+}
+
+// extractiveSummary picks the highest ranked sentences of the description,
+// kept in their original order. Falls back to the title when there is no description.
+func extractiveSummary(title, description string) string {
+	sentences := splitSentences(description)
+	if len(sentences) == 0 {
+		return strings.TrimSpace(title)
+	}
+	if len(sentences) <= extractiveMaxSentences {
+		return truncateSummary(strings.Join(sentences, " "))
+	}
+
+	tokens := make([][]string, len(sentences))
+	for i, sentence := range sentences {
+		tokens[i] = tokenize(sentence)
+	}
+
+	scores := textRank(tokens)
+
+	// Favor sentences that share words with the title, and lead sentences slightly
+	titleTokens := tokenize(title)
+	for i := range scores {
+		scores[i] *= 1 + overlapRatio(tokens[i], titleTokens) + 0.1/float64(i+1)
+	}
+
+	order := make([]int, len(sentences))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[order[a]] > scores[order[b]]
+	})
+
+	picked := order[:extractiveMaxSentences]
+	sort.Ints(picked)
+
+	selected := make([]string, 0, len(picked))
+	for _, i := range picked {
+		selected = append(selected, sentences[i])
+	}
+	return truncateSummary(strings.Join(selected, " "))
+}
+
+// splitSentences splits text on sentence-ending punctuation followed by whitespace
+func splitSentences(text string) []string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return nil
+	}
+
+	marked := sentenceBoundary.ReplaceAllString(text, "$1\n")
+	var sentences []string
+	for _, s := range strings.Split(marked, "\n") {
+		if s = strings.TrimSpace(s); s != "" {
+			sentences = append(sentences, s)
+		}
+	}
+	return sentences
+}
+
+// tokenize lowercases text and returns its content words
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, w := range words {
+		if len([]rune(w)) > 2 && !stopWords[w] {
+			tokens = append(tokens, w)
+		}
+	}
+	return tokens
+}
+
+// textRank scores sentences with PageRank over a word-overlap similarity graph
+func textRank(tokens [][]string) []float64 {
+	n := len(tokens)
+	weights := make([][]float64, n)
+	outSums := make([]float64, n)
+	for i := range tokens {
+		weights[i] = make([]float64, n)
+		for j := range tokens {
+			if i != j {
+				weights[i][j] = sentenceSimilarity(tokens[i], tokens[j])
+				outSums[i] += weights[i][j]
+			}
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+
+	for iter := 0; iter < textRankIterations; iter++ {
+		next := make([]float64, n)
+		delta := 0.0
+		for i := range next {
+			sum := 0.0
+			for j := range tokens {
+				if weights[j][i] > 0 && outSums[j] > 0 {
+					sum += weights[j][i] / outSums[j] * scores[j]
+				}
+			}
+			next[i] = (1 - textRankDamping) + textRankDamping*sum
+			delta += math.Abs(next[i] - scores[i])
+		}
+		scores = next
+		if delta < textRankTolerance {
+			break
+		}
+	}
+
+	return scores
+}
+
+// sentenceSimilarity is the TextRank similarity: shared words normalized by sentence lengths
+func sentenceSimilarity(a, b []string) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+
+	seen := make(map[string]bool, len(a))
+	for _, w := range a {
+		seen[w] = true
+	}
+	shared := 0
+	for _, w := range uniqueWords(b) {
+		if seen[w] {
+			shared++
+		}
+	}
+
+	return float64(shared) / (math.Log(float64(len(a))) + math.Log(float64(len(b))))
+}
+
+// overlapRatio returns the fraction of reference words that appear in tokens
+func overlapRatio(tokens, reference []string) float64 {
+	unique := uniqueWords(reference)
+	if len(unique) == 0 {
+		return 0
+	}
+
+	seen := make(map[string]bool, len(tokens))
+	for _, w := range tokens {
+		seen[w] = true
+	}
+	shared := 0
+	for _, w := range unique {
+		if seen[w] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(unique))
+}
+
+func uniqueWords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	unique := make([]string, 0, len(words))
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			unique = append(unique, w)
+		}
+	}
+	return unique
+}
+
+// truncateSummary limits the summary length, breaking at a word boundary
+func truncateSummary(text string) string {
+	if len(text) <= extractiveMaxLength {
+		return text
+	}
+	cut := text[:extractiveMaxLength]
+	if idx := strings.LastIndex(cut, " "); idx > extractiveMaxLength/2 {
+		cut = cut[:idx]
+	}
+	return strings.ToValidUTF8(strings.TrimRight(cut, " ,;:"), "") + "..."
+}
@@ -0,0 +1,113 @@
+package summarizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSplitSentences(t *testing.T) {
+	got := splitSentences("First sentence.  Second one!\nThird? \"Quoted.\" Last without stop")
+	want := []string{"First sentence.", "Second one!", "Third?", "\"Quoted.\"", "Last without stop"}
+
+	if len(got) != len(want) {
+		t.Fatalf("splitSentences() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("sentence %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestExtractiveSummaryPicksCentralSentences(t *testing.T) {
+	description := "Subscribe to our newsletter for weekly updates. " +
+		"Go channels coordinate goroutines by passing values between concurrent goroutines. " +
+		"Buffered channels let goroutines send values without blocking until the buffer fills. " +
+		"Our sponsor makes great coffee mugs. " +
+		"Select statements let goroutines wait on multiple channels at once."
+
+	got := extractiveSummary("Go Channels and Goroutines", description)
+
+	if strings.Contains(got, "newsletter") || strings.Contains(got, "coffee") {
+		t.Errorf("summary kept an off-topic sentence: %q", got)
+	}
+	if !strings.Contains(got, "Go channels coordinate goroutines") {
+		t.Errorf("summary missing the central sentence: %q", got)
+	}
+	if n := len(splitSentences(got)); n != extractiveMaxSentences {
+		t.Errorf("summary has %d sentences, want %d: %q", n, extractiveMaxSentences, got)
+	}
+}
+
+func TestExtractiveSummaryKeepsOriginalOrder(t *testing.T) {
+	description := "Rust ownership rules prevent data races at compile time. " +
+		"Unrelated filler about the weather today. " +
+		"Borrowing in Rust follows ownership rules enforced by the compiler."
+
+	got := extractiveSummary("Rust ownership", description)
+	first := strings.Index(got, "Rust ownership rules")
+	second := strings.Index(got, "Borrowing in Rust")
+	if first == -1 || second == -1 || first > second {
+		t.Errorf("summary = %q, want both ownership sentences in original order", got)
+	}
+}
+
+func TestExtractiveSummarizer(t *testing.T) {
+	s := NewExtractiveSummarizer()
+
+	tests := []struct {
+		name    string
+		input   Input
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "short description used as is",
+			input: Input{Title: "Title", Description: "Only one sentence here."},
+			want:  "Only one sentence here.",
+		},
+		{
+			name:  "title fallback",
+			input: Input{Title: "  Just a title "},
+			want:  "Just a title",
+		},
+		{
+			name:    "nothing to summarize",
+			input:   Input{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := s.Summarize(context.Background(), tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Summarize() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Summarize() error = %v", err)
+			}
+			if result.Text != tt.want {
+				t.Errorf("Text = %q, want %q", result.Text, tt.want)
+			}
+			if result.Provider != LocalProvider {
+				t.Errorf("Provider = %q, want %q", result.Provider, LocalProvider)
+			}
+		})
+	}
+}
+
+func TestTruncateSummary(t *testing.T) {
+	long := strings.Repeat("word ", 200)
+	got := truncateSummary(long)
+	if len(got) > extractiveMaxLength+3 {
+		t.Errorf("len(truncateSummary()) = %d, want <= %d", len(got), extractiveMaxLength+3)
+	}
+	if !strings.HasSuffix(got, "word...") {
+		t.Errorf("truncateSummary() = %q, want to end at a word boundary", got[len(got)-20:])
+	}
+}
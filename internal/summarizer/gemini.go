@@ -11,38 +11,53 @@ import (
 )
 
 const (
-	geminiProvider     = "gemini"
-	geminiDefaultModel = "gemini-2.5-flash-lite"
-	geminiVersion      = "1.0.0"
+	geminiProvider         = "gemini"
+	geminiDefaultModel     = "gemini-2.5-flash-lite"
+	geminiVersion          = "1.0.0"
+	geminiDefaultTimeout   = 30 * time.Second
+	geminiDefaultMaxTokens = 150
 )
 
 // GeminiSummarizer implements Summarizer using Google's Gemini API
 type GeminiSummarizer struct {
 	client    *genai.Client
 	model     *genai.GenerativeModel
+	jsonModel *genai.GenerativeModel
 	modelName string
+	timeout   time.Duration
 }
 
 // NewGeminiSummarizer creates a new Gemini summarizer
-func NewGeminiSummarizer(ctx context.Context, apiKey string) (*GeminiSummarizer, error) {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+func NewGeminiSummarizer(ctx context.Context, cfg Config) (*GeminiSummarizer, error) {
+	cfg = cfg.withDefaults(geminiDefaultModel, "", geminiDefaultTimeout, geminiDefaultMaxTokens)
+
+	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
-	model := client.GenerativeModel(geminiDefaultModel)
+	model := client.GenerativeModel(cfg.Model)
 
 	// Configure for concise summaries
 	temp := float32(0.3)
 	model.Temperature = &temp
 
-	maxTokens := int32(150)
+	maxTokens := int32(cfg.MaxTokens)
 	model.MaxOutputTokens = &maxTokens
 
+	// Structured summaries use JSON mode and a larger output limit
+	jsonModel := client.GenerativeModel(cfg.Model)
+	jsonModel.Temperature = &temp
+	jsonMaxTokens := int32(maxTokensFor(Input{Structured: true}, cfg.MaxTokens))
+	jsonModel.MaxOutputTokens = &jsonMaxTokens
+	jsonModel.ResponseMIMEType = "application/json"
+
 	return &GeminiSummarizer{
 		client:    client,
 		model:     model,
-		modelName: geminiDefaultModel,
+		jsonModel: jsonModel,
+		modelName: cfg.Model,
+		timeout:   cfg.Timeout,
 	}, nil
 }
 
@@ -53,7 +68,15 @@ func (g *GeminiSummarizer) Version() string  { return geminiVersion }
 func (g *GeminiSummarizer) Summarize(ctx context.Context, input Input) (*Result, error) {
 	prompt := buildPrompt(input)
 
-	resp, err := g.model.GenerateContent(ctx, genai.Text(prompt))
+	ctx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+
+	model := g.model
+	if input.Structured {
+		model = g.jsonModel
+	}
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
 		return nil, fmt.Errorf("gemini generation failed: %w", err)
 	}
@@ -63,13 +86,24 @@ func (g *GeminiSummarizer) Summarize(ctx context.Context, input Input) (*Result,
 		return nil, fmt.Errorf("no text generated")
 	}
 
-	return &Result{
+	text, insights, err := parseOutput(g.Provider(), text, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
 		Text:        text,
+		Insights:    insights,
 		Provider:    g.Provider(),
 		Model:       g.Model(),
-		Version:     g.Version(),
+		Version:     VersionWithPrompt(g.Version(), input.PromptVersion),
 		GeneratedAt: time.Now().UTC(),
-	}, nil
+	}
+	if resp.UsageMetadata != nil {
+		result.InputTokens = int(resp.UsageMetadata.PromptTokenCount)
+		result.OutputTokens = int(resp.UsageMetadata.CandidatesTokenCount)
+	}
+	return result, nil
 }
 
 // Close closes the Gemini client
@@ -77,42 +111,6 @@ func (g *GeminiSummarizer) Close() error {
 	return g.client.Close()
 }
 
-func buildPrompt(input Input) string {
-	var sb strings.Builder
-
-	sb.WriteString("Summarize this ")
-	sb.WriteString(string(input.SourceType))
-	sb.WriteString(" in 1-2 concise sentences for a learning log. ")
-	sb.WriteString("Focus on the key takeaway or main topic. Be direct and informative.\n\n")
-
-	if input.Title != "" {
-		sb.WriteString("Title: ")
-		sb.WriteString(input.Title)
-		sb.WriteString("\n\n")
-	}
-
-	if input.Description != "" {
-		// Limit description to avoid token limits
-		desc := input.Description
-		if len(desc) > 1000 {
-			desc = desc[:1000] + "..."
-		}
-		sb.WriteString("Description: ")
-		sb.WriteString(desc)
-		sb.WriteString("\n\n")
-	}
-
-	if input.Tag != "" {
-		sb.WriteString("Topics: ")
-		sb.WriteString(input.Tag)
-		sb.WriteString("\n\n")
-	}
-
-	sb.WriteString("Summary:")
-
-	return sb.String()
-}
-
 func extractText(resp *genai.GenerateContentResponse) string {
 	if resp == nil || len(resp.Candidates) == 0 {
 		return ""
@@ -0,0 +1,132 @@
+package summarizer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+const (
+	// structuredMinTokens leaves room for the JSON object with takeaways
+	structuredMinTokens = 500
+
+	minTakeaways     = 3
+	maxTakeaways     = 5
+	maxSuggestedTags = 3
+)
+
+var difficultyLevels = []string{model.DifficultyBeginner, model.DifficultyIntermediate, model.DifficultyAdvanced}
+
+// structuredOutput is the JSON schema requested from providers in structured mode
+type structuredOutput struct {
+	Summary       string   `json:"summary"`
+	Takeaways     []string `json:"takeaways"`
+	Difficulty    string   `json:"difficulty"`
+	SuggestedTags []string `json:"suggested_tags"`
+}
+
+// structuredInstructions asks for the structured JSON object, limiting tags to the vocabulary
+func structuredInstructions(vocabulary []string) string {
+	var sb strings.Builder
+	sb.WriteString("\n\nRespond with only a JSON object, no other text, with these fields:\n")
+	sb.WriteString(`- "summary": the summary in one line` + "\n")
+	fmt.Fprintf(&sb, `- "takeaways": %d to %d key takeaways, each a short sentence`+"\n", minTakeaways, maxTakeaways)
+	fmt.Fprintf(&sb, `- "difficulty": one of "%s"`+"\n", strings.Join(difficultyLevels, `", "`))
+	if len(vocabulary) > 0 {
+		fmt.Fprintf(&sb, `- "suggested_tags": up to %d tags chosen only from: %s`+"\n", maxSuggestedTags, strings.Join(vocabulary, ", "))
+	} else {
+		sb.WriteString(`- "suggested_tags": an empty list` + "\n")
+	}
+	return sb.String()
+}
+
+// maxTokensFor raises the output token limit for structured requests
+func maxTokensFor(input Input, maxTokens int) int {
+	if input.Structured && maxTokens < structuredMinTokens {
+		return structuredMinTokens
+	}
+	return maxTokens
+}
+
+// parseInsights validates structured output against the schema. Suggested tags
+// outside the vocabulary are dropped rather than treated as invalid.
+func parseInsights(raw string, vocabulary []string) (string, *model.SummaryInsights, error) {
+	var out structuredOutput
+	if err := json.Unmarshal([]byte(stripCodeFence(raw)), &out); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	summary := strings.TrimSpace(out.Summary)
+	if summary == "" {
+		return "", nil, errors.New("missing summary")
+	}
+
+	var takeaways []string
+	for _, t := range out.Takeaways {
+		if t = strings.TrimSpace(t); t != "" {
+			takeaways = append(takeaways, t)
+		}
+	}
+	if len(takeaways) < minTakeaways || len(takeaways) > maxTakeaways {
+		return summary, nil, fmt.Errorf("got %d takeaways, want %d to %d", len(takeaways), minTakeaways, maxTakeaways)
+	}
+
+	difficulty := strings.ToLower(strings.TrimSpace(out.Difficulty))
+	if !slices.Contains(difficultyLevels, difficulty) {
+		return summary, nil, fmt.Errorf("invalid difficulty %q", out.Difficulty)
+	}
+
+	var tags []string
+	for _, tag := range out.SuggestedTags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if slices.Contains(vocabulary, tag) && !slices.Contains(tags, tag) && len(tags) < maxSuggestedTags {
+			tags = append(tags, tag)
+		}
+	}
+
+	return summary, &model.SummaryInsights{
+		Takeaways:     takeaways,
+		Difficulty:    difficulty,
+		SuggestedTags: tags,
+	}, nil
+}
+
+// parseOutput turns provider output into summary text and optional insights.
+// Structured output that fails validation falls back to plain text: the summary
+// field if it could be read, otherwise the raw text unless it is malformed JSON.
+func parseOutput(provider, raw string, input Input) (string, *model.SummaryInsights, error) {
+	if !input.Structured {
+		return raw, nil, nil
+	}
+
+	summary, insights, err := parseInsights(raw, input.TagVocabulary)
+	if err == nil {
+		return summary, insights, nil
+	}
+
+	slog.Warn("structured summary failed validation, using plain text", "provider", provider, "error", err)
+	if summary != "" {
+		return summary, nil, nil
+	}
+	if text := stripCodeFence(raw); !strings.HasPrefix(text, "{") {
+		return text, nil, nil
+	}
+	return "", nil, fmt.Errorf("invalid structured output: %w", err)
+}
+
+// stripCodeFence removes a markdown code fence that some models wrap JSON in
+func stripCodeFence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimPrefix(s, "json")
+	s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+	return strings.TrimSpace(s)
+}
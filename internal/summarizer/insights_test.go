@@ -0,0 +1,144 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+func TestParseInsights(t *testing.T) {
+	vocabulary := []string{"go", "databases", "testing"}
+
+	t.Run("valid output", func(t *testing.T) {
+		raw := "```json\n" + `{
+			"summary": " Covers Go's testing package. ",
+			"takeaways": ["Use table tests", "Prefer t.Run", "", "Keep helpers small"],
+			"difficulty": "Intermediate",
+			"suggested_tags": ["testing", "GO", "rust", "testing"]
+		}` + "\n```"
+
+		summary, insights, err := parseInsights(raw, vocabulary)
+		if err != nil {
+			t.Fatalf("parseInsights() error = %v", err)
+		}
+		if summary != "Covers Go's testing package." {
+			t.Errorf("summary = %q", summary)
+		}
+		want := &model.SummaryInsights{
+			Takeaways:     []string{"Use table tests", "Prefer t.Run", "Keep helpers small"},
+			Difficulty:    model.DifficultyIntermediate,
+			SuggestedTags: []string{"testing", "go"},
+		}
+		if !reflect.DeepEqual(insights, want) {
+			t.Errorf("insights = %+v, want %+v", insights, want)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		raw         string
+		wantSummary string
+	}{
+		{"not JSON", "Just a sentence.", ""},
+		{"missing summary", `{"takeaways": ["a", "b", "c"], "difficulty": "beginner"}`, ""},
+		{"too few takeaways", `{"summary": "S", "takeaways": ["a"], "difficulty": "beginner"}`, "S"},
+		{"too many takeaways", `{"summary": "S", "takeaways": ["a", "b", "c", "d", "e", "f"], "difficulty": "beginner"}`, "S"},
+		{"unknown difficulty", `{"summary": "S", "takeaways": ["a", "b", "c"], "difficulty": "expert"}`, "S"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, insights, err := parseInsights(tt.raw, vocabulary)
+			if err == nil {
+				t.Fatal("parseInsights() error = nil, want error")
+			}
+			if insights != nil {
+				t.Errorf("insights = %+v, want nil", insights)
+			}
+			if summary != tt.wantSummary {
+				t.Errorf("summary = %q, want %q", summary, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestParseOutputFallsBackToPlainText(t *testing.T) {
+	input := Input{Structured: true}
+
+	tests := []struct {
+		name     string
+		raw      string
+		wantText string
+		wantErr  bool
+	}{
+		{"plain text", "A plain summary.", "A plain summary.", false},
+		{"partial JSON keeps summary", `{"summary": "From JSON.", "takeaways": []}`, "From JSON.", false},
+		{"malformed JSON", `{"summary": `, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, insights, err := parseOutput("test", tt.raw, input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if insights != nil {
+				t.Errorf("insights = %+v, want nil", insights)
+			}
+		})
+	}
+
+	// Without structured mode the output is used as-is
+	if text, _, _ := parseOutput("test", `{"summary": "x"}`, Input{}); text != `{"summary": "x"}` {
+		t.Errorf("parseOutput() unstructured text = %q", text)
+	}
+}
+
+func TestOpenAISummarizerStructured(t *testing.T) {
+	var got openAIChatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		content, _ := json.Marshal(`{"summary": "Indexes speed up reads.", "takeaways": ["B-trees", "Write cost", "Covering indexes"], "difficulty": "advanced", "suggested_tags": ["databases"]}`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": ` + string(content) + `}}]}`))
+	}))
+	defer srv.Close()
+
+	s := NewOpenAISummarizer(Config{BaseURL: srv.URL})
+	result, err := s.Summarize(context.Background(), Input{
+		Title:         "Postgres indexing",
+		SourceType:    model.SourceTypeArticle,
+		Structured:    true,
+		TagVocabulary: []string{"databases", "go"},
+	})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	if got.ResponseFormat == nil || got.ResponseFormat.Type != "json_object" {
+		t.Errorf("response_format = %+v, want json_object", got.ResponseFormat)
+	}
+	if got.MaxTokens < structuredMinTokens {
+		t.Errorf("max_tokens = %d, want at least %d", got.MaxTokens, structuredMinTokens)
+	}
+	if prompt := got.Messages[0].Content; !strings.Contains(prompt, "databases, go") {
+		t.Errorf("prompt does not list the tag vocabulary:\n%s", prompt)
+	}
+	if result.Text != "Indexes speed up reads." {
+		t.Errorf("Text = %q", result.Text)
+	}
+	if result.Insights == nil || result.Insights.Difficulty != model.DifficultyAdvanced ||
+		!reflect.DeepEqual(result.Insights.SuggestedTags, []string{"databases"}) {
+		t.Errorf("Insights = %+v", result.Insights)
+	}
+}
@@ -0,0 +1,129 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ollamaProvider         = "ollama"
+	ollamaDefaultModel     = "llama3.2"
+	ollamaDefaultBaseURL   = "http://localhost:11434"
+	ollamaVersion          = "1.0.0"
+	ollamaDefaultTimeout   = 2 * time.Minute // Local models can be slow, especially on first load
+	ollamaDefaultMaxTokens = 200
+)
+
+// OllamaSummarizer implements Summarizer using a local Ollama server
+type OllamaSummarizer struct {
+	client    *http.Client
+	baseURL   string
+	modelName string
+	maxTokens int
+}
+
+// NewOllamaSummarizer creates a new Ollama summarizer
+func NewOllamaSummarizer(cfg Config) *OllamaSummarizer {
+	cfg = cfg.withDefaults(ollamaDefaultModel, ollamaDefaultBaseURL, ollamaDefaultTimeout, ollamaDefaultMaxTokens)
+
+	return &OllamaSummarizer{
+		client:    &http.Client{Timeout: cfg.Timeout},
+		baseURL:   cfg.BaseURL,
+		modelName: cfg.Model,
+		maxTokens: cfg.MaxTokens,
+	}
+}
+
+func (o *OllamaSummarizer) Provider() string { return ollamaProvider }
+func (o *OllamaSummarizer) Model() string    { return o.modelName }
+func (o *OllamaSummarizer) Version() string  { return ollamaVersion }
+
+func (o *OllamaSummarizer) Summarize(ctx context.Context, input Input) (*Result, error) {
+	payload := ollamaGenerateRequest{
+		Model:  o.modelName,
+		Prompt: buildPrompt(input),
+		Stream: false,
+		Options: ollamaOptions{
+			Temperature: 0.3,
+			NumPredict:  maxTokensFor(input, o.maxTokens),
+		},
+	}
+	if input.Structured {
+		payload.Format = "json"
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("ollama request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &APIError{Provider: ollamaProvider, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	var apiResp ollamaGenerateResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	text := strings.TrimSpace(apiResp.Response)
+	if text == "" {
+		return nil, fmt.Errorf("no text generated")
+	}
+
+	text, insights, err := parseOutput(o.Provider(), text, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Text:         text,
+		Insights:     insights,
+		Provider:     o.Provider(),
+		Model:        o.Model(),
+		Version:      VersionWithPrompt(o.Version(), input.PromptVersion),
+		GeneratedAt:  time.Now().UTC(),
+		InputTokens:  apiResp.PromptEvalCount,
+		OutputTokens: apiResp.EvalCount,
+	}, nil
+}
+
+// Ollama generate API structures
+type ollamaGenerateRequest struct {
+	Model   string        `json:"model"`
+	Prompt  string        `json:"prompt"`
+	Stream  bool          `json:"stream"`
+	Format  string        `json:"format,omitempty"`
+	Options ollamaOptions `json:"options"`
+}
+
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict"`
+}
+
+type ollamaGenerateResponse struct {
+	Response        string `json:"response"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
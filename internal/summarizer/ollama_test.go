@@ -0,0 +1,72 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+func TestOllamaSummarizer(t *testing.T) {
+	var got ollamaGenerateRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/generate" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"model": "llama3.2", "response": "Local summary.", "done": true, "prompt_eval_count": 90, "eval_count": 12}`))
+	}))
+	defer srv.Close()
+
+	s := NewOllamaSummarizer(Config{BaseURL: srv.URL})
+
+	result, err := s.Summarize(context.Background(), Input{
+		Title:       "Understanding B-Trees",
+		Description: "A deep dive into database indexes",
+		SourceType:  model.SourceTypeArticle,
+	})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	if result.Text != "Local summary." {
+		t.Errorf("Text = %q, want %q", result.Text, "Local summary.")
+	}
+	if result.Provider != "ollama" || result.Model != ollamaDefaultModel {
+		t.Errorf("Provider/Model = %s/%s, want ollama/%s", result.Provider, result.Model, ollamaDefaultModel)
+	}
+	if result.InputTokens != 90 || result.OutputTokens != 12 {
+		t.Errorf("tokens = %d/%d, want 90/12", result.InputTokens, result.OutputTokens)
+	}
+	if got.Stream {
+		t.Error("request stream = true, want false")
+	}
+	if got.Options.NumPredict != ollamaDefaultMaxTokens {
+		t.Errorf("request num_predict = %d, want %d", got.Options.NumPredict, ollamaDefaultMaxTokens)
+	}
+	if !strings.Contains(got.Prompt, "Understanding B-Trees") {
+		t.Errorf("request prompt = %q, want title included", got.Prompt)
+	}
+}
+
+func TestOllamaSummarizerModelNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model \"missing\" not found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	s := NewOllamaSummarizer(Config{BaseURL: srv.URL, Model: "missing"})
+	_, err := s.Summarize(context.Background(), Input{Title: "t"})
+	if err == nil || !strings.Contains(err.Error(), "ollama API error: 404") {
+		t.Fatalf("Summarize() error = %v, want ollama 404 error", err)
+	}
+}
@@ -0,0 +1,145 @@
+package summarizer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	openAIProvider         = "openai"
+	openAIDefaultModel     = "gpt-4o-mini"
+	openAIDefaultBaseURL   = "https://api.openai.com/v1"
+	openAIVersion          = "1.0.0"
+	openAIDefaultTimeout   = 30 * time.Second
+	openAIDefaultMaxTokens = 150
+)
+
+// OpenAISummarizer implements Summarizer using an OpenAI-compatible
+// chat completions API. Local servers such as llama.cpp and vLLM expose the
+// same API, so BaseURL can point at them.
+type OpenAISummarizer struct {
+	client    *http.Client
+	baseURL   string
+	apiKey    string
+	modelName string
+	maxTokens int
+}
+
+// NewOpenAISummarizer creates a new OpenAI-compatible summarizer
+func NewOpenAISummarizer(cfg Config) *OpenAISummarizer {
+	cfg = cfg.withDefaults(openAIDefaultModel, openAIDefaultBaseURL, openAIDefaultTimeout, openAIDefaultMaxTokens)
+
+	return &OpenAISummarizer{
+		client:    &http.Client{Timeout: cfg.Timeout},
+		baseURL:   cfg.BaseURL,
+		apiKey:    cfg.APIKey,
+		modelName: cfg.Model,
+		maxTokens: cfg.MaxTokens,
+	}
+}
+
+func (o *OpenAISummarizer) Provider() string { return openAIProvider }
+func (o *OpenAISummarizer) Model() string    { return o.modelName }
+func (o *OpenAISummarizer) Version() string  { return openAIVersion }
+
+func (o *OpenAISummarizer) Summarize(ctx context.Context, input Input) (*Result, error) {
+	payload := openAIChatRequest{
+		Model: o.modelName,
+		Messages: []openAIMessage{
+			{Role: "user", Content: buildPrompt(input)},
+		},
+		MaxTokens:   maxTokensFor(input, o.maxTokens),
+		Temperature: 0.3,
+	}
+	if input.Structured {
+		payload.ResponseFormat = &openAIResponseFormat{Type: "json_object"}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("openai request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &APIError{Provider: openAIProvider, StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	var apiResp openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	text := ""
+	if len(apiResp.Choices) > 0 {
+		text = strings.TrimSpace(apiResp.Choices[0].Message.Content)
+	}
+	if text == "" {
+		return nil, fmt.Errorf("no text generated")
+	}
+
+	text, insights, err := parseOutput(o.Provider(), text, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Text:         text,
+		Insights:     insights,
+		Provider:     o.Provider(),
+		Model:        o.Model(),
+		Version:      VersionWithPrompt(o.Version(), input.PromptVersion),
+		GeneratedAt:  time.Now().UTC(),
+		InputTokens:  apiResp.Usage.PromptTokens,
+		OutputTokens: apiResp.Usage.CompletionTokens,
+	}, nil
+}
+
+// OpenAI chat completions API structures
+type openAIChatRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	MaxTokens      int                   `json:"max_tokens"`
+	Temperature    float64               `json:"temperature"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type string `json:"type"`
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
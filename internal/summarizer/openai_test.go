@@ -0,0 +1,124 @@
+package summarizer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+func TestOpenAISummarizer(t *testing.T) {
+	var got openAIChatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			http.Error(w, "bad auth: "+auth, http.StatusUnauthorized)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices": [{"index": 0, "message": {"role": "assistant", "content": "  A concise summary.\n"}}], "usage": {"prompt_tokens": 120, "completion_tokens": 18}}`))
+	}))
+	defer srv.Close()
+
+	s := NewOpenAISummarizer(Config{
+		BaseURL:   srv.URL + "/v1/",
+		APIKey:    "test-key",
+		Model:     "local-model",
+		MaxTokens: 64,
+	})
+
+	result, err := s.Summarize(context.Background(), Input{
+		Title:      "Go Concurrency Patterns",
+		SourceType: model.SourceTypeYouTube,
+	})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+
+	if result.Text != "A concise summary." {
+		t.Errorf("Text = %q, want %q", result.Text, "A concise summary.")
+	}
+	if result.Provider != "openai" || result.Model != "local-model" {
+		t.Errorf("Provider/Model = %s/%s, want openai/local-model", result.Provider, result.Model)
+	}
+	if result.InputTokens != 120 || result.OutputTokens != 18 {
+		t.Errorf("tokens = %d/%d, want 120/18", result.InputTokens, result.OutputTokens)
+	}
+	if got.Model != "local-model" || got.MaxTokens != 64 {
+		t.Errorf("request model/max_tokens = %s/%d, want local-model/64", got.Model, got.MaxTokens)
+	}
+	if len(got.Messages) != 1 || !strings.Contains(got.Messages[0].Content, "Go Concurrency Patterns") {
+		t.Errorf("request messages = %+v, want prompt containing title", got.Messages)
+	}
+}
+
+func TestOpenAISummarizerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{
+			name:    "rate limited",
+			status:  http.StatusTooManyRequests,
+			body:    `{"error": {"message": "slow down"}}`,
+			wantErr: "openai API error: 429",
+		},
+		{
+			name:    "empty choices",
+			status:  http.StatusOK,
+			body:    `{"choices": []}`,
+			wantErr: "no text generated",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			s := NewOpenAISummarizer(Config{BaseURL: srv.URL})
+			_, err := s.Summarize(context.Background(), Input{Title: "t"})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Summarize() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenAISummarizerTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	s := NewOpenAISummarizer(Config{BaseURL: srv.URL, Timeout: 50 * time.Millisecond})
+	if _, err := s.Summarize(context.Background(), Input{Title: "t"}); err == nil {
+		t.Fatal("Summarize() expected timeout error, got nil")
+	}
+}
+
+func TestNewUnknownProvider(t *testing.T) {
+	if _, err := New(context.Background(), Config{Provider: "bogus"}); err == nil {
+		t.Fatal("New() expected error for unknown provider")
+	}
+}
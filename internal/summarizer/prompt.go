@@ -0,0 +1,125 @@
+package summarizer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+// DefaultPromptTemplate is used for source types without a custom template.
+// Templates are rendered with text/template; see PromptData for the fields available.
+const DefaultPromptTemplate = `Summarize this {{.SourceType}} in 1-2 concise sentences for a learning log. Focus on the key takeaway or main topic. Be direct and informative.
+
+{{if .Title}}Title: {{.Title}}
+
+{{end}}{{if .Description}}Description: {{truncate 1000 .Description}}
+
+{{end}}{{if .Tag}}Topics: {{.Tag}}
+
+{{end}}Summary:`
+
+var defaultPrompt = template.Must(newPromptTemplate().Parse(DefaultPromptTemplate))
+
+// promptVersionSuffix marks the prompt template version in a summary version (semver build metadata)
+var promptVersionSuffix = regexp.MustCompile(`\+prompt\.(\d+)$`)
+
+// PromptData is the data passed to prompt templates
+type PromptData struct {
+	Title       string
+	Description string
+	SourceType  model.SourceType
+	URL         string
+	Tag         string
+}
+
+func newPromptTemplate() *template.Template {
+	return template.New("prompt").Option("missingkey=error").Funcs(template.FuncMap{
+		"truncate": truncatePromptText,
+	})
+}
+
+// ParsePromptTemplate parses a prompt template and checks it renders for a sample input
+func ParsePromptTemplate(text string) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("template is empty")
+	}
+
+	tmpl, err := newPromptTemplate().Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	sample := Input{
+		Title:       "Sample title",
+		Description: "Sample description",
+		SourceType:  model.SourceTypeArticle,
+		URL:         "https://example.com/sample",
+		Tag:         "sample",
+	}
+	if _, err := RenderPrompt(tmpl, sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// RenderPrompt renders a prompt template for the input
+func RenderPrompt(tmpl *template.Template, input Input) (string, error) {
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, PromptData{
+		Title:       input.Title,
+		Description: input.Description,
+		SourceType:  input.SourceType,
+		URL:         input.URL,
+		Tag:         input.Tag,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// VersionWithPrompt appends the prompt template version to an implementation version.
+// Version 0 is the built-in default template and leaves the version unchanged.
+func VersionWithPrompt(version string, promptVersion int) string {
+	if promptVersion <= 0 {
+		return version
+	}
+	return fmt.Sprintf("%s+prompt.%d", version, promptVersion)
+}
+
+// PromptVersion extracts the prompt template version from a summary version
+func PromptVersion(summaryVersion string) int {
+	matches := promptVersionSuffix.FindStringSubmatch(summaryVersion)
+	if len(matches) < 2 {
+		return 0
+	}
+	v, _ := strconv.Atoi(matches[1])
+	return v
+}
+
+// buildPrompt returns the prompt rendered by the caller, or the default prompt,
+// followed by the JSON instructions in structured mode
+func buildPrompt(input Input) string {
+	prompt := input.Prompt
+	if prompt == "" {
+		// The default template only uses fields that always exist, so it can't fail
+		prompt, _ = RenderPrompt(defaultPrompt, input)
+	}
+	if input.Structured {
+		prompt += structuredInstructions(input.TagVocabulary)
+	}
+	return prompt
+}
+
+// truncatePromptText limits text to n characters to avoid token limits
+func truncatePromptText(n int, s string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
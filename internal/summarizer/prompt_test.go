@@ -0,0 +1,83 @@
+package summarizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+)
+
+func TestBuildPromptDefault(t *testing.T) {
+	input := Input{
+		Title:       "Go generics",
+		Description: strings.Repeat("a", 1200),
+		SourceType:  model.SourceTypeArticle,
+		Tag:         "go",
+	}
+
+	want := "Summarize this article in 1-2 concise sentences for a learning log. Focus on the key takeaway or main topic. Be direct and informative.\n\n" +
+		"Title: Go generics\n\n" +
+		"Description: " + strings.Repeat("a", 1000) + "...\n\n" +
+		"Topics: go\n\n" +
+		"Summary:"
+	if got := buildPrompt(input); got != want {
+		t.Errorf("buildPrompt() =\n%q\nwant\n%q", got, want)
+	}
+
+	// Empty fields are omitted
+	got := buildPrompt(Input{SourceType: model.SourceTypeYouTube})
+	want = "Summarize this youtube in 1-2 concise sentences for a learning log. Focus on the key takeaway or main topic. Be direct and informative.\n\nSummary:"
+	if got != want {
+		t.Errorf("buildPrompt() = %q, want %q", got, want)
+	}
+}
+
+func TestTruncatePromptText(t *testing.T) {
+	if got := truncatePromptText(5, "héllo"); got != "héllo" {
+		t.Errorf("truncatePromptText() = %q, want it unchanged", got)
+	}
+	// Multi-byte characters count once and are never split
+	if got, want := truncatePromptText(3, "日本語の説明"), "日本語..."; got != want {
+		t.Errorf("truncatePromptText() = %q, want %q", got, want)
+	}
+}
+
+func TestParsePromptTemplate(t *testing.T) {
+	tmpl, err := ParsePromptTemplate("Summarize {{.Title}} from {{.URL}} in one line.")
+	if err != nil {
+		t.Fatalf("ParsePromptTemplate() error = %v", err)
+	}
+	got, err := RenderPrompt(tmpl, Input{Title: "Talk", URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("RenderPrompt() error = %v", err)
+	}
+	if want := "Summarize Talk from https://example.com in one line."; got != want {
+		t.Errorf("RenderPrompt() = %q, want %q", got, want)
+	}
+
+	for _, text := range []string{"", "  ", "{{.Title", "{{.Missing}}", "{{unknown .Title}}"} {
+		if _, err := ParsePromptTemplate(text); err == nil {
+			t.Errorf("ParsePromptTemplate(%q) error = nil, want error", text)
+		}
+	}
+}
+
+func TestPromptVersion(t *testing.T) {
+	tests := []struct {
+		base    string
+		version int
+		want    string
+	}{
+		{"1.0.0", 0, "1.0.0"},
+		{"1.0.0", 3, "1.0.0+prompt.3"},
+	}
+	for _, tt := range tests {
+		got := VersionWithPrompt(tt.base, tt.version)
+		if got != tt.want {
+			t.Errorf("VersionWithPrompt(%q, %d) = %q, want %q", tt.base, tt.version, got, tt.want)
+		}
+		if pv := PromptVersion(got); pv != tt.version {
+			t.Errorf("PromptVersion(%q) = %d, want %d", got, pv, tt.version)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package summarizer
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/drywaters/learnd/internal/model"
@@ -14,6 +16,34 @@ type Input struct {
 	SourceType  model.SourceType
 	URL         string
 	Tag         string
+
+	// Prompt is an already rendered prompt from a custom template; empty uses the default
+	Prompt string
+	// PromptVersion is the version of the template that rendered Prompt, 0 for the default
+	PromptVersion int
+
+	// Structured requests JSON output with takeaways, difficulty and suggested tags
+	Structured bool
+	// TagVocabulary lists the existing tags that may be suggested in structured mode
+	TagVocabulary []string
+}
+
+// InputFromEntry builds the summarizer input for an entry
+func InputFromEntry(entry *model.Entry) Input {
+	input := Input{
+		SourceType: entry.SourceType,
+		URL:        entry.SourceURL,
+	}
+	if entry.Title != nil {
+		input.Title = *entry.Title
+	}
+	if entry.Description != nil {
+		input.Description = *entry.Description
+	}
+	if entry.Tag != nil {
+		input.Tag = *entry.Tag
+	}
+	return input
 }
 
 // Result contains the generated summary and metadata
@@ -23,6 +53,13 @@ type Result struct {
 	Model       string
 	Version     string
 	GeneratedAt time.Time
+
+	// Insights is set when structured output was requested and passed validation
+	Insights *model.SummaryInsights
+
+	// Tokens the provider reports for the prompt and the generated text; zero when unknown
+	InputTokens  int
+	OutputTokens int
 }
 
 // Summarizer defines the interface for AI-powered text summarization
@@ -40,3 +77,54 @@ type Summarizer interface {
 	// Version returns the implementation version for tracking changes
 	Version() string
 }
+
+// Config selects and configures a summarizer provider.
+// Zero values fall back to provider-specific defaults.
+type Config struct {
+	Provider  string
+	Model     string
+	BaseURL   string
+	APIKey    string
+	Timeout   time.Duration
+	MaxTokens int
+}
+
+// New creates a summarizer for the configured provider
+func New(ctx context.Context, cfg Config) (Summarizer, error) {
+	switch cfg.Provider {
+	case geminiProvider:
+		if cfg.APIKey == "" {
+			return nil, fmt.Errorf("gemini provider requires an API key")
+		}
+		g, err := NewGeminiSummarizer(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+	case openAIProvider:
+		return NewOpenAISummarizer(cfg), nil
+	case ollamaProvider:
+		return NewOllamaSummarizer(cfg), nil
+	case LocalProvider:
+		return NewExtractiveSummarizer(), nil
+	default:
+		return nil, fmt.Errorf("unknown summarizer provider: %q", cfg.Provider)
+	}
+}
+
+func (c Config) withDefaults(model, baseURL string, timeout time.Duration, maxTokens int) Config {
+	if c.Model == "" {
+		c.Model = model
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = baseURL
+	}
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	if c.Timeout <= 0 {
+		c.Timeout = timeout
+	}
+	if c.MaxTokens <= 0 {
+		c.MaxTokens = maxTokens
+	}
+	return c
+}
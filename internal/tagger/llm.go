@@ -0,0 +1,64 @@
+package tagger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/drywaters/learnd/internal/summarizer"
+)
+
+// llmConfidence is reported for tags chosen by the LLM from the vocabulary
+const llmConfidence = 0.7
+
+// LLMClassifier asks the configured summarizer provider to pick a tag from the vocabulary
+type LLMClassifier struct {
+	summarizer summarizer.Summarizer
+}
+
+// NewLLMClassifier creates a classifier that sends a classification prompt through sum
+func NewLLMClassifier(sum summarizer.Summarizer) *LLMClassifier {
+	return &LLMClassifier{summarizer: sum}
+}
+
+// Classify returns the tag the model picked, or nil if it answered with anything outside the vocabulary
+func (c *LLMClassifier) Classify(ctx context.Context, input Input, knowledge Knowledge) (*Suggestion, error) {
+	result, err := c.summarizer.Summarize(ctx, summarizer.Input{
+		Title:       input.Title,
+		Description: input.Description,
+		Prompt:      classificationPrompt(input, knowledge.Vocabulary),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify: %w", err)
+	}
+
+	answer := strings.ToLower(strings.Trim(strings.TrimSpace(result.Text), `."'`+"`"))
+	for _, tag := range knowledge.Vocabulary {
+		if answer == tag {
+			return &Suggestion{Tag: tag, Source: SourceLLM, Confidence: llmConfidence}, nil
+		}
+	}
+	return nil, nil
+}
+
+func classificationPrompt(input Input, vocabulary []string) string {
+	var sb strings.Builder
+	sb.WriteString("Choose the single best tag for this item in a learning log from this list: ")
+	sb.WriteString(strings.Join(vocabulary, ", "))
+	sb.WriteString(". Reply with only the tag, or none if no tag fits.\n\n")
+	if input.Domain != "" {
+		fmt.Fprintf(&sb, "Site: %s\n", input.Domain)
+	}
+	if input.Title != "" {
+		fmt.Fprintf(&sb, "Title: %s\n", input.Title)
+	}
+	if input.Description != "" {
+		desc := input.Description
+		if len(desc) > 500 {
+			desc = desc[:500] + "..."
+		}
+		fmt.Fprintf(&sb, "Description: %s\n", desc)
+	}
+	sb.WriteString("\nTag:")
+	return sb.String()
+}
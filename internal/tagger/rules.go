@@ -0,0 +1,63 @@
+package tagger
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+const (
+	// minDomainEntries is how many confirmed entries a domain needs before its tag is trusted
+	minDomainEntries = 2
+	// titleWeight makes a tag named in the title count more than one in the description
+	titleWeight = 2.0
+)
+
+var wordPattern = regexp.MustCompile(`[a-z0-9]+`)
+
+// RuleClassifier suggests tags from the entry's domain history and from
+// vocabulary tags that appear in the title or description.
+type RuleClassifier struct{}
+
+// NewRuleClassifier creates a new RuleClassifier
+func NewRuleClassifier() *RuleClassifier {
+	return &RuleClassifier{}
+}
+
+// Classify scores each vocabulary tag and returns the best one, if any
+func (c *RuleClassifier) Classify(_ context.Context, input Input, knowledge Knowledge) (*Suggestion, error) {
+	scores := make(map[string]float64)
+
+	// Domain rule: most confirmed entries from this domain share a tag
+	if dt, ok := knowledge.DomainTags[input.Domain]; ok && dt.Count >= minDomainEntries && dt.Total > 0 {
+		scores[dt.Tag] += float64(dt.Count) / float64(dt.Total)
+	}
+
+	// Keyword rule: the tag's words appear in the title or description, e.g. "machine-learning"
+	titleWords := " " + strings.Join(wordPattern.FindAllString(strings.ToLower(input.Title), -1), " ") + " "
+	descWords := " " + strings.Join(wordPattern.FindAllString(strings.ToLower(input.Description), -1), " ") + " "
+	for _, tag := range knowledge.Vocabulary {
+		phrase := " " + strings.ReplaceAll(tag, "-", " ") + " "
+		if strings.Contains(titleWords, phrase) {
+			scores[tag] += 0.4 * titleWeight
+		} else if strings.Contains(descWords, phrase) {
+			scores[tag] += 0.4
+		}
+	}
+
+	var best *Suggestion
+	for _, tag := range knowledge.Vocabulary {
+		score, ok := scores[tag]
+		if !ok {
+			continue
+		}
+		if best == nil || score > best.Confidence {
+			best = &Suggestion{Tag: tag, Source: SourceRules, Confidence: score}
+		}
+	}
+	if best != nil && best.Confidence > 1 {
+		best.Confidence = 1
+	}
+
+	return best, nil
+}
@@ -0,0 +1,90 @@
+package tagger
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Sources recorded with a suggested tag
+const (
+	SourceRules = "rules"
+	SourceLLM   = "llm"
+)
+
+// Input contains the entry fields a tag is inferred from
+type Input struct {
+	Domain      string
+	Title       string
+	Description string
+}
+
+// Suggestion is a proposed tag for an entry
+type Suggestion struct {
+	Tag        string
+	Source     string
+	Confidence float64
+}
+
+// Knowledge is what the classifiers learn from already tagged entries
+type Knowledge struct {
+	// Vocabulary lists the existing tags; suggestions are always chosen from it
+	Vocabulary []string
+	// DomainTags maps a domain to the tag most of its confirmed entries use
+	DomainTags map[string]DomainTag
+}
+
+// DomainTag is the dominant confirmed tag for a domain
+type DomainTag struct {
+	Tag   string
+	Count int
+	Total int
+}
+
+// Classifier proposes a tag for an entry, returning nil when it has no suggestion
+type Classifier interface {
+	Classify(ctx context.Context, input Input, knowledge Knowledge) (*Suggestion, error)
+}
+
+// Tagger runs the rule-based classifier first and asks the LLM classifier,
+// if configured, only when the rules have no confident suggestion.
+type Tagger struct {
+	rules     Classifier
+	llm       Classifier
+	threshold float64
+}
+
+// New creates a Tagger; llm may be nil to use rules only
+func New(llm Classifier) *Tagger {
+	return &Tagger{
+		rules:     NewRuleClassifier(),
+		llm:       llm,
+		threshold: 0.6,
+	}
+}
+
+// Suggest returns a tag suggestion, or nil if no classifier is confident enough
+func (t *Tagger) Suggest(ctx context.Context, input Input, knowledge Knowledge) (*Suggestion, error) {
+	if len(knowledge.Vocabulary) == 0 {
+		return nil, nil
+	}
+
+	suggestion, err := t.rules.Classify(ctx, input, knowledge)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion != nil && suggestion.Confidence >= t.threshold {
+		return suggestion, nil
+	}
+
+	if t.llm != nil {
+		llmSuggestion, err := t.llm.Classify(ctx, input, knowledge)
+		if err != nil {
+			// Fall back to a weak rule-based suggestion rather than failing the entry
+			slog.Warn("LLM tag classification failed", "error", err)
+		} else if llmSuggestion != nil {
+			return llmSuggestion, nil
+		}
+	}
+
+	return suggestion, nil
+}
@@ -0,0 +1,171 @@
+package tagger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/drywaters/learnd/internal/summarizer"
+)
+
+var testKnowledge = Knowledge{
+	Vocabulary: []string{"go", "databases", "machine-learning", "rust"},
+	DomainTags: map[string]DomainTag{
+		"go.dev":         {Tag: "go", Count: 5, Total: 5},
+		"news.example":   {Tag: "rust", Count: 2, Total: 8},
+		"once.example":   {Tag: "databases", Count: 1, Total: 1},
+		"postgresql.org": {Tag: "databases", Count: 3, Total: 4},
+	},
+}
+
+func TestRuleClassifier(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          Input
+		wantTag        string
+		wantConfidence float64
+	}{
+		{name: "dominant domain tag", input: Input{Domain: "go.dev", Title: "Release notes"}, wantTag: "go", wantConfidence: 1},
+		{name: "keyword in title", input: Input{Title: "Intro to Machine Learning"}, wantTag: "machine-learning", wantConfidence: 0.8},
+		{name: "keyword in description", input: Input{Title: "Weekly links", Description: "Notes on Rust lifetimes"}, wantTag: "rust", wantConfidence: 0.4},
+		{name: "domain and keyword agree", input: Input{Domain: "postgresql.org", Title: "Databases at scale"}, wantTag: "databases", wantConfidence: 1},
+		{name: "keyword outweighs weak domain", input: Input{Domain: "news.example", Title: "Databases in Go"}, wantTag: "go", wantConfidence: 0.8},
+		{name: "domain with too few entries", input: Input{Domain: "once.example", Title: "Something"}},
+		{name: "partial word does not match", input: Input{Title: "Going places", Description: "Trusty advice"}},
+		{name: "no match", input: Input{Domain: "example.com", Title: "Cooking pasta"}},
+	}
+
+	c := NewRuleClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Classify(context.Background(), tt.input, testKnowledge)
+			if err != nil {
+				t.Fatalf("Classify() error = %v", err)
+			}
+			if tt.wantTag == "" {
+				if got != nil {
+					t.Errorf("Classify() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("Classify() = nil, want %q", tt.wantTag)
+			}
+			if got.Tag != tt.wantTag || got.Source != SourceRules {
+				t.Errorf("Classify() = %+v, want tag %q from rules", got, tt.wantTag)
+			}
+			if diff := got.Confidence - tt.wantConfidence; diff > 0.001 || diff < -0.001 {
+				t.Errorf("Classify() confidence = %v, want %v", got.Confidence, tt.wantConfidence)
+			}
+		})
+	}
+}
+
+type stubClassifier struct {
+	suggestion *Suggestion
+	err        error
+	calls      int
+}
+
+func (s *stubClassifier) Classify(context.Context, Input, Knowledge) (*Suggestion, error) {
+	s.calls++
+	return s.suggestion, s.err
+}
+
+func TestTaggerSuggest(t *testing.T) {
+	llmTag := &Suggestion{Tag: "rust", Source: SourceLLM, Confidence: llmConfidence}
+
+	tests := []struct {
+		name    string
+		input   Input
+		llm     *stubClassifier
+		wantTag string
+		wantLLM bool
+		noVocab bool
+	}{
+		{name: "confident rules skip the LLM", input: Input{Domain: "go.dev"}, llm: &stubClassifier{suggestion: llmTag}, wantTag: "go"},
+		{name: "weak rules ask the LLM", input: Input{Description: "about go"}, llm: &stubClassifier{suggestion: llmTag}, wantTag: "rust", wantLLM: true},
+		{name: "LLM error keeps weak rule suggestion", input: Input{Description: "about go"}, llm: &stubClassifier{err: errors.New("down")}, wantTag: "go", wantLLM: true},
+		{name: "LLM without answer", input: Input{Title: "Cooking"}, llm: &stubClassifier{}, wantLLM: true},
+		{name: "rules only", input: Input{Description: "about go"}, wantTag: "go"},
+		{name: "empty vocabulary", input: Input{Domain: "go.dev"}, llm: &stubClassifier{suggestion: llmTag}, noVocab: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tagger *Tagger
+			if tt.llm != nil {
+				tagger = New(tt.llm)
+			} else {
+				tagger = New(nil)
+			}
+
+			knowledge := testKnowledge
+			if tt.noVocab {
+				knowledge = Knowledge{}
+			}
+
+			got, err := tagger.Suggest(context.Background(), tt.input, knowledge)
+			if err != nil {
+				t.Fatalf("Suggest() error = %v", err)
+			}
+			gotTag := ""
+			if got != nil {
+				gotTag = got.Tag
+			}
+			if gotTag != tt.wantTag {
+				t.Errorf("Suggest() tag = %q, want %q", gotTag, tt.wantTag)
+			}
+			if tt.llm != nil && (tt.llm.calls > 0) != tt.wantLLM {
+				t.Errorf("LLM called = %v, want %v", tt.llm.calls > 0, tt.wantLLM)
+			}
+		})
+	}
+}
+
+type stubSummarizer struct {
+	text   string
+	prompt string
+}
+
+func (s *stubSummarizer) Provider() string { return "stub" }
+func (s *stubSummarizer) Model() string    { return "stub" }
+func (s *stubSummarizer) Version() string  { return "1.0.0" }
+
+func (s *stubSummarizer) Summarize(_ context.Context, input summarizer.Input) (*summarizer.Result, error) {
+	s.prompt = input.Prompt
+	return &summarizer.Result{Text: s.text}, nil
+}
+
+func TestLLMClassifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		wantTag string
+	}{
+		{name: "exact tag", answer: "databases", wantTag: "databases"},
+		{name: "formatted tag", answer: " `Rust`.\n", wantTag: "rust"},
+		{name: "none", answer: "none"},
+		{name: "tag outside vocabulary", answer: "cooking"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum := &stubSummarizer{text: tt.answer}
+			got, err := NewLLMClassifier(sum).Classify(context.Background(), Input{Title: "Indexes"}, testKnowledge)
+			if err != nil {
+				t.Fatalf("Classify() error = %v", err)
+			}
+			if sum.prompt == "" {
+				t.Error("Classify() sent no prompt")
+			}
+			gotTag := ""
+			if got != nil {
+				gotTag = got.Tag
+			}
+			if gotTag != tt.wantTag {
+				t.Errorf("Classify() tag = %q, want %q", gotTag, tt.wantTag)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package tracing sets up OpenTelemetry tracing and the helpers learnd uses to
+// start spans, trace database queries and link background work to the request
+// that queued it.
+//
+// Without an exporter configured the global no-op tracer provider stays in place,
+// so spans cost next to nothing.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ExporterNone keeps tracing off
+	ExporterNone = "none"
+	// ExporterOTLP sends spans over OTLP/HTTP, configured by the standard OTEL_EXPORTER_OTLP_* variables
+	ExporterOTLP = "otlp"
+
+	instrumentationName = "github.com/drywaters/learnd"
+)
+
+// traceContext carries span contexts in the W3C traceparent format
+var traceContext = propagation.TraceContext{}
+
+// Setup installs the tracer provider for exporter and returns a function that
+// flushes buffered spans on shutdown
+func Setup(ctx context.Context, exporter string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(traceContext, propagation.Baggage{}))
+
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+	default:
+		return nil, fmt.Errorf("unknown trace exporter: %q", exporter)
+	}
+
+	exp, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("learnd")),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of any span in ctx
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, opts...)
+}
+
+// urlPattern matches URLs quoted in error messages
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+
+// End records err on span, if there is one, and ends it. URLs in the error's message
+// are cut down to their host, since a failed fetch quotes the full source URL, whose
+// path and query can be private.
+func End(span trace.Span, err error) {
+	if err != nil {
+		msg := redactURLs(err.Error())
+		span.SetAttributes(semconv.ErrorTypeKey.String(fmt.Sprintf("%T", err)))
+		span.RecordError(errors.New(msg))
+		span.SetStatus(codes.Error, msg)
+	}
+	span.End()
+}
+
+// redactURLs replaces each URL in msg with its scheme and host
+func redactURLs(msg string) string {
+	return urlPattern.ReplaceAllStringFunc(msg, func(raw string) string {
+		parsed, err := url.Parse(raw)
+		if err != nil || parsed.Host == "" {
+			return "[url]"
+		}
+		return parsed.Scheme + "://" + parsed.Host
+	})
+}
+
+// TraceParent returns the traceparent header value for the span in ctx, or "" when
+// ctx isn't being traced
+func TraceParent(ctx context.Context) string {
+	carrier := propagation.MapCarrier{}
+	traceContext.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// LinkedTo links a new span to the span a traceparent value was taken from, for
+// work that runs after the request that queued it has finished
+func LinkedTo(traceParent string) trace.SpanStartOption {
+	ctx := traceContext.Extract(context.Background(), propagation.MapCarrier{"traceparent": traceParent})
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return trace.WithLinks()
+	}
+	return trace.WithLinks(trace.Link{SpanContext: sc})
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestLinkedToCaptureRequest(t *testing.T) {
+	recorder := newRecorder(t)
+
+	ctx, capture := Start(context.Background(), "POST /api/entries")
+	traceParent := TraceParent(ctx)
+	capture.End()
+	if traceParent == "" {
+		t.Fatal("expected a traceparent for a traced request")
+	}
+
+	_, job := Start(context.Background(), "worker.enrich", LinkedTo(traceParent))
+	End(job, errors.New("boom"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	captured, worker := spans[0], spans[1]
+	if worker.Parent().IsValid() {
+		t.Error("expected the worker span to start a new trace")
+	}
+	links := worker.Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != captured.SpanContext().SpanID() {
+		t.Errorf("links = %+v, want the capture request's span", links)
+	}
+	if worker.Status().Code != codes.Error {
+		t.Errorf("status = %v, want an error", worker.Status())
+	}
+}
+
+func TestUntracedContexts(t *testing.T) {
+	if got := TraceParent(context.Background()); got != "" {
+		t.Errorf("TraceParent without a span = %q, want empty", got)
+	}
+
+	// Entries captured before tracing, or without it, have no traceparent to link to
+	recorder := newRecorder(t)
+	for _, traceParent := range []string{"", "not-a-traceparent"} {
+		_, span := Start(context.Background(), "worker.enrich", LinkedTo(traceParent))
+		span.End()
+	}
+	for _, span := range recorder.Ended() {
+		if len(span.Links()) != 0 {
+			t.Errorf("links = %+v, want none", span.Links())
+		}
+	}
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), "jaeger"); err == nil {
+		t.Error("expected an error for an unknown exporter")
+	}
+	shutdown, err := Setup(context.Background(), ExporterNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown error = %v", err)
+	}
+}
+
+func TestRedactURLs(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{
+			msg:  `failed to fetch URL: Get "https://user:pw@example.com:8443/a/b?token=x#frag": EOF`,
+			want: `failed to fetch URL: Get "https://example.com:8443": EOF`,
+		},
+		{
+			msg:  "HTTP error: 404 (wayback fallback: Get http://archive.org/wait?url=https://example.com/p: timeout)",
+			want: "HTTP error: 404 (wayback fallback: Get http://archive.org timeout)",
+		},
+		{msg: "no urls here", want: "no urls here"},
+	}
+	for _, tt := range tests {
+		if got := redactURLs(tt.msg); got != tt.want {
+			t.Errorf("redactURLs(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package ui
+
+import "github.com/drywaters/learnd/internal/model"
+
+// AuthEventLabel describes an authentication event, e.g. "Signed in with password"
+func AuthEventLabel(event model.AuthEvent) string {
+	switch event.Kind {
+	case model.AuthEventLoginSucceeded:
+		return "Signed in" + authMethodSuffix(event.Method)
+	case model.AuthEventLoginFailed:
+		return "Failed sign-in" + authMethodSuffix(event.Method)
+	case model.AuthEventTwoFactorFailed:
+		return "Wrong two-factor code"
+	case model.AuthEventTokenRejected:
+		return "Rejected API token"
+	case model.AuthEventLockedOut:
+		if event.Method == "global" {
+			return "Sign-in slowed down for everyone"
+		}
+		return "Sign-in locked for this address"
+	default:
+		return string(event.Kind)
+	}
+}
+
+func authMethodSuffix(method string) string {
+	switch method {
+	case model.AuthMethodPassword:
+		return " with password"
+	case model.AuthMethodLegacyToken:
+		return " with API token"
+	case model.AuthMethodSSO:
+		return " with single sign-on"
+	case model.AuthMethodTwoFactor:
+		return " with two-factor code"
+	default:
+		return ""
+	}
+}
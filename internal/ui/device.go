@@ -0,0 +1,52 @@
+package ui
+
+import "strings"
+
+// browsers are matched in order, since most user agents also claim to be Safari or Mozilla
+var browsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"FxiOS/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+}
+
+var platforms = []struct{ token, name string }{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DeviceLabel summarizes a user agent as "Browser on Platform", e.g. "Firefox on macOS"
+func DeviceLabel(userAgent string) string {
+	browser := matchToken(userAgent, browsers)
+	platform := matchToken(userAgent, platforms)
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	case userAgent != "":
+		return "Unknown browser"
+	default:
+		return "Unknown device"
+	}
+}
+
+func matchToken(userAgent string, candidates []struct{ token, name string }) string {
+	for _, c := range candidates {
+		if strings.Contains(userAgent, c.token) {
+			return c.name
+		}
+	}
+	return ""
+}
@@ -61,3 +61,12 @@ func FormatDate(t time.Time) string {
 	}
 	return t.Format("Jan 2, 2006")
 }
+
+// FormatDateTime formats a time to "Jan 2, 2006 3:04 PM" format
+// Returns an empty string if the time is zero (0001-01-01)
+func FormatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("Jan 2, 2006 3:04 PM")
+}
@@ -54,7 +54,7 @@ func ReportsPage() templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<main class=\"max-w-4xl mx-auto px-4 py-8\"><!-- Page Title --><div class=\"mb-8\"><h1 class=\"font-display text-2xl font-semibold mb-2\" style=\"color: var(--color-ink);\">Reports</h1><p class=\"text-sm\" style=\"color: var(--color-ink-lighter);\">Review your learning activity over time.</p></div><!-- Filters Card --><div class=\"card p-6 mb-8\"><form id=\"report-form\" hx-get=\"/api/reports\" hx-target=\"#report-results\" hx-swap=\"innerHTML\"><div class=\"grid gap-4 sm:grid-cols-2 items-end\"><div class=\"min-w-[220px]\"><label for=\"start\" class=\"block text-sm font-medium mb-2\" style=\"color: var(--color-ink-light);\">Start Date</label> <input type=\"date\" id=\"start\" name=\"start\" class=\"input-field w-full\"></div><div class=\"min-w-[220px]\"><label for=\"end\" class=\"block text-sm font-medium mb-2\" style=\"color: var(--color-ink-light);\">End Date</label> <input type=\"date\" id=\"end\" name=\"end\" class=\"input-field w-full\"></div><button type=\"submit\" class=\"btn-primary w-full px-6 py-3 text-sm inline-flex items-center justify-center whitespace-nowrap\">Generate Report</button> <a id=\"export-link\" href=\"/api/reports/export\" class=\"btn-secondary w-full inline-flex items-center gap-2 justify-center px-4 py-2.5 text-sm whitespace-nowrap\" hx-boost=\"false\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<main class=\"max-w-4xl mx-auto px-4 py-8\"><!-- Page Title --><div class=\"mb-8\"><h1 class=\"font-display text-2xl font-semibold mb-2\" style=\"color: var(--color-ink);\">Reports</h1><p class=\"text-sm\" style=\"color: var(--color-ink-lighter);\">Review your learning activity over time.</p></div><!-- Filters Card --><div class=\"card p-6 mb-8\"><form id=\"report-form\" hx-get=\"/api/reports\" hx-target=\"#report-results\" hx-swap=\"innerHTML\"><input type=\"hidden\" id=\"report-tz\" name=\"tz\"><div class=\"grid gap-4 sm:grid-cols-2 items-end\"><div class=\"min-w-[220px]\"><label for=\"start\" class=\"block text-sm font-medium mb-2\" style=\"color: var(--color-ink-light);\">Start Date</label> <input type=\"date\" id=\"start\" name=\"start\" class=\"input-field w-full\"></div><div class=\"min-w-[220px]\"><label for=\"end\" class=\"block text-sm font-medium mb-2\" style=\"color: var(--color-ink-light);\">End Date</label> <input type=\"date\" id=\"end\" name=\"end\" class=\"input-field w-full\"></div><button type=\"submit\" class=\"btn-primary w-full px-6 py-3 text-sm inline-flex items-center justify-center whitespace-nowrap\">Generate Report</button><div class=\"flex gap-2\"><a id=\"export-link\" href=\"/api/reports/export\" data-export-base=\"/api/reports/export\" class=\"btn-secondary w-full inline-flex items-center gap-2 justify-center px-4 py-2.5 text-sm whitespace-nowrap\" hx-boost=\"false\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -62,19 +62,31 @@ func ReportsPage() templ.Component {
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "CSV</a></div></form></div><!-- Report Results --><div id=\"report-results\"><div class=\"text-center py-12\" style=\"color: var(--color-ink-lighter);\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "CSV</a> <a id=\"export-anki-link\" href=\"/api/export/anki\" data-export-base=\"/api/export/anki\" class=\"btn-secondary w-full inline-flex items-center gap-2 justify-center px-4 py-2.5 text-sm whitespace-nowrap\" hx-boost=\"false\" title=\"Anki-importable notes, one subdeck per tag\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = components.ChartIconLarge().Render(ctx, templ_7745c5c3_Buffer)
+			templ_7745c5c3_Err = components.DownloadIcon().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "Anki</a> <a id=\"export-markdown-link\" href=\"/api/export/markdown\" data-export-base=\"/api/export/markdown\" class=\"btn-secondary w-full inline-flex items-center gap-2 justify-center px-4 py-2.5 text-sm whitespace-nowrap\" hx-boost=\"false\" title=\"Zip of Markdown notes for an Obsidian vault\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<p class=\"text-sm\">Select a date range to generate a report.</p></div></div></main></div>")
+			templ_7745c5c3_Err = components.DownloadIcon().Render(ctx, templ_7745c5c3_Buffer)
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = reportScript().Render(ctx, templ_7745c5c3_Buffer)
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "Markdown</a></div></div></form></div><!-- Report Results --><div id=\"report-results\"><div class=\"text-center py-12\" style=\"color: var(--color-ink-lighter);\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = components.ChartIconLarge().Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<p class=\"text-sm\">Select a date range to generate a report.</p></div></div></main></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -88,33 +100,4 @@ func ReportsPage() templ.Component {
 	})
 }
 
-func reportScript() templ.Component {
-	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
-		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
-		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
-			return templ_7745c5c3_CtxErr
-		}
-		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
-		if !templ_7745c5c3_IsBuffer {
-			defer func() {
-				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
-				if templ_7745c5c3_Err == nil {
-					templ_7745c5c3_Err = templ_7745c5c3_BufErr
-				}
-			}()
-		}
-		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var3 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var3 == nil {
-			templ_7745c5c3_Var3 = templ.NopComponent
-		}
-		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<script>\n\t\t// Update export link when form changes\n\t\tdocument.getElementById('report-form').addEventListener('change', function() {\n\t\t\tconst formData = new FormData(this);\n\t\t\tconst params = new URLSearchParams(formData);\n\t\t\tdocument.getElementById('export-link').href = '/api/reports/export?' + params.toString();\n\t\t});\n\t</script>")
-		if templ_7745c5c3_Err != nil {
-			return templ_7745c5c3_Err
-		}
-		return nil
-	})
-}
-
 var _ = templruntime.GeneratedTemplate
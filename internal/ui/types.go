@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"time"
+
 	"github.com/drywaters/learnd/internal/model"
 )
 
@@ -10,3 +12,39 @@ type EntryView struct {
 	DuplicateCount int
 	SwapOOB        bool
 }
+
+// PromptTemplateView is a source type's summary prompt on the settings page.
+// Version 0 means the built-in default template is in use.
+type PromptTemplateView struct {
+	SourceType    model.SourceType
+	Label         string
+	Template      string
+	Version       int
+	UpdatedAt     *time.Time
+	OutdatedCount int
+}
+
+// ReviewCardView is the next due review card with the interval each rating would schedule.
+// Card is nil when nothing is due.
+type ReviewCardView struct {
+	Card     *model.ReviewCard
+	DueCount int
+	Options  []ReviewOption
+}
+
+// ReviewOption is a rating button on a review card
+type ReviewOption struct {
+	Rating model.ReviewRating
+	Label  string
+}
+
+// TwoFactorView is the state of the security settings page. Secret and QRCode are
+// set while enrolling; NewRecoveryCodes are shown once, right after they're generated.
+type TwoFactorView struct {
+	Enabled           bool
+	Required          bool
+	RecoveryCodesLeft int
+	Secret            string
+	QRCode            string
+	NewRecoveryCodes  []string
+}
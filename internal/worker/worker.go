@@ -5,41 +5,96 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"github.com/drywaters/learnd/internal/enricher"
+	"github.com/drywaters/learnd/internal/metrics"
 	"github.com/drywaters/learnd/internal/model"
 	"github.com/drywaters/learnd/internal/repository"
+	"github.com/drywaters/learnd/internal/review"
 	"github.com/drywaters/learnd/internal/summarizer"
+	"github.com/drywaters/learnd/internal/tagger"
+	"github.com/drywaters/learnd/internal/tracing"
+	"github.com/drywaters/learnd/internal/urlutil"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// maxImportAttempts is how many times an import item is tried before it's marked failed
+const maxImportAttempts = 5
+
 // Worker processes entries in the background
 type Worker struct {
 	entryRepo      *repository.EntryRepository
 	cacheRepo      *repository.SummaryCacheRepository
+	promptRepo     *repository.PromptTemplateRepository
+	reviewRepo     *repository.ReviewRepository
+	importRepo     *repository.ImportJobRepository
 	enrichRegistry *enricher.Registry
 	summarizer     summarizer.Summarizer
+	archiver       Archiver
+	tagger         *tagger.Tagger
+	cards          *review.Generator
 
-	interval  time.Duration
-	batchSize int
+	interval    time.Duration
+	batchSize   int
+	importBatch int
+	cacheTTL    time.Duration
+	structured  bool
+	maxAttempts int
 
 	stopCh chan struct{}
 	wg     sync.WaitGroup
 }
 
+// Archiver submits newly captured URLs to a web archive
+type Archiver interface {
+	Save(ctx context.Context, url string) error
+}
+
 // Config holds worker configuration
 type Config struct {
 	Interval  time.Duration
 	BatchSize int
+
+	// ImportBatch caps how many imported entries may wait for enrichment at once
+	ImportBatch int
+
+	// CacheTTL is how long cached summaries are reused; zero keeps them until purged
+	CacheTTL time.Duration
+
+	// Structured requests takeaways, difficulty and suggested tags along with summaries
+	Structured bool
+
+	// MaxSummaryAttempts is how many times an entry is summarized while the providers
+	// are unavailable before it's marked failed
+	MaxSummaryAttempts int
+
+	// Archiver is optional; when set, URLs are archived after their first successful enrichment
+	Archiver Archiver
+
+	// Tagger is optional; when set, untagged entries get a suggested tag after enrichment
+	Tagger *tagger.Tagger
+
+	// Cards is optional; when set, summarized entries get spaced-repetition review cards
+	Cards *review.Generator
 }
 
 // New creates a new background worker
 func New(
 	entryRepo *repository.EntryRepository,
 	cacheRepo *repository.SummaryCacheRepository,
+	promptRepo *repository.PromptTemplateRepository,
+	reviewRepo *repository.ReviewRepository,
+	importRepo *repository.ImportJobRepository,
 	enrichRegistry *enricher.Registry,
 	sum summarizer.Summarizer,
 	cfg Config,
@@ -50,14 +105,30 @@ func New(
 	if cfg.BatchSize == 0 {
 		cfg.BatchSize = 5
 	}
+	if cfg.ImportBatch == 0 {
+		cfg.ImportBatch = 20
+	}
+	if cfg.MaxSummaryAttempts == 0 {
+		cfg.MaxSummaryAttempts = 5
+	}
 
 	return &Worker{
 		entryRepo:      entryRepo,
 		cacheRepo:      cacheRepo,
+		promptRepo:     promptRepo,
+		reviewRepo:     reviewRepo,
+		importRepo:     importRepo,
 		enrichRegistry: enrichRegistry,
 		summarizer:     sum,
+		archiver:       cfg.Archiver,
+		tagger:         cfg.Tagger,
+		cards:          cfg.Cards,
 		interval:       cfg.Interval,
 		batchSize:      cfg.BatchSize,
+		importBatch:    cfg.ImportBatch,
+		cacheTTL:       cfg.CacheTTL,
+		structured:     cfg.Structured,
+		maxAttempts:    cfg.MaxSummaryAttempts,
 		stopCh:         make(chan struct{}),
 	}
 }
@@ -66,9 +137,26 @@ func New(
 func (w *Worker) Start(ctx context.Context) {
 	slog.Info("starting background worker", "interval", w.interval, "batch_size", w.batchSize)
 
+	w.upgradeLocalSummaries(ctx)
+
 	w.wg.Add(2)
 	go w.runEnrichmentLoop(ctx)
 	go w.runSummarizationLoop(ctx)
+
+	if w.tagger != nil {
+		w.wg.Add(1)
+		go w.runAutoTagLoop(ctx)
+	}
+
+	if w.cards != nil && w.reviewRepo != nil {
+		w.wg.Add(1)
+		go w.runReviewCardLoop(ctx)
+	}
+
+	if w.importRepo != nil {
+		w.wg.Add(1)
+		go w.runImportLoop(ctx)
+	}
 }
 
 // Stop gracefully stops the worker
@@ -79,6 +167,22 @@ func (w *Worker) Stop() {
 	slog.Info("background worker stopped")
 }
 
+// upgradeLocalSummaries requeues offline summaries once an LLM summarizer is configured
+func (w *Worker) upgradeLocalSummaries(ctx context.Context) {
+	if w.summarizer == nil || w.summarizer.Provider() == summarizer.LocalProvider {
+		return
+	}
+
+	count, err := w.entryRepo.RequeueSummariesByProvider(ctx, summarizer.LocalProvider)
+	if err != nil {
+		slog.Error("failed to requeue local summaries", "error", err)
+		return
+	}
+	if count > 0 {
+		slog.Info("requeued local summaries for upgrade", "count", count, "provider", w.summarizer.Provider())
+	}
+}
+
 func (w *Worker) runEnrichmentLoop(ctx context.Context) {
 	defer w.wg.Done()
 
@@ -115,6 +219,60 @@ func (w *Worker) runSummarizationLoop(ctx context.Context) {
 	}
 }
 
+func (w *Worker) runAutoTagLoop(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.processAutoTag(ctx)
+		}
+	}
+}
+
+func (w *Worker) runReviewCardLoop(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.processReviewCards(ctx)
+		}
+	}
+}
+
+func (w *Worker) runImportLoop(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.processImports(ctx)
+		}
+	}
+}
+
 func (w *Worker) processEnrichment(ctx context.Context) {
 	entries, err := w.entryRepo.GetPendingEnrichment(ctx, w.batchSize)
 	if err != nil {
@@ -123,49 +281,84 @@ func (w *Worker) processEnrichment(ctx context.Context) {
 	}
 
 	for _, entry := range entries {
-		// Mark as processing
-		if err := w.entryRepo.UpdateEnrichmentStatus(ctx, entry.ID, model.StatusProcessing, nil); err != nil {
-			slog.Error("failed to update enrichment status", "id", entry.ID, "error", err)
-			continue
-		}
+		entryCtx, span := startEntrySpan(ctx, "worker.enrich", &entry)
+		err := w.enrichEntry(entryCtx, &entry)
+		tracing.End(span, err)
+	}
+}
+
+// enrichEntry enriches one entry, returning the error it failed with, if any
+func (w *Worker) enrichEntry(ctx context.Context, entry *model.Entry) error {
+	// Mark as processing
+	if err := w.entryRepo.UpdateEnrichmentStatus(ctx, entry.ID, model.StatusProcessing, nil); err != nil {
+		slog.Error("failed to update enrichment status", "id", entry.ID, "error", err)
+		return err
+	}
+
+	// Enrich
+	result, err := w.enrichRegistry.Enrich(ctx, entry.SourceURL)
+	if err != nil {
+		errMsg := err.Error()
+		slog.Warn("enrichment failed", "id", entry.ID, "url", entry.SourceURL, "error", err)
+		w.entryRepo.UpdateEnrichmentStatus(ctx, entry.ID, model.StatusFailed, &errMsg)
+		return err
+	}
 
-		// Enrich
-		result, err := w.enrichRegistry.Enrich(ctx, entry.SourceURL)
+	var metadataJSON []byte
+	if len(result.Metadata) > 0 {
+		metadataJSON, err = json.Marshal(result.Metadata)
 		if err != nil {
-			errMsg := err.Error()
-			slog.Warn("enrichment failed", "id", entry.ID, "url", entry.SourceURL, "error", err)
-			w.entryRepo.UpdateEnrichmentStatus(ctx, entry.ID, model.StatusFailed, &errMsg)
-			continue
+			slog.Warn("failed to marshal enrichment metadata", "id", entry.ID, "error", err)
+			metadataJSON = nil
 		}
+	}
 
-		var metadataJSON []byte
-		if len(result.Metadata) > 0 {
-			metadataJSON, err = json.Marshal(result.Metadata)
-			if err != nil {
-				slog.Warn("failed to marshal enrichment metadata", "id", entry.ID, "error", err)
-				metadataJSON = nil
-			}
-		}
+	// Save enrichment result (sanitize text fields to remove invalid UTF-8)
+	enrichResult := &repository.EnrichmentResult{
+		CanonicalURL:   result.CanonicalURL,
+		Domain:         result.Domain,
+		SourceType:     result.SourceType,
+		Title:          sanitizeUTF8(result.Title),
+		Description:    sanitizeUTF8(result.Description),
+		PublishedAt:    result.PublishedAt,
+		RuntimeSeconds: result.RuntimeSeconds,
+		MetadataJSON:   metadataJSON,
+	}
 
-		// Save enrichment result (sanitize text fields to remove invalid UTF-8)
-		enrichResult := &repository.EnrichmentResult{
-			CanonicalURL:   result.CanonicalURL,
-			Domain:         result.Domain,
-			SourceType:     result.SourceType,
-			Title:          sanitizeUTF8(result.Title),
-			Description:    sanitizeUTF8(result.Description),
-			PublishedAt:    result.PublishedAt,
-			RuntimeSeconds: result.RuntimeSeconds,
-			MetadataJSON:   metadataJSON,
-		}
+	if err := w.entryRepo.UpdateEnrichmentResult(ctx, entry.ID, enrichResult); err != nil {
+		slog.Error("failed to save enrichment result", "id", entry.ID, "error", err)
+		return err
+	}
 
-		if err := w.entryRepo.UpdateEnrichmentResult(ctx, entry.ID, enrichResult); err != nil {
-			slog.Error("failed to save enrichment result", "id", entry.ID, "error", err)
-			continue
-		}
+	slog.Info("enriched entry", "id", entry.ID, "title", result.Title, "type", result.SourceType)
 
-		slog.Info("enriched entry", "id", entry.ID, "title", result.Title, "type", result.SourceType)
+	// Archive new captures while the page is still live
+	_, fromArchive := result.Metadata["archived_from"]
+	if w.archiver != nil && entry.CanonicalURL == nil && !fromArchive {
+		w.archive(ctx, entry.ID, entry.SourceURL)
 	}
+	return nil
+}
+
+// startEntrySpan starts the span for processing an entry, linked to the request that
+// captured it since that request finished long ago
+func startEntrySpan(ctx context.Context, name string, entry *model.Entry) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		tracing.LinkedTo(entry.TraceParent),
+		trace.WithAttributes(attribute.String("entry.id", entry.ID.String())),
+	)
+}
+
+func (w *Worker) archive(ctx context.Context, id uuid.UUID, url string) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.archiver.Save(ctx, url); err != nil {
+			slog.Warn("failed to archive url", "id", id, "url", url, "error", err)
+			return
+		}
+		slog.Info("archived url", "id", id, "url", url)
+	}()
 }
 
 func (w *Worker) processSummarization(ctx context.Context) {
@@ -179,22 +372,81 @@ func (w *Worker) processSummarization(ctx context.Context) {
 		return
 	}
 
+	prompts := w.loadPromptTemplates(ctx)
+
+	// Each user's tags are their own, so the vocabulary is loaded per owner
+	vocabularies := make(map[uuid.UUID][]string)
+
 	for _, entry := range entries {
-		// Skip if no content to summarize
-		if entry.Title == nil && entry.Description == nil {
-			w.entryRepo.UpdateSummaryStatus(ctx, entry.ID, model.StatusSkipped, nil)
-			continue
+		entryCtx, span := startEntrySpan(ctx, "worker.summarize", &entry)
+		more, err := w.summarizeEntry(entryCtx, &entry, prompts, vocabularies)
+		tracing.End(span, err)
+		if !more {
+			return
+		}
+	}
+}
+
+// summarizeEntry summarizes one entry, using the cache when it can. It reports whether
+// the batch should go on, which it shouldn't while the provider is unavailable, and
+// the error the entry failed with, if any.
+func (w *Worker) summarizeEntry(ctx context.Context, entry *model.Entry, prompts map[model.SourceType]promptTemplate, vocabularies map[uuid.UUID][]string) (bool, error) {
+	// Skip if no content to summarize
+	if entry.Title == nil && entry.Description == nil {
+		w.entryRepo.UpdateSummaryStatus(ctx, entry.ID, model.StatusSkipped, nil)
+		return true, nil
+	}
+
+	// Build input, using the custom prompt template for the source type if there is one
+	input := summarizer.InputFromEntry(entry)
+	input.Structured = w.structured
+	if w.structured {
+		vocabulary, ok := vocabularies[entry.UserID]
+		if !ok {
+			var err error
+			if vocabulary, err = w.entryRepo.ListTags(ctx, entry.UserID); err != nil {
+				slog.Warn("failed to load tag vocabulary", "user_id", entry.UserID, "error", err)
+			}
+			vocabularies[entry.UserID] = vocabulary
 		}
+		input.TagVocabulary = vocabulary
+	}
+	if tmpl, ok := prompts[entry.SourceType]; ok {
+		prompt, err := summarizer.RenderPrompt(tmpl.parsed, input)
+		if err != nil {
+			slog.Warn("failed to render prompt template, using default", "id", entry.ID, "type", entry.SourceType, "error", err)
+		} else {
+			input.Prompt = prompt
+			input.PromptVersion = tmpl.version
+		}
+	}
 
-		// Check cache first
-		canonicalURL := entry.SourceURL
-		if entry.CanonicalURL != nil {
-			canonicalURL = *entry.CanonicalURL
+	canonicalURL := entry.SourceURL
+	if entry.CanonicalURL != nil {
+		canonicalURL = *entry.CanonicalURL
+	}
+	urlHash := hashURL(canonicalURL)
+	contentHash := hashContent(input)
+
+	// Check cache first. The key covers the model and prompt version of the configured
+	// primary provider, which is what a summary it produces is stored under below, so a
+	// summary from a fallback, another provider or an old prompt isn't reused.
+	if entry.SummaryBypassCache {
+		metrics.SummaryCacheLookups.WithLabelValues("bypass").Inc()
+		if err := w.cacheRepo.RecordBypass(ctx); err != nil {
+			slog.Warn("failed to record cache bypass", "id", entry.ID, "error", err)
 		}
-		urlHash := hashURL(canonicalURL)
+	} else if w.summarizer.Provider() != summarizer.LocalProvider {
+		key := cacheKey(urlHash, contentHash, w.summarizer.Provider(), w.summarizer.Model(),
+			summarizer.VersionWithPrompt(w.summarizer.Version(), input.PromptVersion))
 
-		cached, err := w.cacheRepo.GetByURLHash(ctx, urlHash)
-		if err == nil && cached != nil {
+		cached, err := w.cacheRepo.Lookup(ctx, key)
+		if err != nil {
+			slog.Warn("failed to look up summary cache", "id", entry.ID, "error", err)
+		} else if cached == nil {
+			metrics.SummaryCacheLookups.WithLabelValues("miss").Inc()
+		} else {
+			metrics.SummaryCacheLookups.WithLabelValues("hit").Inc()
 			// Use cached summary
 			result := &repository.SummaryResult{
 				Text:        cached.SummaryText,
@@ -202,29 +454,120 @@ func (w *Worker) processSummarization(ctx context.Context) {
 				Model:       cached.Model,
 				Version:     cached.Version,
 				GeneratedAt: cached.CreatedAt,
+				Insights:    cached.Insights,
 			}
 			if err := w.entryRepo.UpdateSummaryResult(ctx, entry.ID, result); err != nil {
 				slog.Error("failed to save cached summary", "id", entry.ID, "error", err)
 			}
 			slog.Info("used cached summary", "id", entry.ID)
-			continue
+			return true, nil
 		}
+	}
 
-		// Mark as processing
-		if err := w.entryRepo.UpdateSummaryStatus(ctx, entry.ID, model.StatusProcessing, nil); err != nil {
-			slog.Error("failed to update summary status", "id", entry.ID, "error", err)
-			continue
+	// Mark as processing
+	if err := w.entryRepo.UpdateSummaryStatus(ctx, entry.ID, model.StatusProcessing, nil); err != nil {
+		slog.Error("failed to update summary status", "id", entry.ID, "error", err)
+		return true, err
+	}
+
+	// Generate summary
+	result, err := w.summarizer.Summarize(ctx, input)
+	if err != nil {
+		errMsg := err.Error()
+		if errors.Is(err, summarizer.ErrCircuitOpen) {
+			// No provider was tried, so this doesn't count as an attempt
+			slog.Warn("summarization unavailable, retrying later", "id", entry.ID, "error", err)
+			w.entryRepo.UpdateSummaryStatus(ctx, entry.ID, model.StatusPending, &errMsg)
+			return false, err
 		}
+		if summarizer.IsRetryable(err) {
+			// Leave the entry pending and stop for this batch rather than hammering the
+			// provider, until it has used up its attempts
+			failed, deferErr := w.entryRepo.DeferSummary(ctx, entry.ID, errMsg, w.maxAttempts)
+			if deferErr != nil {
+				slog.Error("failed to defer summary", "id", entry.ID, "error", deferErr)
+			} else if failed {
+				slog.Warn("summarization failed after retries", "id", entry.ID, "attempts", w.maxAttempts, "error", err)
+			} else {
+				slog.Warn("summarization unavailable, retrying later", "id", entry.ID, "error", err)
+			}
+			return false, err
+		}
+		slog.Warn("summarization failed", "id", entry.ID, "error", err)
+		w.entryRepo.UpdateSummaryStatus(ctx, entry.ID, model.StatusFailed, &errMsg)
+		return true, err
+	}
+
+	// Save to entry
+	summaryResult := &repository.SummaryResult{
+		Text:        result.Text,
+		Provider:    result.Provider,
+		Model:       result.Model,
+		Version:     result.Version,
+		GeneratedAt: result.GeneratedAt,
+		Insights:    result.Insights,
+	}
+
+	if err := w.entryRepo.UpdateSummaryResult(ctx, entry.ID, summaryResult); err != nil {
+		slog.Error("failed to save summary result", "id", entry.ID, "error", err)
+		return true, err
+	}
+
+	slog.Info("summarized entry", "id", entry.ID, "provider", result.Provider)
+
+	// Offline summaries are cheap to recompute and shouldn't shadow LLM summaries in the cache
+	if result.Provider == summarizer.LocalProvider {
+		return true, nil
+	}
+
+	// Cache the summary
+	cache := &model.SummaryCache{
+		CacheKey:     cacheKey(urlHash, contentHash, result.Provider, result.Model, result.Version),
+		URLHash:      urlHash,
+		ContentHash:  contentHash,
+		CanonicalURL: canonicalURL,
+		SummaryText:  result.Text,
+		Provider:     result.Provider,
+		Model:        result.Model,
+		Version:      result.Version,
+		Insights:     result.Insights,
+	}
+	if w.cacheTTL > 0 {
+		expiresAt := time.Now().Add(w.cacheTTL)
+		cache.ExpiresAt = &expiresAt
+	}
+	if err := w.cacheRepo.Store(ctx, cache); err != nil {
+		slog.Warn("failed to cache summary", "id", entry.ID, "error", err)
+	}
+	return true, nil
+}
+
+func (w *Worker) processAutoTag(ctx context.Context) {
+	entries, err := w.entryRepo.GetPendingAutoTag(ctx, w.batchSize)
+	if err != nil {
+		slog.Error("failed to get pending auto-tag", "error", err)
+		return
+	}
+	if len(entries) == 0 {
+		return
+	}
+
+	// Classifiers learn from the confirmed tags of the entry's owner only
+	knowledgeByUser := make(map[uuid.UUID]tagger.Knowledge)
 
-		// Build input
-		tag := ""
-		if entry.Tag != nil {
-			tag = *entry.Tag
+	for _, entry := range entries {
+		knowledge, ok := knowledgeByUser[entry.UserID]
+		if !ok {
+			if knowledge, err = w.loadTagKnowledge(ctx, entry.UserID); err != nil {
+				slog.Error("failed to load tag knowledge", "user_id", entry.UserID, "error", err)
+				return
+			}
+			knowledgeByUser[entry.UserID] = knowledge
 		}
-		input := summarizer.Input{
-			SourceType: entry.SourceType,
-			URL:        entry.SourceURL,
-			Tag:        tag,
+
+		input := tagger.Input{}
+		if entry.Domain != nil {
+			input.Domain = *entry.Domain
 		}
 		if entry.Title != nil {
 			input.Title = *entry.Title
@@ -233,44 +576,194 @@ func (w *Worker) processSummarization(ctx context.Context) {
 			input.Description = *entry.Description
 		}
 
-		// Generate summary
-		result, err := w.summarizer.Summarize(ctx, input)
+		suggestion, err := w.tagger.Suggest(ctx, input, knowledge)
 		if err != nil {
-			errMsg := err.Error()
-			slog.Warn("summarization failed", "id", entry.ID, "error", err)
-			w.entryRepo.UpdateSummaryStatus(ctx, entry.ID, model.StatusFailed, &errMsg)
+			// Leave the entry unchecked so it is retried on the next tick
+			slog.Warn("tag classification failed", "id", entry.ID, "error", err)
 			continue
 		}
 
-		// Save to entry
-		summaryResult := &repository.SummaryResult{
-			Text:        result.Text,
-			Provider:    result.Provider,
-			Model:       result.Model,
-			Version:     result.Version,
-			GeneratedAt: result.GeneratedAt,
+		var tag *string
+		var source string
+		if suggestion != nil {
+			tag = &suggestion.Tag
+			source = suggestion.Source
+		}
+		if err := w.entryRepo.SaveTagSuggestion(ctx, entry.ID, tag, source); err != nil {
+			slog.Error("failed to save tag suggestion", "id", entry.ID, "error", err)
+			continue
 		}
 
-		if err := w.entryRepo.UpdateSummaryResult(ctx, entry.ID, summaryResult); err != nil {
-			slog.Error("failed to save summary result", "id", entry.ID, "error", err)
+		if suggestion != nil {
+			slog.Info("suggested tag", "id", entry.ID, "tag", suggestion.Tag, "source", suggestion.Source, "confidence", suggestion.Confidence)
+		}
+	}
+}
+
+func (w *Worker) processReviewCards(ctx context.Context) {
+	entries, err := w.reviewRepo.GetEntriesNeedingCards(ctx, w.batchSize)
+	if err != nil {
+		slog.Error("failed to get entries needing review cards", "error", err)
+		return
+	}
+
+	for _, entry := range entries {
+		cards, err := w.cards.Generate(ctx, &entry)
+		if err != nil {
+			// Stop for this batch rather than hammering an unavailable provider
+			slog.Warn("review card generation unavailable, retrying later", "id", entry.ID, "error", err)
+			return
+		}
+		if len(cards) == 0 {
 			continue
 		}
 
-		// Cache the summary
-		cache := &model.SummaryCache{
-			URLHash:      urlHash,
-			CanonicalURL: canonicalURL,
-			SummaryText:  result.Text,
-			Provider:     result.Provider,
-			Model:        result.Model,
-			Version:      result.Version,
+		if err := w.reviewRepo.SaveCards(ctx, &entry, cards); err != nil {
+			slog.Error("failed to save review cards", "id", entry.ID, "error", err)
+			continue
+		}
+		slog.Info("saved review cards", "id", entry.ID, "count", len(cards))
+	}
+}
+
+// processImports turns pending import items into entries. Items are released only as the
+// enrichment queue drains, so a large import doesn't flood the enrichers or delay new captures.
+func (w *Worker) processImports(ctx context.Context) {
+	pending, err := w.entryRepo.CountPendingEnrichment(ctx)
+	if err != nil {
+		slog.Error("failed to count pending enrichment", "error", err)
+		return
+	}
+
+	limit := w.importBatch - pending
+	if limit <= 0 {
+		return
+	}
+
+	items, err := w.importRepo.GetPendingItems(ctx, limit)
+	if err != nil {
+		slog.Error("failed to get pending import items", "error", err)
+		return
+	}
+
+	for _, item := range items {
+		status, entryID, errMsg := w.importItem(ctx, &item)
+		if status == model.ImportItemPending {
+			// Database error; retry the item on the next tick until it runs out of attempts
+			failed, err := w.importRepo.RetryItem(ctx, item.ID, *errMsg, maxImportAttempts)
+			if err != nil {
+				slog.Error("failed to retry import item", "id", item.ID, "error", err)
+			} else if failed {
+				slog.Warn("import item failed after retries", "id", item.ID, "attempts", maxImportAttempts, "error", *errMsg)
+			}
+			break
 		}
-		if err := w.cacheRepo.Store(ctx, cache); err != nil {
-			slog.Warn("failed to cache summary", "id", entry.ID, "error", err)
+		if err := w.importRepo.MarkItem(ctx, item.ID, status, entryID, errMsg); err != nil {
+			slog.Error("failed to mark import item", "id", item.ID, "error", err)
+			return
 		}
+	}
+
+	finished, err := w.importRepo.FinishCompletedJobs(ctx)
+	if err != nil {
+		slog.Error("failed to finish import jobs", "error", err)
+		return
+	}
+	if finished > 0 {
+		slog.Info("finished import jobs", "count", finished)
+	}
+}
+
+// importItem creates an entry for an import item unless its URL is invalid or already captured.
+// Returns ImportItemPending, with the error, when the item should be retried.
+func (w *Worker) importItem(ctx context.Context, item *model.ImportItem) (model.ImportItemStatus, *uuid.UUID, *string) {
+	normalizedURL, err := urlutil.NormalizeURL(item.URL)
+	if err == nil && !strings.HasPrefix(normalizedURL, "http://") && !strings.HasPrefix(normalizedURL, "https://") {
+		err = fmt.Errorf("unsupported url scheme")
+	}
+	if err != nil {
+		errMsg := err.Error()
+		return model.ImportItemInvalid, nil, &errMsg
+	}
+
+	count, err := w.entryRepo.CountByNormalizedURL(ctx, item.UserID, normalizedURL)
+	if err != nil {
+		slog.Error("failed to check import duplicate", "id", item.ID, "error", err)
+		errMsg := err.Error()
+		return model.ImportItemPending, nil, &errMsg
+	}
+	if count > 0 {
+		return model.ImportItemDuplicate, nil, nil
+	}
+
+	entry, err := w.entryRepo.Create(ctx, &model.CreateEntryInput{
+		UserID:        item.UserID,
+		SourceURL:     strings.TrimSpace(item.URL),
+		NormalizedURL: normalizedURL,
+		Tag:           item.Tag,
+		Notes:         item.Notes,
+		Title:         item.Title,
+		CreatedAt:     item.SavedAt,
+	})
+	if err != nil {
+		slog.Error("failed to create imported entry", "id", item.ID, "error", err)
+		errMsg := err.Error()
+		return model.ImportItemPending, nil, &errMsg
+	}
+	return model.ImportItemImported, &entry.ID, nil
+}
+
+// loadTagKnowledge gathers a user's confirmed tags the classifiers learn from
+func (w *Worker) loadTagKnowledge(ctx context.Context, userID uuid.UUID) (tagger.Knowledge, error) {
+	vocabulary, err := w.entryRepo.ListTags(ctx, userID)
+	if err != nil {
+		return tagger.Knowledge{}, err
+	}
 
-		slog.Info("summarized entry", "id", entry.ID)
+	domainTags, err := w.entryRepo.TagsByDomain(ctx, userID)
+	if err != nil {
+		return tagger.Knowledge{}, err
+	}
+
+	knowledge := tagger.Knowledge{
+		Vocabulary: vocabulary,
+		DomainTags: make(map[string]tagger.DomainTag, len(domainTags)),
+	}
+	for _, dt := range domainTags {
+		knowledge.DomainTags[dt.Domain] = tagger.DomainTag{Tag: dt.Tag, Count: dt.Count, Total: dt.Total}
 	}
+	return knowledge, nil
+}
+
+// promptTemplate is a parsed custom prompt and its version
+type promptTemplate struct {
+	parsed  *template.Template
+	version int
+}
+
+// loadPromptTemplates parses the saved prompt templates for a batch.
+// Source types without a valid template use the built-in default prompt.
+func (w *Worker) loadPromptTemplates(ctx context.Context) map[model.SourceType]promptTemplate {
+	prompts := make(map[model.SourceType]promptTemplate)
+	if w.promptRepo == nil {
+		return prompts
+	}
+
+	saved, err := w.promptRepo.List(ctx)
+	if err != nil {
+		slog.Error("failed to load prompt templates", "error", err)
+		return prompts
+	}
+
+	for sourceType, t := range saved {
+		parsed, err := summarizer.ParsePromptTemplate(t.Template)
+		if err != nil {
+			slog.Warn("invalid prompt template, using default", "type", sourceType, "version", t.Version, "error", err)
+			continue
+		}
+		prompts[sourceType] = promptTemplate{parsed: parsed, version: t.Version}
+	}
+	return prompts
 }
 
 func hashURL(url string) string {
@@ -279,6 +772,38 @@ func hashURL(url string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// hashContent hashes the entry fields and output mode a summary is generated from,
+// so edits invalidate the cache. Structured summaries suggest tags from the owner's
+// vocabulary, so it's included too.
+func hashContent(input summarizer.Input) string {
+	h := sha256.New()
+	for _, field := range []string{string(input.SourceType), input.Title, input.Description, input.Tag} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	if input.Structured {
+		h.Write([]byte("structured"))
+		h.Write([]byte{0})
+		vocabulary := slices.Clone(input.TagVocabulary)
+		slices.Sort(vocabulary)
+		for _, tag := range vocabulary {
+			h.Write([]byte(tag))
+			h.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// cacheKey identifies a summary of specific content by a specific provider, model and version
+func cacheKey(urlHash, contentHash, provider, model, version string) string {
+	h := sha256.New()
+	for _, part := range []string{urlHash, contentHash, provider, model, version} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // sanitizeUTF8 removes invalid UTF-8 byte sequences from a string.
 // This prevents PostgreSQL errors when storing text that may contain
 // malformed characters from web scraping.
@@ -1,6 +1,11 @@
 package worker
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/drywaters/learnd/internal/model"
+	"github.com/drywaters/learnd/internal/summarizer"
+)
 
 func TestSanitizeUTF8(t *testing.T) {
 	tests := []struct {
@@ -59,3 +64,50 @@ func TestSanitizeUTF8(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheKey(t *testing.T) {
+	input := summarizer.Input{Title: "Go generics", Description: "An intro", SourceType: model.SourceTypeArticle}
+	urlHash := hashURL("https://example.com/generics")
+	base := cacheKey(urlHash, hashContent(input), "openai", "gpt-4o-mini", "1.0.0")
+
+	if again := cacheKey(urlHash, hashContent(input), "openai", "gpt-4o-mini", "1.0.0"); again != base {
+		t.Errorf("cacheKey() is not stable: %q != %q", again, base)
+	}
+
+	edited := input
+	edited.Description = "An updated intro"
+	variants := map[string]string{
+		"content":        cacheKey(urlHash, hashContent(edited), "openai", "gpt-4o-mini", "1.0.0"),
+		"provider":       cacheKey(urlHash, hashContent(input), "ollama", "gpt-4o-mini", "1.0.0"),
+		"model":          cacheKey(urlHash, hashContent(input), "openai", "gpt-4o", "1.0.0"),
+		"prompt version": cacheKey(urlHash, hashContent(input), "openai", "gpt-4o-mini", "1.0.0+prompt.2"),
+		"url":            cacheKey(hashURL("https://example.com/other"), hashContent(input), "openai", "gpt-4o-mini", "1.0.0"),
+	}
+	for name, key := range variants {
+		if key == base {
+			t.Errorf("cacheKey() unchanged when %s differs", name)
+		}
+	}
+
+	// Structured summaries suggest tags from the vocabulary, in whatever order it's listed
+	structured := input
+	structured.Structured = true
+	structured.TagVocabulary = []string{"go", "rust"}
+	reordered := structured
+	reordered.TagVocabulary = []string{"rust", "go"}
+	other := structured
+	other.TagVocabulary = []string{"go", "python"}
+	if hashContent(structured) != hashContent(reordered) {
+		t.Error("hashContent() depends on the vocabulary order")
+	}
+	if hashContent(structured) == hashContent(other) || hashContent(structured) == hashContent(input) {
+		t.Error("hashContent() ignores the tag vocabulary in structured mode")
+	}
+
+	// Field boundaries are delimited so moving text between fields changes the hash
+	a := hashContent(summarizer.Input{Title: "ab", Description: "c"})
+	b := hashContent(summarizer.Input{Title: "a", Description: "bc"})
+	if a == b {
+		t.Error("hashContent() ignores field boundaries")
+	}
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE prompt_templates (
+    source_type TEXT PRIMARY KEY,
+    template    TEXT NOT NULL,
+    version     INT NOT NULL DEFAULT 1,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- +goose Down
+DROP TABLE prompt_templates;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- Existing rows are keyed by URL only and can't be matched under the new key.
+-- Entries keep their summaries, so clearing the cache loses nothing but future hits.
+DELETE FROM summary_cache;
+ALTER TABLE summary_cache DROP CONSTRAINT summary_cache_url_hash_key;
+ALTER TABLE summary_cache
+    ADD COLUMN cache_key    TEXT NOT NULL UNIQUE,
+    ADD COLUMN content_hash TEXT NOT NULL,
+    ADD COLUMN expires_at   TIMESTAMPTZ,
+    ADD COLUMN hit_count    INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_hit_at  TIMESTAMPTZ;
+CREATE INDEX idx_summary_cache_expires_at ON summary_cache(expires_at);
+
+CREATE TABLE summary_cache_stats (
+    id       BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    hits     BIGINT NOT NULL DEFAULT 0,
+    misses   BIGINT NOT NULL DEFAULT 0,
+    bypasses BIGINT NOT NULL DEFAULT 0,
+    reset_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+INSERT INTO summary_cache_stats DEFAULT VALUES;
+
+ALTER TABLE entries ADD COLUMN summary_bypass_cache BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE entries DROP COLUMN summary_bypass_cache;
+DROP TABLE summary_cache_stats;
+DELETE FROM summary_cache;
+DROP INDEX idx_summary_cache_expires_at;
+ALTER TABLE summary_cache
+    DROP COLUMN cache_key,
+    DROP COLUMN content_hash,
+    DROP COLUMN expires_at,
+    DROP COLUMN hit_count,
+    DROP COLUMN last_hit_at;
+ALTER TABLE summary_cache ADD CONSTRAINT summary_cache_url_hash_key UNIQUE (url_hash);
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE entries ADD COLUMN summary_insights JSONB;
+ALTER TABLE summary_cache ADD COLUMN insights JSONB;
+
+-- +goose Down
+ALTER TABLE summary_cache DROP COLUMN insights;
+ALTER TABLE entries DROP COLUMN summary_insights;
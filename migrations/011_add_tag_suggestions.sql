@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE entries ADD COLUMN tag_status TEXT NOT NULL DEFAULT 'confirmed'
+    CHECK (tag_status IN ('suggested', 'confirmed'));
+ALTER TABLE entries ADD COLUMN tag_source TEXT;
+ALTER TABLE entries ADD COLUMN tag_checked_at TIMESTAMPTZ;
+
+CREATE INDEX idx_entries_tag_status ON entries(tag_status) WHERE tag_status = 'suggested';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_entries_tag_status;
+ALTER TABLE entries DROP COLUMN tag_checked_at;
+ALTER TABLE entries DROP COLUMN tag_source;
+ALTER TABLE entries DROP COLUMN tag_status;
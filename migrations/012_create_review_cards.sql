@@ -0,0 +1,39 @@
+-- +goose Up
+CREATE TABLE review_cards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entry_id UUID NOT NULL REFERENCES entries(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('entry', 'qa')),
+    front TEXT NOT NULL,
+    back TEXT NOT NULL,
+
+    -- SM-2 schedule
+    ease DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    interval_days INT NOT NULL DEFAULT 0,
+    repetitions INT NOT NULL DEFAULT 0,
+    lapses INT NOT NULL DEFAULT 0,
+    due_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_reviewed_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (entry_id, kind, front)
+);
+
+CREATE INDEX idx_review_cards_due_at ON review_cards(due_at);
+
+CREATE TABLE review_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    card_id UUID NOT NULL REFERENCES review_cards(id) ON DELETE CASCADE,
+    rating TEXT NOT NULL CHECK (rating IN ('again', 'hard', 'good', 'easy')),
+    reviewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    previous_interval_days INT NOT NULL,
+    interval_days INT NOT NULL,
+    ease DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX idx_review_logs_reviewed_at ON review_logs(reviewed_at DESC);
+
+-- +goose Down
+DROP TABLE review_logs;
+DROP TABLE review_cards;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    format TEXT NOT NULL,
+    filename TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'done')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_import_jobs_created_at ON import_jobs(created_at DESC);
+
+CREATE TABLE import_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_id UUID NOT NULL REFERENCES import_jobs(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    url TEXT NOT NULL,
+    title TEXT,
+    tag TEXT,
+    notes TEXT,
+    saved_at TIMESTAMPTZ,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'imported', 'duplicate', 'invalid')),
+    entry_id UUID REFERENCES entries(id) ON DELETE SET NULL,
+    error TEXT,
+
+    UNIQUE (job_id, position)
+);
+
+CREATE INDEX idx_import_items_pending ON import_items(job_id, position) WHERE status = 'pending';
+
+-- +goose Down
+DROP TABLE import_items;
+DROP TABLE import_jobs;
@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email TEXT NOT NULL UNIQUE,
+    password_hash TEXT,
+    is_admin BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Existing entries belong to a default admin, who signs in with the API token until
+-- a password is set with `learnd users set-password`
+INSERT INTO users (id, email, is_admin) VALUES ('00000000-0000-0000-0000-000000000001', 'admin@localhost', true);
+
+ALTER TABLE entries ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+UPDATE entries SET user_id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE entries ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_entries_user_created_at ON entries(user_id, created_at DESC);
+CREATE INDEX idx_entries_user_normalized_url ON entries(user_id, normalized_url);
+
+ALTER TABLE import_jobs ADD COLUMN user_id UUID REFERENCES users(id) ON DELETE CASCADE;
+UPDATE import_jobs SET user_id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE import_jobs ALTER COLUMN user_id SET NOT NULL;
+CREATE INDEX idx_import_jobs_user_created_at ON import_jobs(user_id, created_at DESC);
+
+-- +goose Down
+ALTER TABLE import_jobs DROP COLUMN user_id;
+ALTER TABLE entries DROP COLUMN user_id;
+DROP TABLE users;
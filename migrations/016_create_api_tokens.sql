@@ -0,0 +1,19 @@
+-- +goose Up
+-- Personal access tokens for programmatic clients. Like sessions, only a SHA-256 hash
+-- of the token is stored; prefix keeps its first characters so tokens can be told apart.
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    prefix TEXT NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_tokens_user_created_at ON api_tokens(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE api_tokens;
@@ -0,0 +1,39 @@
+-- +goose Up
+-- Optional TOTP second factor. totp_last_step is the last time step a code was
+-- accepted for, so a code can't be replayed within its window. totp_required makes
+-- enrolment mandatory for the account.
+ALTER TABLE users
+    ADD COLUMN totp_secret TEXT,
+    ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN totp_required BOOLEAN NOT NULL DEFAULT false;
+
+-- Single-use recovery codes for when the authenticator is lost; only SHA-256 hashes
+-- are stored
+CREATE TABLE recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, code_hash)
+);
+
+-- Sessions that passed the password but still need a second factor. They can't
+-- authenticate requests until verified, and are deleted after too many wrong codes.
+ALTER TABLE sessions
+    ADD COLUMN pending_totp BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN totp_attempts INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE sessions
+    DROP COLUMN totp_attempts,
+    DROP COLUMN pending_totp;
+
+DROP TABLE recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN totp_required,
+    DROP COLUMN totp_last_step,
+    DROP COLUMN totp_enabled_at,
+    DROP COLUMN totp_secret;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Audit log of sign-ins, failed attempts, rejected bearer tokens and lockouts.
+-- user_id is empty when a failure didn't match an account; email keeps what was entered.
+CREATE TABLE auth_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    method TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+    ip TEXT NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_auth_events_created_at ON auth_events(created_at DESC);
+CREATE INDEX idx_auth_events_user_created_at ON auth_events(user_id, created_at DESC);
+
+-- +goose Down
+DROP TABLE auth_events;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- W3C traceparent of the request that captured an entry, so the worker's spans can
+-- link back to it. Empty when the capture wasn't traced.
+ALTER TABLE entries ADD COLUMN trace_parent TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE entries DROP COLUMN trace_parent;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Summaries put off because every provider was unavailable, so an entry that keeps
+-- failing is eventually marked failed instead of staying pending forever.
+ALTER TABLE entries ADD COLUMN summary_attempts INT NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE entries DROP COLUMN summary_attempts;
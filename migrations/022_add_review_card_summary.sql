@@ -0,0 +1,12 @@
+-- +goose Up
+-- When the entry summary a card was generated from was written, so cards are
+-- regenerated once the entry is summarized again.
+ALTER TABLE review_cards ADD COLUMN summary_generated_at TIMESTAMPTZ;
+
+UPDATE review_cards c
+SET summary_generated_at = e.summary_generated_at
+FROM entries e
+WHERE e.id = c.entry_id;
+
+-- +goose Down
+ALTER TABLE review_cards DROP COLUMN summary_generated_at;
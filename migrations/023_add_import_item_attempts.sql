@@ -0,0 +1,14 @@
+-- +goose Up
+-- Items that keep failing to import are marked failed after a few attempts so they
+-- don't hold their job open forever.
+ALTER TABLE import_items ADD COLUMN attempts INT NOT NULL DEFAULT 0;
+ALTER TABLE import_items DROP CONSTRAINT import_items_status_check;
+ALTER TABLE import_items ADD CONSTRAINT import_items_status_check
+    CHECK (status IN ('pending', 'imported', 'duplicate', 'invalid', 'failed'));
+
+-- +goose Down
+UPDATE import_items SET status = 'invalid' WHERE status = 'failed';
+ALTER TABLE import_items DROP CONSTRAINT import_items_status_check;
+ALTER TABLE import_items ADD CONSTRAINT import_items_status_check
+    CHECK (status IN ('pending', 'imported', 'duplicate', 'invalid'));
+ALTER TABLE import_items DROP COLUMN attempts;
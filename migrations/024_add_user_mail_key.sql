@@ -0,0 +1,7 @@
+-- +goose Up
+-- Emailed links are routed by a secret key in the recipient address rather than the
+-- sender, which anyone can forge. Users get a key when they open Capture Tools.
+ALTER TABLE users ADD COLUMN mail_key TEXT UNIQUE;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN mail_key;
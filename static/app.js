@@ -0,0 +1,146 @@
+// Page behaviour for learnd. It's loaded once from the page head and listens on the
+// document, so it keeps working as hx-boost swaps pages in. Boosted pages can't carry
+// inline scripts: the Content-Security-Policy only runs this origin's files and
+// scripts with the nonce of the page that was loaded, which swapped-in content never
+// gets. Only the quick-capture popup, always a full page load, has one of its own.
+(function () {
+	// Toast handling
+	document.addEventListener('showToast', function(evt) {
+		const detail = evt.detail || {};
+		const message = detail.message || 'Action completed';
+		const type = detail.type || 'success';
+		const id = detail.id || '';
+		const now = Date.now();
+		const toastKey = `${id}:${type}:${message}`;
+		const lastToast = window.__lastToast || {};
+
+		if (lastToast.key === toastKey && now - lastToast.time < 500) {
+			return;
+		}
+
+		window.__lastToast = { key: toastKey, time: now };
+
+		const toast = document.createElement('div');
+		toast.className = `toast-enter px-4 py-3 rounded-lg shadow-lg flex items-center gap-3 ${
+			type === 'error'
+				? 'bg-red-50 border border-red-200 text-red-800'
+				: 'bg-white border border-gray-200 text-gray-800'
+		}`;
+
+		// Build toast content safely using DOM APIs to prevent XSS
+		const svgNS = 'http://www.w3.org/2000/svg';
+		const icon = document.createElementNS(svgNS, 'svg');
+		icon.setAttribute('class', type === 'error' ? 'w-5 h-5 text-red-500' : 'w-5 h-5 text-green-600');
+		icon.setAttribute('fill', 'none');
+		icon.setAttribute('stroke', 'currentColor');
+		icon.setAttribute('viewBox', '0 0 24 24');
+
+		const path = document.createElementNS(svgNS, 'path');
+		path.setAttribute('stroke-linecap', 'round');
+		path.setAttribute('stroke-linejoin', 'round');
+		path.setAttribute('stroke-width', '2');
+		path.setAttribute('d', type === 'error'
+			? 'M12 8v4m0 4h.01M21 12a9 9 0 11-18 0 9 9 0 0118 0z'
+			: 'M5 13l4 4L19 7');
+		icon.appendChild(path);
+		toast.appendChild(icon);
+
+		const msgSpan = document.createElement('span');
+		msgSpan.className = 'text-sm font-medium';
+		msgSpan.textContent = message;
+		toast.appendChild(msgSpan);
+
+		const container = document.getElementById('toast-container');
+		container.appendChild(toast);
+
+		setTimeout(() => {
+			toast.classList.remove('toast-enter');
+			toast.classList.add('toast-exit');
+			setTimeout(() => toast.remove(), 300);
+		}, 3000);
+	});
+
+	// Data attributes that stand in for inline event handlers
+	document.addEventListener('click', function(evt) {
+		const select = evt.target.closest('[data-select-on-click]');
+		if (select) {
+			select.select();
+		}
+		if (evt.target.closest('[data-prevent-click]')) {
+			evt.preventDefault();
+		}
+	});
+
+	document.addEventListener('htmx:afterRequest', function(evt) {
+		const el = evt.target;
+		const successful = evt.detail.successful;
+		const created = successful && evt.detail.xhr.getResponseHeader('X-Entry-Created') === 'true';
+
+		const form = el.closest('[data-reset-on-success]');
+		if (form && successful) {
+			form.reset();
+		}
+
+		// Accepting a suggested tag saves it immediately; mirror it in the tag field
+		const suggested = el.closest('[data-suggested-tag]');
+		if (suggested) {
+			if (successful) {
+				document.getElementById('tag').value = suggested.dataset.suggestedTag;
+				document.querySelectorAll('[data-suggested-tag]').forEach(function(other) {
+					other.disabled = other === suggested;
+				});
+			}
+			return;
+		}
+
+		// Only redirect after the edit form is successfully submitted
+		if (el.matches('#edit-form') && successful) {
+			window.location.href = '/';
+		}
+
+		if (el.matches('#capture-form') && created) {
+			el.reset();
+			document.getElementById('url').focus();
+		}
+
+		if (el.matches('#quick-capture') && created) {
+			document.getElementById('quick-capture').classList.add('hidden');
+			document.getElementById('quick-capture-done').classList.remove('hidden');
+			// Popups opened by the bookmarklet can close themselves; an installed app
+			// opened from the share sheet goes back to the capture page instead
+			setTimeout(function () {
+				window.close();
+				setTimeout(function () { window.location.href = '/'; }, 300);
+			}, 800);
+		}
+	});
+
+	// Shift+Enter submits the capture form
+	document.addEventListener('keydown', function(evt) {
+		const form = document.getElementById('capture-form');
+		if (form && evt.shiftKey && evt.key === 'Enter') {
+			evt.preventDefault();
+			htmx.trigger(form, 'submit');
+		}
+	});
+
+	// Update the report's export links when its form changes
+	document.addEventListener('change', function(evt) {
+		const form = evt.target.closest('#report-form');
+		if (!form) {
+			return;
+		}
+		const params = new URLSearchParams(new FormData(form));
+		document.querySelectorAll('[data-export-base]').forEach(function(link) {
+			link.href = link.dataset.exportBase + '?' + params.toString();
+		});
+	});
+
+	// Count review streaks in the browser's time zone
+	document.addEventListener('htmx:load', function() {
+		const tz = document.getElementById('report-tz');
+		if (tz) {
+			tz.value = Intl.DateTimeFormat().resolvedOptions().timeZone || '';
+		}
+	});
+})();